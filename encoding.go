@@ -0,0 +1,812 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+var (
+	// `ErrNilMap` is returned when trying to encode or decode
+	// a `nil` partitioned map.
+	ErrNilMap = errors.New("partitionmap: nil map")
+)
+
+type (
+	// `tCountingReader` wraps an `io.Reader` counting the bytes read
+	// and providing the `io.ByteReader` interface without reading
+	// ahead of the data actually consumed.
+	tCountingReader struct {
+		io.Reader       // the reader to read from
+		count     int64 // the number of bytes read so far
+	}
+)
+
+// `Read()` implements the `io.Reader` interface.
+func (cr *tCountingReader) Read(aBuffer []byte) (int, error) {
+	n, err := cr.Reader.Read(aBuffer)
+	cr.count += int64(n)
+
+	return n, err
+} // Read()
+
+// `ReadByte()` implements the `io.ByteReader` interface.
+func (cr *tCountingReader) ReadByte() (byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(cr, b[:]); nil != err {
+		return 0, err
+	}
+
+	return b[0], nil
+} // ReadByte()
+
+// ---------------------------------------------------------------------------
+// Text conversion helpers:
+
+// `formatKind()` returns the textual representation of the given
+// value if its kind is a boolean, string, integer, or floating point
+// kind.
+//
+// The representation is the one `strconv` would produce for the
+// underlying kind of the value's type (which allows for named types
+// like `type tName string` as well).
+//
+// Parameters:
+//   - `aValue`: The value to convert.
+//
+// Returns:
+//   - `string`: The textual representation of the value.
+//   - `bool`: Whether the value's kind is supported.
+func formatKind(aValue reflect.Value) (string, bool) {
+	switch aValue.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(aValue.Bool()), true
+	case reflect.String:
+		return aValue.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(aValue.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(aValue.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(aValue.Float(), 'g', -1, aValue.Type().Bits()), true
+	default:
+		return "", false
+	} // switch
+} // formatKind()
+
+// `parseKind()` parses the given text into the given (settable)
+// value if its kind is a boolean, string, integer, or floating point
+// kind.
+//
+// This is the counterpart of `formatKind()`.
+//
+// Parameters:
+//   - `aValue`: The value to set.
+//   - `aText`: The text to parse.
+//
+// Returns:
+//   - `bool`: Whether the value's kind is supported.
+//   - `error`: A possible parsing error.
+func parseKind(aValue reflect.Value, aText string) (rOk bool, rErr error) {
+	rOk = true
+
+	switch aValue.Kind() {
+	case reflect.Bool:
+		var b bool
+		if b, rErr = strconv.ParseBool(aText); nil == rErr {
+			aValue.SetBool(b)
+		}
+	case reflect.String:
+		aValue.SetString(aText)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, rErr = strconv.ParseInt(aText, 10, aValue.Type().Bits()); nil == rErr {
+			aValue.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		if u, rErr = strconv.ParseUint(aText, 10, aValue.Type().Bits()); nil == rErr {
+			aValue.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, rErr = strconv.ParseFloat(aText, aValue.Type().Bits()); nil == rErr {
+			aValue.SetFloat(f)
+		}
+	default:
+		rOk = false
+	} // switch
+
+	return
+} // parseKind()
+
+// `keyToText()` returns the textual representation of the given key.
+//
+// The representation is the one `strconv` would produce for the
+// underlying kind of the key's type (see `formatKind()`).
+//
+// Parameters:
+//   - `aKey`: The key to convert.
+//
+// Returns:
+//   - `string`: The textual representation of the key.
+func keyToText[K cmp.Ordered](aKey K) string {
+	if text, ok := formatKind(reflect.ValueOf(aKey)); ok {
+		return text
+	}
+
+	return fmt.Sprintf("%v", aKey)
+} // keyToText()
+
+// `textToKey()` parses the given text into a key of type `K`.
+//
+// This is the counterpart of `keyToText()`.
+//
+// Parameters:
+//   - `aText`: The text to parse.
+//
+// Returns:
+//   - `K`: The parsed key.
+//   - `error`: A possible parsing error.
+func textToKey[K cmp.Ordered](aText string) (rKey K, rErr error) {
+	var ok bool
+	if ok, rErr = parseKind(reflect.ValueOf(&rKey).Elem(), aText); !ok {
+		rErr = fmt.Errorf("partitionmap: unsupported key type %T", rKey)
+	}
+
+	return
+} // textToKey()
+
+// `textToValue()` parses the given text into a value of type `V`.
+//
+// This is the counterpart of `valueToText()`.
+//
+// Parameters:
+//   - `aText`: The text to parse.
+//
+// Returns:
+//   - `V`: The parsed value.
+//   - `error`: A possible parsing error.
+func textToValue[V any](aText string) (rVal V, rErr error) {
+	if tu, ok := any(&rVal).(encoding.TextUnmarshaler); ok {
+		rErr = tu.UnmarshalText([]byte(aText))
+		return
+	}
+
+	var ok bool
+	if ok, rErr = parseKind(reflect.ValueOf(&rVal).Elem(), aText); !ok {
+		rErr = fmt.Errorf("partitionmap: unsupported value type %T", rVal)
+	}
+
+	return
+} // textToValue()
+
+// `valueToText()` returns the textual representation of the given
+// value.
+//
+// Values implementing `encoding.TextMarshaler` are converted by that
+// interface, values of boolean, string, integer, and floating point
+// kinds by `formatKind()`; all other values are unsupported.
+//
+// Parameters:
+//   - `aValue`: The value to convert.
+//
+// Returns:
+//   - `string`: The textual representation of the value.
+//   - `error`: A possible conversion error.
+func valueToText[V any](aValue V) (string, error) {
+	if tm, ok := any(aValue).(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	if tm, ok := any(&aValue).(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+
+	if text, ok := formatKind(reflect.ValueOf(&aValue).Elem()); ok {
+		return text, nil
+	}
+
+	return "", fmt.Errorf("partitionmap: unsupported value type %T", aValue)
+} // valueToText()
+
+// ---------------------------------------------------------------------------
+// CSV encoding:
+
+// `ReadCSV()` reads two-column `key,value` records as written by
+// `WriteCSV()` from the given reader.
+//
+// If all records are decoded successfully, the current contents of
+// the map are atomically replaced by the decoded key/value pairs
+// (see `SetAll()`). In case of an error the map remains unchanged.
+//
+// The supported key and value types are the same as those of
+// `UnmarshalText()`.
+//
+// Parameters:
+//   - `aReader`: The reader to read the CSV data from.
+//
+// Returns:
+//   - `error`: A possible read or decoding error.
+func (pm *TPartitionMap[K, V]) ReadCSV(aReader io.Reader) error {
+	if nil == pm {
+		return ErrNilMap
+	}
+
+	reader := csv.NewReader(aReader)
+	reader.FieldsPerRecord = 2
+	reader.ReuseRecord = true
+
+	decoded := make(map[K]V)
+	for {
+		record, err := reader.Read()
+		if io.EOF == err {
+			break
+		}
+		if nil != err {
+			return err
+		}
+
+		key, err := textToKey[K](record[0])
+		if nil != err {
+			return err
+		}
+		val, err := textToValue[V](record[1])
+		if nil != err {
+			return err
+		}
+		decoded[key] = val
+	}
+
+	pm.SetAll(decoded)
+
+	return nil
+} // ReadCSV()
+
+// `WriteCSV()` writes all key/value pairs as two-column `key,value`
+// records to the given writer.
+//
+// The records are sorted by their keys in ascending order and quoted
+// as required by RFC 4180 (see `encoding/csv`); no header record is
+// written.
+//
+// The supported key and value types are the same as those of
+// `MarshalText()`.
+//
+// Parameters:
+//   - `aWriter`: The writer to write the CSV data to.
+//
+// Returns:
+//   - `error`: A possible encoding or write error.
+func (pm *TPartitionMap[K, V]) WriteCSV(aWriter io.Writer) error {
+	if nil == pm {
+		return ErrNilMap
+	}
+
+	writer := csv.NewWriter(aWriter)
+	for _, e := range pm.Entries() {
+		val, err := valueToText(e.Value)
+		if nil != err {
+			return err
+		}
+		if err = writer.Write([]string{keyToText(e.Key), val}); nil != err {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+} // WriteCSV()
+
+// ---------------------------------------------------------------------------
+// Gob encoding:
+
+// `GobEncode()` implements the `gob.GobEncoder` interface.
+//
+// All key/value pairs are copied into a plain `map[K]V` which is then
+// gob encoded. The partitions are read-locked only while copying
+// their contents, so it's safe to use concurrently with other
+// goroutines accessing the map.
+//
+// NOTE: Both, the key type `K` and the value type `V` must themselves
+// be encodable by the `encoding/gob` package.
+//
+// Returns:
+//   - `[]byte`: The gob encoded partitioned map.
+//   - `error`: A possible encoding error.
+func (pm *TPartitionMap[K, V]) GobEncode() ([]byte, error) {
+	if nil == pm {
+		return nil, ErrNilMap
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(pm.ToMap()); nil != err {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+} // GobEncode()
+
+// `GobDecode()` implements the `gob.GobDecoder` interface.
+//
+// If the given data is decoded successfully, the current contents of
+// the map are atomically replaced by the decoded key/value pairs
+// (see `SetAll()`). In case of an error the map remains unchanged.
+//
+// Parameters:
+//   - `aData`: The gob encoded data as produced by `GobEncode()`.
+//
+// Returns:
+//   - `error`: A possible decoding error.
+func (pm *TPartitionMap[K, V]) GobDecode(aData []byte) error {
+	if nil == pm {
+		return ErrNilMap
+	}
+
+	var data map[K]V
+	if err := gob.NewDecoder(bytes.NewReader(aData)).Decode(&data); nil != err {
+		return err
+	}
+
+	pm.SetAll(data)
+
+	return nil
+} // GobDecode()
+
+// ---------------------------------------------------------------------------
+// JSON encoding:
+
+// `EncodeJSON()` writes the partitioned map as a JSON object to the
+// given writer.
+//
+// Other than `MarshalJSON()`, which builds the whole document in
+// memory, this method streams the object's members partition by
+// partition: each partition is snapshotted under its read lock and
+// written before the next one is copied, so no more than a single
+// partition's data is held in memory at once. This allows for
+// encoding maps too large to be marshalled in one piece.
+//
+// The members are sorted by their keys within each partition, but
+// not across partitions; hence the output is deterministic for a
+// given map but (other than that of `MarshalJSON()`) not globally
+// sorted. Since the partitions are copied one after the other, the
+// output isn't a consistent snapshot of the whole map either; use
+// `Snapshot()` first if that's required.
+// The result can be decoded by `UnmarshalJSON()`.
+//
+// Example usage:
+//
+//	if err := pm.EncodeJSON(file); nil != err {
+//		log.Fatal(err)
+//	}
+//
+// Parameters:
+//   - `aWriter`: The writer to write the JSON data to.
+//
+// Returns:
+//   - `error`: A possible encoding or write error.
+func (pm *TPartitionMap[K, V]) EncodeJSON(aWriter io.Writer) error {
+	writer := bufio.NewWriter(aWriter)
+	if nil == pm {
+		writer.WriteString("null")
+		return writer.Flush()
+	}
+
+	writer.WriteByte('{')
+	first := true
+	for _, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		kv := p.clone()
+		for _, k := range slices.Sorted(maps.Keys(kv)) {
+			key, err := json.Marshal(keyToText(k))
+			if nil != err {
+				return err
+			}
+			val, err := json.Marshal(kv[k])
+			if nil != err {
+				return err
+			}
+			if !first {
+				writer.WriteByte(',')
+			}
+			first = false
+			writer.Write(key)
+			writer.WriteByte(':')
+			if _, err = writer.Write(val); nil != err {
+				return err
+			}
+		}
+	}
+	writer.WriteByte('}')
+
+	return writer.Flush()
+} // EncodeJSON()
+
+// `MarshalJSON()` implements the `json.Marshaler` interface.
+//
+// The partitioned map is encoded as a standard JSON object whose
+// members are emitted in ascending order of their keys, so the
+// output is deterministic. Non-string keys are encoded by their
+// textual representation (e.g. `42` becomes `"42"`).
+//
+// The method works on a consistent snapshot of each partition (see
+// `Entries()`), so it's safe to use concurrently with other goroutines
+// modifying the map.
+//
+// Returns:
+//   - `[]byte`: The JSON encoded partitioned map.
+//   - `error`: A possible encoding error of one of the values.
+func (pm *TPartitionMap[K, V]) MarshalJSON() ([]byte, error) {
+	if nil == pm {
+		return []byte("null"), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for idx, e := range pm.Entries() {
+		if 0 < idx {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(keyToText(e.Key))
+		if nil != err {
+			return nil, err
+		}
+		val, err := json.Marshal(e.Value)
+		if nil != err {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+} // MarshalJSON()
+
+// `UnmarshalJSON()` implements the `json.Unmarshaler` interface.
+//
+// The given data must be a JSON object as produced by `MarshalJSON()`.
+// If it's decoded successfully, the current contents of the map are
+// atomically replaced by the decoded key/value pairs (see `SetAll()`).
+// In case of an error the map remains unchanged.
+//
+// Supported key types are all types whose underlying type is a string,
+// integer, or floating point type.
+//
+// Parameters:
+//   - `aData`: The JSON data to decode.
+//
+// Returns:
+//   - `error`: A possible decoding error.
+func (pm *TPartitionMap[K, V]) UnmarshalJSON(aData []byte) error {
+	if nil == pm {
+		return ErrNilMap
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(aData, &raw); nil != err {
+		return err
+	}
+
+	decoded := make(map[K]V, len(raw))
+	for text, data := range raw {
+		key, err := textToKey[K](text)
+		if nil != err {
+			return err
+		}
+		var val V
+		if err = json.Unmarshal(data, &val); nil != err {
+			return err
+		}
+		decoded[key] = val
+	}
+
+	pm.SetAll(decoded)
+
+	return nil
+} // UnmarshalJSON()
+
+// ---------------------------------------------------------------------------
+// Text encoding:
+
+var (
+	// `gTextEscaper` escapes the characters with a special meaning
+	// in the line-oriented text format.
+	gTextEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		"\n", `\n`,
+		"\r", `\r`,
+		`=`, `\=`,
+	)
+)
+
+// `splitTextLine()` splits the given line of the text format into
+// its (unescaped) key and value.
+//
+// The key and value are separated by the first unescaped `=`
+// character. Within both, the escape sequences `\\`, `\n`, `\r`,
+// and `\=` are recognised.
+//
+// Parameters:
+//   - `aLine`: The line to split.
+//
+// Returns:
+//   - `string`: The unescaped key.
+//   - `string`: The unescaped value.
+//   - `error`: A possible syntax error.
+func splitTextLine(aLine string) (rKey, rValue string, rErr error) {
+	var (
+		builder strings.Builder
+		isKey   = true
+	)
+	for idx := 0; idx < len(aLine); idx++ {
+		switch char := aLine[idx]; char {
+		case '\\':
+			if idx++; idx == len(aLine) {
+				return "", "", errors.New("incomplete escape sequence")
+			}
+			switch aLine[idx] {
+			case '\\', '=':
+				builder.WriteByte(aLine[idx])
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			default:
+				return "", "", fmt.Errorf("invalid escape sequence '\\%c'", aLine[idx])
+			}
+		case '=':
+			if isKey {
+				rKey, isKey = builder.String(), false
+				builder.Reset()
+				continue
+			}
+			builder.WriteByte(char)
+		default:
+			builder.WriteByte(char)
+		}
+	}
+	if isKey {
+		return "", "", errors.New("missing '=' separator")
+	}
+
+	return rKey, builder.String(), nil
+} // splitTextLine()
+
+// `MarshalText()` implements the `encoding.TextMarshaler` interface.
+//
+// The partitioned map is encoded as human-readable `key=value` lines
+// which are sorted by their keys in ascending order, so the output
+// is deterministic. Within keys and values the characters `\`, `=`,
+// newline and carriage return are escaped as `\\`, `\=`, `\n`,
+// and `\r` respectively.
+//
+// Supported key types are all types whose underlying type is a string,
+// integer, or floating point type. Supported value types are those
+// implementing `encoding.TextMarshaler` and all types whose underlying
+// type is a boolean, string, integer, or floating point type.
+//
+// Returns:
+//   - `[]byte`: The text encoded partitioned map.
+//   - `error`: A possible encoding error of one of the values.
+func (pm *TPartitionMap[K, V]) MarshalText() ([]byte, error) {
+	if nil == pm {
+		return nil, ErrNilMap
+	}
+
+	var buf bytes.Buffer
+	for _, e := range pm.Entries() {
+		val, err := valueToText(e.Value)
+		if nil != err {
+			return nil, err
+		}
+		_, _ = gTextEscaper.WriteString(&buf, keyToText(e.Key))
+		buf.WriteByte('=')
+		_, _ = gTextEscaper.WriteString(&buf, val)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+} // MarshalText()
+
+// `UnmarshalText()` implements the `encoding.TextUnmarshaler` interface.
+//
+// The given text must consist of `key=value` lines as produced by
+// `MarshalText()`; empty lines are ignored. If it's decoded
+// successfully, the current contents of the map are atomically
+// replaced by the decoded key/value pairs (see `SetAll()`). In case
+// of an error the map remains unchanged.
+//
+// Parameters:
+//   - `aText`: The text to decode.
+//
+// Returns:
+//   - `error`: A possible decoding error.
+func (pm *TPartitionMap[K, V]) UnmarshalText(aText []byte) error {
+	if nil == pm {
+		return ErrNilMap
+	}
+
+	decoded := make(map[K]V)
+	for idx, line := range strings.Split(string(aText), "\n") {
+		// Tolerate line endings changed by an editor.
+		if line = strings.TrimSuffix(line, "\r"); 0 == len(line) {
+			continue
+		}
+
+		keyText, valText, err := splitTextLine(line)
+		if nil != err {
+			return fmt.Errorf("partitionmap: line %d: %w", idx+1, err)
+		}
+		key, err := textToKey[K](keyText)
+		if nil != err {
+			return fmt.Errorf("partitionmap: line %d: %w", idx+1, err)
+		}
+		val, err := textToValue[V](valText)
+		if nil != err {
+			return fmt.Errorf("partitionmap: line %d: %w", idx+1, err)
+		}
+		decoded[key] = val
+	}
+
+	pm.SetAll(decoded)
+
+	return nil
+} // UnmarshalText()
+
+// ---------------------------------------------------------------------------
+// Binary stream encoding:
+
+// `writeRecord()` writes the given data prefixed by its length
+// (as an unsigned varint) to the given writer.
+//
+// Parameters:
+//   - `aWriter`: The writer to write to.
+//   - `aData`: The record's data.
+//
+// Returns:
+//   - `int64`: The number of bytes written.
+//   - `error`: A possible write error.
+func writeRecord(aWriter io.Writer, aData []byte) (int64, error) {
+	var prefix [binary.MaxVarintLen64]byte
+	n, err := aWriter.Write(prefix[:binary.PutUvarint(prefix[:], uint64(len(aData)))])
+	if (nil != err) || (0 == len(aData)) {
+		return int64(n), err
+	}
+	m, err := aWriter.Write(aData)
+
+	return int64(n + m), err
+} // writeRecord()
+
+// `ReadFrom()` implements the `io.ReaderFrom` interface.
+//
+// It reads a stream as written by `WriteTo()` up to (and including)
+// its end marker; any data following that marker is left unread.
+// If the stream is decoded successfully, the current contents of the
+// map are atomically replaced by the decoded key/value pairs (see
+// `SetAll()`). In case of an error the map remains unchanged.
+//
+// Parameters:
+//   - `aReader`: The reader to read the stream from.
+//
+// Returns:
+//   - `int64`: The number of bytes read.
+//   - `error`: A possible read or decoding error.
+func (pm *TPartitionMap[K, V]) ReadFrom(aReader io.Reader) (int64, error) {
+	if nil == pm {
+		return 0, ErrNilMap
+	}
+
+	var buf bytes.Buffer
+	cr := &tCountingReader{Reader: aReader}
+	decoded := make(map[K]V)
+	for {
+		size, err := binary.ReadUvarint(cr)
+		if nil != err {
+			if io.EOF == err {
+				err = io.ErrUnexpectedEOF
+			}
+			return cr.count, err
+		}
+		if 0 == size {
+			break // end of stream
+		}
+
+		// Using a buffer (instead of a slice allocated with the given
+		// size) protects against bogus sizes in corrupted data.
+		buf.Reset()
+		if _, err = io.CopyN(&buf, cr, int64(size)); nil != err {
+			if io.EOF == err {
+				err = io.ErrUnexpectedEOF
+			}
+			return cr.count, err
+		}
+
+		var pair TPair[K, V]
+		if err = gob.NewDecoder(&buf).Decode(&pair); nil != err {
+			return cr.count, err
+		}
+		decoded[pair.Key] = pair.Value
+	}
+
+	pm.SetAll(decoded)
+
+	return cr.count, nil
+} // ReadFrom()
+
+// `WriteTo()` implements the `io.WriterTo` interface.
+//
+// The map is written as a stream of records, each consisting of its
+// length (as an unsigned varint) followed by a single gob encoded
+// key/value pair; an empty record marks the end of the stream.
+// The partitions are processed one after the other, each on a
+// snapshot taken under its read lock, and the records are written
+// as they are encoded, so no buffer holding the whole map is needed.
+//
+// NOTE: Both, the key type `K` and the value type `V` must themselves
+// be encodable by the `encoding/gob` package.
+//
+// Parameters:
+//   - `aWriter`: The writer to write the stream to.
+//
+// Returns:
+//   - `int64`: The number of bytes written.
+//   - `error`: A possible encoding or write error.
+func (pm *TPartitionMap[K, V]) WriteTo(aWriter io.Writer) (rCount int64, rErr error) {
+	if nil == pm {
+		return 0, ErrNilMap
+	}
+
+	var (
+		buf bytes.Buffer
+		n   int64
+	)
+	for _, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		for k, v := range p.clone() {
+			buf.Reset()
+			if rErr = gob.NewEncoder(&buf).Encode(TPair[K, V]{Key: k, Value: v}); nil != rErr {
+				return
+			}
+			n, rErr = writeRecord(aWriter, buf.Bytes())
+			if rCount += n; nil != rErr {
+				return
+			}
+		}
+	}
+
+	// Terminate the stream with an empty record:
+	n, rErr = writeRecord(aWriter, nil)
+	rCount += n
+
+	return
+} // WriteTo()
+
+/* _EoF_ */
@@ -0,0 +1,760 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_keyToText_textToKey(t *testing.T) {
+	type tName string
+
+	checkRoundTrip(t, "string", "some key")
+	checkRoundTrip(t, "named string", tName("named key"))
+	checkRoundTrip(t, "int", -42)
+	checkRoundTrip(t, "int8", int8(-128))
+	checkRoundTrip(t, "uint64", uint64(18446744073709551615))
+	checkRoundTrip(t, "float32", float32(3.14))
+	checkRoundTrip(t, "float64", 2.718281828459045)
+
+	if _, err := textToKey[int]("no number"); nil == err {
+		t.Errorf("textToKey[int]() expected an error")
+	}
+	if _, err := textToKey[int8]("300"); nil == err {
+		t.Errorf("textToKey[int8]() expected an overflow error")
+	}
+} // Test_keyToText_textToKey()
+
+func checkRoundTrip[K cmp.Ordered](t *testing.T, aName string, aKey K) {
+	t.Helper()
+
+	got, err := textToKey[K](keyToText(aKey))
+	if nil != err {
+		t.Errorf("%s: textToKey() error = %v", aName, err)
+	}
+	if got != aKey {
+		t.Errorf("%s: round trip = %v, want %v", aName, got, aKey)
+	}
+} // checkRoundTrip()
+
+func Test_TPartitionMap_CSV_RoundTrip(t *testing.T) {
+	src := New[string, string]().
+		Put("plain", "value").
+		Put("comma", "a,b,c").
+		Put("quote", `say "hello"`).
+		Put("multi,line", "first\nsecond").
+		Put("", "empty key")
+
+	var buf bytes.Buffer
+	if err := src.WriteCSV(&buf); nil != err {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := `,empty key
+comma,"a,b,c"
+"multi,line","first
+second"
+plain,value
+quote,"say ""hello"""
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV() = %q, want %q", got, want)
+	}
+
+	dst := New[string, string]().Put("stale", "entry")
+	if err := dst.ReadCSV(&buf); nil != err {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(dst.Entries(), src.Entries()) {
+		t.Errorf("CSV round trip: got %v, want %v", dst.Entries(), src.Entries())
+	}
+
+	// Non-string types:
+	isrc := New[int, float64]().Put(-1, 0.5).Put(2, 1e21)
+	buf.Reset()
+	if err := isrc.WriteCSV(&buf); nil != err {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	idst := New[int, float64]()
+	if err := idst.ReadCSV(&buf); nil != err {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(idst.Entries(), isrc.Entries()) {
+		t.Errorf("CSV round trip: got %v, want %v", idst.Entries(), isrc.Entries())
+	}
+
+	var nilPM *TPartitionMap[int, float64]
+	if err := nilPM.WriteCSV(&buf); !errors.Is(err, ErrNilMap) {
+		t.Errorf("WriteCSV() error = %v, want %v", err, ErrNilMap)
+	}
+} // Test_TPartitionMap_CSV_RoundTrip()
+
+func Test_TPartitionMap_ReadCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		pm       *TPartitionMap[int, int]
+		data     string
+		wantKeys []int
+		wantErr  bool
+	}{
+		{
+			name:     "Valid records",
+			pm:       New[int, int]().Put(99, 99),
+			data:     "1,10\n2,20\n",
+			wantKeys: []int{1, 2},
+		},
+		{
+			name:     "Wrong number of fields",
+			pm:       New[int, int]().Put(99, 99),
+			data:     "1,10\n2,20,30\n",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid key",
+			pm:       New[int, int]().Put(99, 99),
+			data:     "one,10\n",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid value",
+			pm:       New[int, int]().Put(99, 99),
+			data:     "1,ten\n",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid quoting",
+			pm:       New[int, int]().Put(99, 99),
+			data:     "1,\"10\n",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:    "Nil partition map",
+			pm:      nil,
+			data:    "1,10\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.pm.ReadCSV(strings.NewReader(tc.data))
+			if (nil != err) != tc.wantErr {
+				t.Errorf("ReadCSV() error = %v, wantErr %v",
+					err, tc.wantErr)
+			}
+			if nil == tc.pm {
+				if !errors.Is(err, ErrNilMap) {
+					t.Errorf("ReadCSV() error = %v, want %v",
+						err, ErrNilMap)
+				}
+				return
+			}
+			if got := tc.pm.Keys(); !reflect.DeepEqual(got, tc.wantKeys) {
+				t.Errorf("After ReadCSV(), Keys() = %v, want %v",
+					got, tc.wantKeys)
+			}
+		})
+	}
+} // Test_TPartitionMap_ReadCSV()
+
+func Test_TPartitionMap_Gob_RoundTrip(t *testing.T) {
+	type tValue struct {
+		Name  string
+		Tags  []string
+		Count int
+	}
+
+	src := New[int64, tValue]()
+	for i := range int64(1000) {
+		src.Put(i-500, tValue{
+			Name:  fmt.Sprintf("value-%d", i),
+			Tags:  []string{"tag", fmt.Sprint(i)},
+			Count: int(i),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(src); nil != err {
+		t.Fatalf("gob.Encode() error = %v", err)
+	}
+
+	dst := New[int64, tValue]().Put(99999, tValue{Name: "stale"})
+	if err := gob.NewDecoder(&buf).Decode(dst); nil != err {
+		t.Fatalf("gob.Decode() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(dst.Entries(), src.Entries()) {
+		t.Errorf("Gob round trip: Entries() differ")
+	}
+} // Test_TPartitionMap_Gob_RoundTrip()
+
+func Test_TPartitionMap_GobDecode(t *testing.T) {
+	pm := New[int64, string]().Put(1, "one")
+
+	if err := pm.GobDecode([]byte("invalid data")); nil == err {
+		t.Errorf("GobDecode() expected an error")
+	}
+	if v, ok := pm.Get(1); !ok || ("one" != v) {
+		t.Errorf("GobDecode() modified map on error")
+	}
+
+	var nilPM *TPartitionMap[int64, string]
+	if err := nilPM.GobDecode(nil); !errors.Is(err, ErrNilMap) {
+		t.Errorf("GobDecode() error = %v, want %v", err, ErrNilMap)
+	}
+	if _, err := nilPM.GobEncode(); !errors.Is(err, ErrNilMap) {
+		t.Errorf("GobEncode() error = %v, want %v", err, ErrNilMap)
+	}
+} // Test_TPartitionMap_GobDecode()
+
+func Test_TPartitionMap_EncodeJSON(t *testing.T) {
+	src := New[string, int]()
+	want := make(map[string]int, 1000)
+	for i := range 1000 {
+		key := fmt.Sprintf("key \"%d\"", i)
+		src.Put(key, i)
+		want[key] = i
+	}
+
+	var buf bytes.Buffer
+	if err := src.EncodeJSON(&buf); nil != err {
+		t.Fatalf("EncodeJSON() error = %v", err)
+	}
+	var got map[string]int
+	if err := json.Unmarshal(buf.Bytes(), &got); nil != err {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EncodeJSON() decoded %d pairs, want %d", len(got), len(want))
+	}
+
+	// The output can be decoded by `UnmarshalJSON()` as well.
+	dst := New[string, int]()
+	if err := json.Unmarshal(buf.Bytes(), dst); nil != err {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	if !Equal(dst, src) {
+		t.Error("UnmarshalJSON() of EncodeJSON() output differs from source")
+	}
+
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[int, string]
+		want string
+	}{
+		{"Empty partition map", New[int, string](), `{}`},
+		{"Single pair", New[int, string]().Put(-1, "minus one"), `{"-1":"minus one"}`},
+		{"Nil partition map", nil, `null`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf strings.Builder
+			if err := tc.pm.EncodeJSON(&buf); nil != err {
+				t.Fatalf("EncodeJSON() error = %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("EncodeJSON() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	// Encoding and write errors are reported.
+	bad := New[string, func()]().Put("f", func() {})
+	if err := bad.EncodeJSON(io.Discard); nil == err {
+		t.Error("EncodeJSON() of unsupported value should fail")
+	}
+	if err := src.EncodeJSON(tFailingWriter{}); nil == err {
+		t.Error("EncodeJSON() to failing writer should fail")
+	}
+} // Test_TPartitionMap_EncodeJSON()
+
+// `tFailingWriter` is a writer rejecting all data.
+type tFailingWriter struct{}
+
+func (tFailingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+} // Write()
+
+func Test_TPartitionMap_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		pm      *TPartitionMap[int, string]
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[int, string](),
+			want: `{}`,
+		},
+		{
+			name: "Partition map with values",
+			pm: New[int, string]().
+				Put(10, "ten").
+				Put(2, "two").
+				Put(-1, "minus \"one\""),
+			want: `{"-1":"minus \"one\"","2":"two","10":"ten"}`,
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: `null`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.pm.MarshalJSON()
+			if (nil != err) != tc.wantErr {
+				t.Errorf("MarshalJSON() error = %v, wantErr %v",
+					err, tc.wantErr)
+				return
+			}
+			if string(got) != tc.want {
+				t.Errorf("MarshalJSON() = %s, want %s",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_MarshalJSON()
+
+func Test_TPartitionMap_JSON_RoundTrip(t *testing.T) {
+	type tValue struct {
+		Name  string
+		Count int
+	}
+
+	src := New[string, tValue]()
+	for i, name := range []string{"alpha", "beta", "gamma", "delta"} {
+		src.Put(name, tValue{Name: name, Count: i})
+	}
+
+	data, err := json.Marshal(src)
+	if nil != err {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	dst := New[string, tValue]().Put("stale", tValue{})
+	if err = json.Unmarshal(data, dst); nil != err {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(dst.Keys(), src.Keys()) {
+		t.Errorf("Keys() = %v, want %v", dst.Keys(), src.Keys())
+	}
+	if !reflect.DeepEqual(dst.Values(), src.Values()) {
+		t.Errorf("Values() = %v, want %v", dst.Values(), src.Values())
+	}
+
+	// Numeric keys:
+	fsrc := New[float64, int]().Put(3.14, 1).Put(-2.5, 2).Put(1e21, 3)
+	if data, err = json.Marshal(fsrc); nil != err {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	fdst := New[float64, int]()
+	if err = json.Unmarshal(data, fdst); nil != err {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(fdst.Entries(), fsrc.Entries()) {
+		t.Errorf("Entries() = %v, want %v", fdst.Entries(), fsrc.Entries())
+	}
+} // Test_TPartitionMap_JSON_RoundTrip()
+
+func Test_TPartitionMap_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		pm       *TPartitionMap[int, int]
+		data     string
+		wantKeys []int
+		wantErr  bool
+	}{
+		{
+			name:     "Valid object",
+			pm:       New[int, int]().Put(99, 99),
+			data:     `{"1":10,"2":20}`,
+			wantKeys: []int{1, 2},
+		},
+		{
+			name:     "Invalid key",
+			pm:       New[int, int]().Put(99, 99),
+			data:     `{"one":10}`,
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid value",
+			pm:       New[int, int]().Put(99, 99),
+			data:     `{"1":"ten"}`,
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid JSON",
+			pm:       New[int, int]().Put(99, 99),
+			data:     `[1, 2]`,
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:    "Nil partition map",
+			pm:      nil,
+			data:    `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.pm.UnmarshalJSON([]byte(tc.data))
+			if (nil != err) != tc.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v",
+					err, tc.wantErr)
+			}
+			if nil == tc.pm {
+				if !errors.Is(err, ErrNilMap) {
+					t.Errorf("UnmarshalJSON() error = %v, want %v",
+						err, ErrNilMap)
+				}
+				return
+			}
+			if got := tc.pm.Keys(); !reflect.DeepEqual(got, tc.wantKeys) {
+				t.Errorf("After UnmarshalJSON(), Keys() = %v, want %v",
+					got, tc.wantKeys)
+			}
+		})
+	}
+} // Test_TPartitionMap_UnmarshalJSON()
+
+func Test_TPartitionMap_MarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		pm      *TPartitionMap[string, string]
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, string](),
+			want: "",
+		},
+		{
+			name: "Sorted lines",
+			pm:   New[string, string]().Put("b", "2").Put("a", "1"),
+			want: "a=1\nb=2\n",
+		},
+		{
+			name: "Escaped characters",
+			pm: New[string, string]().
+				Put("x=y", "a\nb").
+				Put(`back\slash`, "c\r=d"),
+			want: "back\\\\slash=c\\r\\=d\nx\\=y=a\\nb\n",
+		},
+		{
+			name:    "Nil partition map",
+			pm:      nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.pm.MarshalText()
+			if (nil != err) != tc.wantErr {
+				t.Errorf("MarshalText() error = %v, wantErr %v",
+					err, tc.wantErr)
+				return
+			}
+			if string(got) != tc.want {
+				t.Errorf("MarshalText() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	// Values of unsupported types:
+	if _, err := New[int, []int]().Put(1, []int{1}).MarshalText(); nil == err {
+		t.Errorf("MarshalText() expected an error for slice values")
+	}
+} // Test_TPartitionMap_MarshalText()
+
+func Test_TPartitionMap_Text_RoundTrip(t *testing.T) {
+	type tName string
+
+	src := New[string, tName]().
+		Put("plain", "value").
+		Put("", "empty key").
+		Put("empty value", "").
+		Put("a=b", "c=d=e").
+		Put("multi\nline", "first\nsecond\r\n").
+		Put(`C:\path\`, `\n is not a newline`)
+
+	data, err := src.MarshalText()
+	if nil != err {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+	if n := bytes.Count(data, []byte("\n")); src.Len() != n {
+		t.Errorf("MarshalText() produced %d lines, want %d", n, src.Len())
+	}
+
+	dst := New[string, tName]().Put("stale", "entry")
+	if err = dst.UnmarshalText(data); nil != err {
+		t.Fatalf("UnmarshalText() error = %v", err)
+	}
+	if !reflect.DeepEqual(dst.Entries(), src.Entries()) {
+		t.Errorf("Text round trip: got %v, want %v", dst.Entries(), src.Entries())
+	}
+
+	// Numeric keys, boolean values:
+	bsrc := New[float64, bool]().Put(-2.5, true).Put(1e21, false)
+	if data, err = bsrc.MarshalText(); nil != err {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+	bdst := New[float64, bool]()
+	if err = bdst.UnmarshalText(data); nil != err {
+		t.Fatalf("UnmarshalText() error = %v", err)
+	}
+	if !reflect.DeepEqual(bdst.Entries(), bsrc.Entries()) {
+		t.Errorf("Text round trip: got %v, want %v", bdst.Entries(), bsrc.Entries())
+	}
+
+	// Values implementing `encoding.TextMarshaler`:
+	tsrc := New[int, time.Time]().Put(1, time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC))
+	if data, err = tsrc.MarshalText(); nil != err {
+		t.Fatalf("MarshalText() error = %v", err)
+	}
+	if want := "1=2025-03-04T05:06:07Z\n"; string(data) != want {
+		t.Errorf("MarshalText() = %q, want %q", data, want)
+	}
+	tdst := New[int, time.Time]()
+	if err = tdst.UnmarshalText(data); nil != err {
+		t.Fatalf("UnmarshalText() error = %v", err)
+	}
+	if got, _ := tdst.Get(1); !got.Equal(time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Errorf("Text round trip: got %v", got)
+	}
+} // Test_TPartitionMap_Text_RoundTrip()
+
+func Test_TPartitionMap_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name     string
+		pm       *TPartitionMap[int, int]
+		text     string
+		wantKeys []int
+		wantErr  bool
+	}{
+		{
+			name:     "Valid lines",
+			pm:       New[int, int]().Put(99, 99),
+			text:     "1=10\n\n2=20\r\n",
+			wantKeys: []int{1, 2},
+		},
+		{
+			name:     "Empty text",
+			pm:       New[int, int]().Put(99, 99),
+			text:     "",
+			wantKeys: []int{},
+		},
+		{
+			name:     "Missing separator",
+			pm:       New[int, int]().Put(99, 99),
+			text:     "1=10\n2\n",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid escape sequence",
+			pm:       New[int, int]().Put(99, 99),
+			text:     "1=1\\0\n",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Incomplete escape sequence",
+			pm:       New[int, int]().Put(99, 99),
+			text:     "1=10\\",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid key",
+			pm:       New[int, int]().Put(99, 99),
+			text:     "one=1\n",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:     "Invalid value",
+			pm:       New[int, int]().Put(99, 99),
+			text:     "1=one\n",
+			wantKeys: []int{99},
+			wantErr:  true,
+		},
+		{
+			name:    "Nil partition map",
+			pm:      nil,
+			text:    "1=1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.pm.UnmarshalText([]byte(tc.text))
+			if (nil != err) != tc.wantErr {
+				t.Errorf("UnmarshalText() error = %v, wantErr %v",
+					err, tc.wantErr)
+			}
+			if nil == tc.pm {
+				if !errors.Is(err, ErrNilMap) {
+					t.Errorf("UnmarshalText() error = %v, want %v",
+						err, ErrNilMap)
+				}
+				return
+			}
+			if got := tc.pm.Keys(); !reflect.DeepEqual(got, tc.wantKeys) {
+				t.Errorf("After UnmarshalText(), Keys() = %v, want %v",
+					got, tc.wantKeys)
+			}
+		})
+	}
+} // Test_TPartitionMap_UnmarshalText()
+
+func Test_TPartitionMap_WriteTo_ReadFrom(t *testing.T) {
+	type tValue struct {
+		Name  string
+		Count int
+	}
+
+	tests := []struct {
+		name string
+		src  *TPartitionMap[int, tValue]
+	}{
+		{
+			name: "Empty partition map",
+			src:  New[int, tValue](),
+		},
+		{
+			name: "Zero key and value",
+			src:  New[int, tValue]().Put(0, tValue{}),
+		},
+		{
+			name: "Partition map with values",
+			src: func() *TPartitionMap[int, tValue] {
+				pm := New[int, tValue]()
+				for i := range 1000 {
+					pm.Put(i-500, tValue{Name: fmt.Sprint(i), Count: i})
+				}
+				return pm
+			}(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			written, err := tc.src.WriteTo(&buf)
+			if nil != err {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if int64(buf.Len()) != written {
+				t.Errorf("WriteTo() = %d, want %d", written, buf.Len())
+			}
+
+			// Data following the stream must be left unread.
+			buf.WriteString("trailer")
+
+			dst := New[int, tValue]().Put(99999, tValue{Name: "stale"})
+			read, err := dst.ReadFrom(&buf)
+			if nil != err {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			if read != written {
+				t.Errorf("ReadFrom() = %d, want %d", read, written)
+			}
+			if "trailer" != buf.String() {
+				t.Errorf("ReadFrom() left %q, want %q", buf.String(), "trailer")
+			}
+			if !reflect.DeepEqual(dst.Entries(), tc.src.Entries()) {
+				t.Errorf("WriteTo/ReadFrom round trip: Entries() differ")
+			}
+		})
+	}
+} // Test_TPartitionMap_WriteTo_ReadFrom()
+
+func Test_TPartitionMap_ReadFrom_Errors(t *testing.T) {
+	var stream bytes.Buffer
+	if _, err := New[int, string]().Put(1, "one").Put(2, "two").WriteTo(&stream); nil != err {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	data := stream.Bytes()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{
+			name:    "Empty stream",
+			data:    nil,
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "Missing end marker",
+			data:    data[:len(data)-1],
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name:    "Truncated record",
+			data:    data[:len(data)/2],
+			wantErr: io.ErrUnexpectedEOF,
+		},
+		{
+			name: "Invalid record",
+			data: []byte{3, 'x', 'y', 'z', 0},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pm := New[int, string]().Put(99, "stale")
+			_, err := pm.ReadFrom(bytes.NewReader(tc.data))
+			if nil == err {
+				t.Fatalf("ReadFrom() expected an error")
+			}
+			if (nil != tc.wantErr) && !errors.Is(err, tc.wantErr) {
+				t.Errorf("ReadFrom() error = %v, want %v", err, tc.wantErr)
+			}
+			if v, ok := pm.Get(99); !ok || ("stale" != v) || (1 != pm.Len()) {
+				t.Errorf("ReadFrom() modified map on error")
+			}
+		})
+	}
+
+	var nilPM *TPartitionMap[int, string]
+	if _, err := nilPM.ReadFrom(bytes.NewReader(data)); !errors.Is(err, ErrNilMap) {
+		t.Errorf("ReadFrom() error = %v, want %v", err, ErrNilMap)
+	}
+	if _, err := nilPM.WriteTo(io.Discard); !errors.Is(err, ErrNilMap) {
+		t.Errorf("WriteTo() error = %v, want %v", err, ErrNilMap)
+	}
+} // Test_TPartitionMap_ReadFrom_Errors()
+
+/* _EoF_ */
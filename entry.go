@@ -0,0 +1,157 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides a handle for a single key of a partitioned map,
+// grouping the operations on that key.
+
+type (
+	// `TEntry` is a live handle for a single key of a partitioned map
+	// as returned by `TPartitionMap.Entry()`.
+	//
+	// The handle doesn't hold a copy of the key's value: each method
+	// operates on the map's current contents, using the key's
+	// partition which was resolved when the handle was created (or
+	// the partition which took over the key since).
+	TEntry[K cmp.Ordered, V any] struct {
+		pm  *TPartitionMap[K, V]
+		p   *tPartition[K, V]
+		key K
+	}
+)
+
+// `Delete()` removes the entry's key/value pair from the map.
+//
+// Returns:
+//   - `*TEntry[K, V]`: The entry itself, allowing method chaining.
+func (e *TEntry[K, V]) Delete() *TEntry[K, V] {
+	if nil == e {
+		return nil
+	}
+
+	if e.p.del(e.key) {
+		e.pm.notifyDelete(e.key)
+	}
+
+	return e
+} // Delete()
+
+// `Key()` returns the (normalised) key of the entry.
+//
+// Returns:
+//   - `K`: The entry's key.
+func (e *TEntry[K, V]) Key() (rKey K) {
+	if nil != e {
+		rKey = e.key
+	}
+
+	return
+} // Key()
+
+// `Present()` reports whether the entry's key is currently present
+// in the map.
+//
+// Returns:
+//   - `bool`: `true` if the key is present.
+func (e *TEntry[K, V]) Present() bool {
+	if nil == e {
+		return false
+	}
+
+	return e.p.has(e.key)
+} // Present()
+
+// `SetValue()` stores the given value for the entry's key in the map.
+//
+// Parameters:
+//   - `aValue`: The value to store.
+//
+// Returns:
+//   - `*TEntry[K, V]`: The entry itself, allowing method chaining.
+func (e *TEntry[K, V]) SetValue(aValue V) *TEntry[K, V] {
+	if nil == e {
+		return nil
+	}
+
+	e.pm.evict(e.p.put(e.key, aValue))
+	e.pm.notifyPut(e.key, aValue)
+
+	return e
+} // SetValue()
+
+// `Value()` retrieves the current value of the entry's key from the
+// map (see `TPartitionMap.Get()`).
+//
+// Returns:
+//   - `V`: The value associated with the key (if found).
+//   - `bool`: Indicating whether the key was found.
+func (e *TEntry[K, V]) Value() (rVal V, rOk bool) {
+	if nil == e {
+		return
+	}
+
+	rVal, rOk, expired := e.p.get(e.key)
+	if expired && e.p.expire(e.key) {
+		e.pm.notifyDelete(e.key)
+	}
+
+	return
+} // Value()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `Entry()` returns a handle for the given key.
+//
+// Each of the handle's methods operates atomically on the key's
+// partition, just like the map's respective methods (e.g. `Get()`,
+// `Put()`, `Delete()`, or `Has()`) do. Since the key is normalised
+// and its partition resolved only once, the handle is a convenient
+// and slightly cheaper way to perform several operations on one key.
+//
+// NOTE: The handle is a live view, not a snapshot: it always reflects
+// the map's current value of the key. It keeps working after the
+// map's partitions were replaced (e.g. by `Resize()`, `Rebalance()`,
+// `CompactSparse()`, `SetAll()`, or `Reset()`), since the retired
+// partition passes each call on to the key's current partition.
+// That costs an additional partition lookup per call, so a handle
+// used often should be fetched anew after such a change. After
+// `SetHasher()` the handle keeps using the key's previous partition
+// until `Rebalance()` moves the map's entries.
+// A sequence of calls (like in the example below) isn't atomic as a
+// whole; use `Compute()` or `Update()` for that.
+//
+// Example usage:
+//
+//	e := pm.Entry("visits")
+//	if v, ok := e.Value(); ok {
+//		e.SetValue(v + 1)
+//	}
+//
+// Parameters:
+//   - `aKey`: The key to get a handle for.
+//
+// Returns:
+//   - `*TEntry[K, V]`: The key's handle, or `nil` for a `nil` map.
+func (pm *TPartitionMap[K, V]) Entry(aKey K) *TEntry[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	aKey = pm.normKey(aKey)
+	p, _ := pm.partition(aKey, true)
+
+	return &TEntry[K, V]{pm: pm, p: p, key: aKey}
+} // Entry()
+
+/* _EoF_ */
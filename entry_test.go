@@ -0,0 +1,121 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TPartitionMap_Entry(t *testing.T) {
+	pm := New[string, int]()
+	var puts, deletes int
+	pm.OnPut(func(string, int) { puts++ })
+	pm.OnDelete(func(string) { deletes++ })
+
+	e := pm.Entry("key")
+	if e.Present() {
+		t.Error("Present() on new key = true, want false")
+	}
+	if v, ok := e.Value(); ok {
+		t.Errorf("Value() on new key = (%d, %v), want (0, false)", v, ok)
+	}
+
+	if got := e.SetValue(42); got != e {
+		t.Error("SetValue() returned different instance")
+	}
+	if !e.Present() {
+		t.Error("Present() after SetValue() = false, want true")
+	}
+	if v, ok := e.Value(); !ok || 42 != v {
+		t.Errorf("Value() = (%d, %v), want (42, true)", v, ok)
+	}
+	if v, ok := pm.Get("key"); !ok || 42 != v {
+		t.Errorf("Get() after SetValue() = (%d, %v), want (42, true)", v, ok)
+	}
+
+	// The handle is live: it sees changes made through the map.
+	pm.Put("key", 43)
+	if v, _ := e.Value(); 43 != v {
+		t.Errorf("Value() after Put() = %d, want 43", v)
+	}
+
+	if got := e.Delete(); got != e {
+		t.Error("Delete() returned different instance")
+	}
+	if e.Present() || pm.Has("key") {
+		t.Error("key still present after Delete()")
+	}
+	e.Delete() // deleting a missing key is a no-op
+	if 2 != puts || 1 != deletes {
+		t.Errorf("hooks called %d/%d times, want 2/1", puts, deletes)
+	}
+	if 0 != pm.Len() {
+		t.Errorf("Len() = %d, want 0", pm.Len())
+	}
+} // Test_TPartitionMap_Entry()
+
+func Test_TPartitionMap_Entry_Normalized(t *testing.T) {
+	pm := NewNormalized[string, int](strings.ToLower)
+	e := pm.Entry("MiXeD").SetValue(1)
+	if "mixed" != e.Key() {
+		t.Errorf("Key() = %q, want %q", e.Key(), "mixed")
+	}
+	if v, ok := pm.Get("MIXED"); !ok || 1 != v {
+		t.Errorf("Get(MIXED) = (%d, %v), want (1, true)", v, ok)
+	}
+} // Test_TPartitionMap_Entry_Normalized()
+
+func Test_TPartitionMap_Entry_Relayout(t *testing.T) {
+	pm := New[string, int]().Put("key", 1)
+	e := pm.Entry("key")
+
+	// The handle follows the key into the new partitions.
+	pm.Resize(7)
+	if v, ok := e.Value(); !ok || 1 != v {
+		t.Errorf("Value() after Resize() = (%d, %v), want (1, true)", v, ok)
+	}
+	e.SetValue(2)
+	if v, ok := pm.Get("key"); !ok || 2 != v {
+		t.Errorf("Get() after SetValue() = (%d, %v), want (2, true)", v, ok)
+	}
+
+	pm.SetAll(map[string]int{"key": 3})
+	if v, ok := e.Value(); !ok || 3 != v {
+		t.Errorf("Value() after SetAll() = (%d, %v), want (3, true)", v, ok)
+	}
+
+	pm.Reset()
+	if e.Present() {
+		t.Error("Present() after Reset() = true, want false")
+	}
+	e.SetValue(4)
+	if v, ok := pm.Get("key"); !ok || 4 != v {
+		t.Errorf("Get() after Reset() = (%d, %v), want (4, true)", v, ok)
+	}
+	if 1 != pm.Len() {
+		t.Errorf("Len() = %d, want 1", pm.Len())
+	}
+} // Test_TPartitionMap_Entry_Relayout()
+
+func Test_TPartitionMap_Entry_Nil(t *testing.T) {
+	var npm *TPartitionMap[string, int]
+	e := npm.Entry("key")
+	if nil != e {
+		t.Fatal("Entry() on nil map should return nil")
+	}
+	if e.Present() || nil != e.SetValue(1) || nil != e.Delete() || "" != e.Key() {
+		t.Error("methods of a nil entry should be no-ops")
+	}
+	if _, ok := e.Value(); ok {
+		t.Error("Value() of a nil entry = true, want false")
+	}
+} // Test_TPartitionMap_Entry_Nil()
+
+/* _EoF_ */
@@ -0,0 +1,196 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides an immutable copy of a partitioned map which
+// can be read without any locking.
+
+type (
+	// `TFrozenMap` is an immutable copy of a partitioned map as
+	// returned by `TPartitionMap.Freeze()`.
+	//
+	// Since its contents never change, it needs no locks at all and
+	// is safe for concurrent use. It offers no modifying methods.
+	TFrozenMap[K cmp.Ordered, V any] struct {
+		layout *TPartitionMap[K, V] // the source's (empty) partition layout
+		parts  []tKeyMap[K, V]      // the partitions' key/value pairs
+		keys   []K                  // all keys, sorted in ascending order
+	}
+)
+
+// `ForEach()` executes the provided function for each key/value pair
+// in ascending order of the keys.
+//
+// Parameters:
+//   - `aFunc`: The function to execute for each key/value pair.
+//
+// Returns:
+//   - `TReadOnlyMap[K, V]`: The frozen map itself, allowing method chaining.
+func (fm *TFrozenMap[K, V]) ForEach(aFunc func(aKey K, aValue V)) TReadOnlyMap[K, V] {
+	if (nil == fm) || (nil == aFunc) {
+		return fm
+	}
+
+	for _, key := range fm.keys {
+		aFunc(key, fm.parts[fm.layout.index(key)][key])
+	}
+
+	return fm
+} // ForEach()
+
+// `Get()` retrieves the value associated with the given key.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to be retrieved.
+//
+// Returns:
+//   - `V`: The value associated with the key (if found).
+//   - `bool`: Indicating whether the key was found.
+func (fm *TFrozenMap[K, V]) Get(aKey K) (rVal V, rOk bool) {
+	if nil == fm {
+		return
+	}
+
+	aKey = fm.layout.normKey(aKey)
+	rVal, rOk = fm.parts[fm.layout.index(aKey)][aKey]
+
+	return
+} // Get()
+
+// `Has()` reports whether the frozen map contains the given key.
+//
+// Parameters:
+//   - `aKey`: The key to look for.
+//
+// Returns:
+//   - `bool`: `true` if the key is present.
+func (fm *TFrozenMap[K, V]) Has(aKey K) bool {
+	_, ok := fm.Get(aKey)
+
+	return ok
+} // Has()
+
+// `Keys()` returns the keys of the frozen map, sorted in ascending
+// order.
+//
+// Returns:
+//   - `[]K`: A slice of all keys.
+func (fm *TFrozenMap[K, V]) Keys() []K {
+	if nil == fm {
+		return nil
+	}
+
+	return slices.Clone(fm.keys)
+} // Keys()
+
+// `Len()` returns the number of key/value pairs in the frozen map.
+//
+// Returns:
+//   - `int`: The number of key/value pairs.
+func (fm *TFrozenMap[K, V]) Len() int {
+	if nil == fm {
+		return 0
+	}
+
+	return len(fm.keys)
+} // Len()
+
+// `String()` returns a string representation of the frozen map in
+// the same format as `TPartitionMap.String()`.
+//
+// Returns:
+//   - `string`: The frozen map's string representation.
+func (fm *TFrozenMap[K, V]) String() string {
+	var builder strings.Builder
+	fm.ForEach(func(aKey K, aValue V) {
+		fmt.Fprintf(&builder, "%v: '%v'\n", aKey, aValue)
+	})
+
+	return builder.String()
+} // String()
+
+// `Values()` returns the values of the frozen map in the order of
+// their (sorted) keys.
+//
+// Returns:
+//   - `[]V`: A slice of all values.
+func (fm *TFrozenMap[K, V]) Values() []V {
+	if nil == fm {
+		return nil
+	}
+
+	result := make([]V, 0, len(fm.keys))
+	fm.ForEach(func(_ K, aValue V) {
+		result = append(result, aValue)
+	})
+
+	return result
+} // Values()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `Freeze()` returns an immutable copy of the partitioned map.
+//
+// The copy keeps the map's partition layout but none of its locks:
+// since its contents never change, reading it (e.g. by `Get()`)
+// doesn't need any synchronisation, which makes it the faster choice
+// for a map which becomes read-only after an initial load phase.
+// The frozen map offers only reading methods; it implements the
+// `TReadOnlyMap` interface.
+//
+// The copy is taken like `Snapshot()` does, i.e. consistent across
+// all partitions. Later modifications of the partitioned map don't
+// affect the frozen copy. Expiry times (see `NewWithTTL()`) are not
+// copied, i.e. the frozen map holds all entries present at the time
+// of freezing forever; call `DeleteExpired()` first to remove the
+// expired ones.
+//
+// Example usage:
+//
+//	config := loadConfig() // *TPartitionMap[string, string]
+//	settings := config.Freeze()
+//	// ...
+//	v, ok := settings.Get("listen")
+//
+// Returns:
+//   - `*TFrozenMap[K, V]`: An immutable copy of the partitioned map.
+func (pm *TPartitionMap[K, V]) Freeze() *TFrozenMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	snap := pm.Snapshot()
+	result := &TFrozenMap[K, V]{
+		layout: newEmptyLike[K, V, V](pm, len(snap.slots())),
+		parts:  make([]tKeyMap[K, V], len(snap.slots())),
+	}
+
+	sorted := make([][]K, 0, len(snap.slots()))
+	for idx, p := range snap.partitions() {
+		if nil == p {
+			continue
+		}
+		result.parts[idx] = p.kv // the snapshot's copy isn't shared
+		sorted = append(sorted, slices.Sorted(maps.Keys(p.kv)))
+	}
+	result.keys = mergeSorted(sorted)
+
+	return result
+} // Freeze()
+
+/* _EoF_ */
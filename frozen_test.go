@@ -0,0 +1,153 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TPartitionMap_Freeze(t *testing.T) {
+	const numKeys = 1000
+
+	pm := New[string, int]()
+	for i := range numKeys {
+		pm.Put(fmt.Sprintf("key%04d", i), i)
+	}
+	want := pm.ToMap()
+	wantKeys, wantValues, wantString := pm.Keys(), pm.Values(), pm.String()
+
+	fm := pm.Freeze()
+
+	// Later modifications of the source don't affect the frozen map.
+	pm.Put("key0000", -1).Delete("key0001").Put("new", 42)
+	pm.Clear()
+
+	if got := fm.Len(); numKeys != got {
+		t.Errorf("Len() = %d, want %d", got, numKeys)
+	}
+	for k, v := range want {
+		if got, ok := fm.Get(k); !ok || got != v {
+			t.Fatalf("Get(%q) = (%d, %v), want (%d, true)", k, got, ok, v)
+		}
+		if !fm.Has(k) {
+			t.Fatalf("Has(%q) = false, want true", k)
+		}
+	}
+	if _, ok := fm.Get("new"); ok || fm.Has("new") {
+		t.Error("frozen map reflects a later Put()")
+	}
+	if got := fm.Keys(); !slices.Equal(got, wantKeys) {
+		t.Error("Keys() differs from the source's keys")
+	}
+	if got := fm.Values(); !reflect.DeepEqual(got, wantValues) {
+		t.Error("Values() differs from the source's values")
+	}
+	if got := fm.String(); got != wantString {
+		t.Error("String() differs from the source's string")
+	}
+
+	// The returned keys are a copy.
+	keys := fm.Keys()
+	keys[0] = "changed"
+	if fm.Keys()[0] == "changed" {
+		t.Error("Keys() exposes the frozen map's storage")
+	}
+
+	// There's no way to modify the frozen map.
+	var ro TReadOnlyMap[string, int] = fm
+	for _, name := range []string{"Put", "Delete", "Clear", "Compute"} {
+		if _, ok := reflect.TypeOf(ro).MethodByName(name); ok {
+			t.Errorf("TFrozenMap has method %s", name)
+		}
+	}
+} // Test_TPartitionMap_Freeze()
+
+func Test_TPartitionMap_Freeze_Layout(t *testing.T) {
+	// Normalisation and custom hashing carry over.
+	pm := NewNormalized[string, int](strings.ToLower).Put("Alpha", 1).Put("beta", 2)
+	pm.SetHasher(func(aKey string) uint64 { return uint64(len(aKey)) }).Rebalance()
+	fm := pm.Freeze()
+	if v, ok := fm.Get("ALPHA"); !ok || 1 != v {
+		t.Errorf("Get(ALPHA) = (%d, %v), want (1, true)", v, ok)
+	}
+	if !fm.Has("Beta") {
+		t.Error("Has(Beta) = false, want true")
+	}
+
+	empty := New[int, int]().Freeze()
+	if 0 != empty.Len() || 0 != len(empty.Keys()) || "" != empty.String() {
+		t.Error("frozen empty map isn't empty")
+	}
+
+	var npm *TPartitionMap[string, int]
+	nfm := npm.Freeze()
+	if nil != nfm {
+		t.Fatal("Freeze() on nil map should return nil")
+	}
+	if _, ok := nfm.Get("a"); ok || 0 != nfm.Len() || nil != nfm.Keys() || nil != nfm.Values() {
+		t.Error("methods of a nil frozen map should return zero values")
+	}
+} // Test_TPartitionMap_Freeze_Layout()
+
+func Test_TFrozenMap_Concurrent(t *testing.T) {
+	pm := New[int, int]()
+	for i := range 1000 {
+		pm.Put(i, i)
+	}
+	fm := pm.Freeze()
+
+	var wg sync.WaitGroup
+	wg.Add(8)
+	for range 8 {
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				if v, ok := fm.Get(i); !ok || i != v {
+					t.Errorf("Get(%d) = (%d, %v), want (%d, true)", i, v, ok, i)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+} // Test_TFrozenMap_Concurrent()
+
+func Benchmark_TFrozenMap_Get(b *testing.B) {
+	const numKeys = 1 << 16
+
+	pm := New[string, int]()
+	keys := make([]string, numKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+		pm.Put(keys[i], i)
+	}
+	fm := pm.Freeze()
+
+	b.Run("TPartitionMap", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				pm.Get(keys[i%numKeys])
+			}
+		})
+	})
+	b.Run("TFrozenMap", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				fm.Get(keys[i%numKeys])
+			}
+		})
+	})
+} // Benchmark_TFrozenMap_Get()
+
+/* _EoF_ */
@@ -0,0 +1,639 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides package-level functions working on partitioned
+// maps. They are functions instead of methods because they either
+// need additional type parameters or further constraints on the
+// map's type parameters (which Go methods can't introduce).
+
+type (
+	// `TNumber` is a constraint permitting all integer and floating
+	// point types.
+	TNumber interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+			~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+			~float32 | ~float64
+	}
+)
+
+// `AddTo()` atomically adds the given delta to the value associated
+// with the given key, reporting the previous value as well.
+//
+// Like `Increment()` a missing key is created with `aDelta` as its
+// value, and the read-modify-write cycle is performed under the write
+// lock of the key's partition. Additionally the caller learns whether
+// the key existed before, e.g. to emit an event only on a counter's
+// first increment.
+//
+// Example usage:
+//
+//	if _, _, existed := AddTo(visitors, userID, 1); !existed {
+//		log.Println("new visitor:", userID)
+//	}
+//
+// Parameters:
+//   - `aPM`: The partitioned map holding the values.
+//   - `aKey`: The key of the value to add to.
+//   - `aDelta`: The amount to add (may be negative).
+//
+// Returns:
+//   - `V`: The new value associated with the key.
+//   - `V`: The previous value (or zero if the key didn't exist).
+//   - `bool`: Whether the key existed before.
+func AddTo[K cmp.Ordered, V TNumber](aPM *TPartitionMap[K, V], aKey K, aDelta V) (rNew, rOld V, rExisted bool) {
+	rNew, _ = aPM.Compute(aKey, func(aOld V, aFound bool) (V, bool) {
+		rOld, rExisted = aOld, aFound
+		return aOld + aDelta, false
+	})
+
+	return
+} // AddTo()
+
+// `ContainsValue()` reports whether any key of the given partitioned
+// map is associated with the given value.
+//
+// The partitions are scanned one after the other, holding only the
+// respective partition's read lock. The scan stops at the first
+// match. Since every value might have to be checked, this is an
+// O(n) operation.
+//
+// Parameters:
+//   - `aPM`: The partitioned map to search.
+//   - `aValue`: The value to look for.
+//
+// Returns:
+//   - `bool`: `true` if the value was found, `false` otherwise.
+func ContainsValue[K cmp.Ordered, V comparable](aPM *TPartitionMap[K, V], aValue V) bool {
+	if nil == aPM {
+		return false
+	}
+
+	for _, p := range aPM.partitions() {
+		if nil == p {
+			continue
+		}
+
+		p.RLock()
+		for _, v := range p.kv {
+			if v == aValue {
+				p.RUnlock()
+				return true
+			}
+		}
+		p.RUnlock()
+	}
+
+	return false
+} // ContainsValue()
+
+// `Diff()` compares two partitioned maps and returns the keys which
+// differ between them.
+//
+// Both maps are snapshotted (see `ToMap()`) before they are compared.
+// A `nil` map is treated like an empty one. Values are compared using
+// `==`. The returned slices are sorted in ascending order.
+//
+// Example usage:
+//
+//	added, removed, changed := Diff(yesterday, today)
+//
+// Parameters:
+//   - `aPM`: The original partitioned map.
+//   - `aOther`: The partitioned map to compare with.
+//
+// Returns:
+//   - `[]K`: The keys present in `aOther` but not in `aPM`.
+//   - `[]K`: The keys present in `aPM` but not in `aOther`.
+//   - `[]K`: The keys present in both maps with different values.
+func Diff[K cmp.Ordered, V comparable](aPM, aOther *TPartitionMap[K, V]) (rAdded, rRemoved, rChanged []K) {
+	old, cur := aPM.ToMap(), aOther.ToMap()
+
+	for k, v := range cur {
+		if ov, ok := old[k]; !ok {
+			rAdded = append(rAdded, k)
+		} else if ov != v {
+			rChanged = append(rChanged, k)
+		}
+	}
+	for k := range old {
+		if _, ok := cur[k]; !ok {
+			rRemoved = append(rRemoved, k)
+		}
+	}
+	slices.Sort(rAdded)
+	slices.Sort(rRemoved)
+	slices.Sort(rChanged)
+
+	return
+} // Diff()
+
+// `DifferenceKeys()` returns the keys present in the first
+// partitioned map but not in the second one.
+//
+// Each map is snapshotted (see `TPartitionMap.Snapshot()`) before the
+// keys are compared; the snapshots are taken one after the other so
+// that no locks of both maps are held at the same time.
+// A `nil` map is treated like an empty one.
+//
+// Example usage:
+//
+//	gone := DifferenceKeys(yesterday, today)
+//
+// Parameters:
+//   - `aPM1`: The partitioned map whose keys to return.
+//   - `aPM2`: The partitioned map whose keys to exclude.
+//
+// Returns:
+//   - `[]K`: The sorted keys of `aPM1` not present in `aPM2`.
+func DifferenceKeys[K cmp.Ordered, V, W any](aPM1 *TPartitionMap[K, V], aPM2 *TPartitionMap[K, W]) []K {
+	keys1, keys2 := aPM1.Snapshot().Keys(), aPM2.Snapshot().Keys()
+
+	var result []K
+	for i, j := 0, 0; i < len(keys1); {
+		c := -1 // keys left in the first list only
+		if j < len(keys2) {
+			c = cmp.Compare(keys1[i], keys2[j])
+		}
+		switch {
+		case 0 > c:
+			result = append(result, keys1[i])
+			i++
+		case 0 < c:
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+
+	return result
+} // DifferenceKeys()
+
+// `distinctValues()` collects the unique values of the given map.
+//
+// The partitions are scanned one after the other, holding only the
+// respective partition's read lock.
+//
+// Parameters:
+//   - `aPM`: The partitioned map to scan.
+//
+// Returns:
+//   - `map[V]struct{}`: The set of the map's values.
+func distinctValues[K cmp.Ordered, V comparable](aPM *TPartitionMap[K, V]) map[V]struct{} {
+	result := make(map[V]struct{})
+	for _, p := range aPM.partitions() {
+		if nil == p {
+			continue
+		}
+
+		p.RLock()
+		for _, v := range p.kv {
+			result[v] = struct{}{}
+		}
+		p.RUnlock()
+	}
+
+	return result
+} // distinctValues()
+
+// `DistinctValueCount()` returns the number of unique values in the
+// given partitioned map.
+//
+// All values are collected into a set while the partitions are
+// scanned one after the other, holding only the respective
+// partition's read lock. This takes O(n) time and, in the worst case
+// of all values being distinct, O(n) additional memory.
+//
+// Example usage:
+//
+//	countries := DistinctValueCount(userCountry)
+//
+// Parameters:
+//   - `aPM`: The partitioned map to scan.
+//
+// Returns:
+//   - `int`: The number of unique values (`0` for a `nil` map).
+func DistinctValueCount[K cmp.Ordered, V comparable](aPM *TPartitionMap[K, V]) int {
+	if nil == aPM {
+		return 0
+	}
+
+	return len(distinctValues(aPM))
+} // DistinctValueCount()
+
+// `DistinctValues()` returns the unique values of the given
+// partitioned map sorted in ascending order.
+//
+// Like `DistinctValueCount()` this takes O(n) time and up to O(n)
+// additional memory (plus the time to sort the unique values).
+//
+// Parameters:
+//   - `aPM`: The partitioned map to scan.
+//
+// Returns:
+//   - `[]V`: The sorted unique values (`nil` for a `nil` map).
+func DistinctValues[K, V cmp.Ordered](aPM *TPartitionMap[K, V]) []V {
+	if nil == aPM {
+		return nil
+	}
+
+	result := slices.Collect(maps.Keys(distinctValues(aPM)))
+	if nil == result {
+		result = []V{}
+	}
+	slices.Sort(result)
+
+	return result
+} // DistinctValues()
+
+// `Equal()` reports whether both partitioned maps contain the same
+// key/value pairs.
+//
+// Like `maps.Equal()` does for plain maps, a `nil` map is considered
+// equal to an empty map (since both have no entries).
+// Values are compared using `==`; for value types that aren't
+// comparable use `EqualFunc()` instead.
+//
+// NOTE: If either map is modified concurrently, the result reflects
+// some interleaving of the modifications and comparisons.
+//
+// Parameters:
+//   - `aPM1`: The first partitioned map to compare.
+//   - `aPM2`: The second partitioned map to compare.
+//
+// Returns:
+//   - `bool`: `true` if both maps are equal, `false` otherwise.
+func Equal[K cmp.Ordered, V comparable](aPM1, aPM2 *TPartitionMap[K, V]) bool {
+	return EqualFunc(aPM1, aPM2, func(aV1, aV2 V) bool {
+		return aV1 == aV2
+	})
+} // Equal()
+
+// `EqualFunc()` reports whether both partitioned maps contain the
+// same keys with values considered equal by the given function.
+//
+// First the lengths of both maps are compared. Then each partition
+// of `aPM1` is copied under its read lock and every key of the copy
+// is looked up in a copy of `aPM2` (see `ToMap()`), hence no two
+// partition locks are ever held at the same time. Neither map is
+// modified, i.e. hit counts, LRU recency, and expired entries are
+// left untouched.
+// A `nil` map is considered equal to an empty map.
+//
+// Parameters:
+//   - `aPM1`: The first partitioned map to compare.
+//   - `aPM2`: The second partitioned map to compare.
+//   - `aEq`: The function reporting whether two values are equal.
+//
+// Returns:
+//   - `bool`: `true` if both maps are equal, `false` otherwise.
+func EqualFunc[K cmp.Ordered, V, W any](aPM1 *TPartitionMap[K, V], aPM2 *TPartitionMap[K, W], aEq func(aV1 V, aV2 W) bool) bool {
+	if nil == aEq {
+		return false
+	}
+	if aPM1.Len() != aPM2.Len() {
+		return false
+	}
+	if nil == aPM1 {
+		// Both are empty.
+		return true
+	}
+
+	other := aPM2.ToMap()
+	for _, p := range aPM1.partitions() {
+		if nil == p {
+			continue
+		}
+		for k, v1 := range p.clone() {
+			v2, ok := other[k]
+			if !ok || !aEq(v1, v2) {
+				return false
+			}
+		}
+	}
+
+	return true
+} // EqualFunc()
+
+// `GroupBy()` returns a new partitioned map holding the values of
+// the given map grouped by a key derived from each key/value pair.
+//
+// The classification function is called for each pair without
+// holding any locks; the value is then appended to the list of its
+// group atomically (see `TPartitionMap.Compute()`). Within each
+// group the values are ordered by their keys in the source map.
+// The returned map uses the same number of partitions as the source.
+//
+// Example usage:
+//
+//	// group sessions by user:
+//	byUser := GroupBy(sessions, func(aID string, aSession *TSession) string {
+//		return aSession.User
+//	})
+//
+// Parameters:
+//   - `aPM`: The partitioned map whose values to group.
+//   - `aClassify`: The function computing a pair's group key.
+//
+// Returns:
+//   - `*TPartitionMap[G, []V]`: A new map holding the grouped values.
+func GroupBy[K cmp.Ordered, V any, G cmp.Ordered](aPM *TPartitionMap[K, V], aClassify func(aKey K, aValue V) G) *TPartitionMap[G, []V] {
+	if (nil == aPM) || (nil == aClassify) {
+		return nil
+	}
+
+	result := NewWithPartitions[G, []V](len(aPM.slots()))
+	for _, e := range aPM.Entries() {
+		result.Compute(aClassify(e.Key, e.Value), func(aGroup []V, aFound bool) ([]V, bool) {
+			return append(aGroup, e.Value), false
+		})
+	}
+
+	return result
+} // GroupBy()
+
+// `Increment()` atomically adds the given delta to the value
+// associated with the given key.
+//
+// If the key doesn't exist yet, it's created with `aDelta` as its
+// value. The read-modify-write cycle is performed under the write
+// lock of the key's partition, so concurrent increments of the same
+// key don't get lost.
+//
+// Example usage:
+//
+//	counters := New[string, int64]()
+//	Increment(counters, "requests", 1)
+//
+// Parameters:
+//   - `aPM`: The partitioned map holding the counters.
+//   - `aKey`: The key of the value to increment.
+//   - `aDelta`: The amount to add (may be negative).
+//
+// Returns:
+//   - `V`: The new value associated with the key.
+func Increment[K cmp.Ordered, V TNumber](aPM *TPartitionMap[K, V], aKey K, aDelta V) V {
+	result, _ := aPM.Compute(aKey, func(aOld V, aFound bool) (V, bool) {
+		return aOld + aDelta, false
+	})
+
+	return result
+} // Increment()
+
+// `IntersectKeys()` returns the keys present in both partitioned maps.
+//
+// Each map is snapshotted (see `TPartitionMap.Snapshot()`) before the
+// keys are compared; the snapshots are taken one after the other so
+// that no locks of both maps are held at the same time.
+// A `nil` map is treated like an empty one.
+//
+// Example usage:
+//
+//	both := IntersectKeys(admins, online)
+//
+// Parameters:
+//   - `aPM1`: The first partitioned map.
+//   - `aPM2`: The second partitioned map.
+//
+// Returns:
+//   - `[]K`: The sorted keys present in both maps.
+func IntersectKeys[K cmp.Ordered, V, W any](aPM1 *TPartitionMap[K, V], aPM2 *TPartitionMap[K, W]) []K {
+	keys1, keys2 := aPM1.Snapshot().Keys(), aPM2.Snapshot().Keys()
+
+	var result []K
+	for i, j := 0, 0; (i < len(keys1)) && (j < len(keys2)); {
+		switch c := cmp.Compare(keys1[i], keys2[j]); {
+		case 0 > c:
+			i++
+		case 0 < c:
+			j++
+		default:
+			result = append(result, keys1[i])
+			i++
+			j++
+		}
+	}
+
+	return result
+} // IntersectKeys()
+
+// `Invert()` returns a new partitioned map using the values of the
+// given map as keys and its keys as values.
+//
+// If several keys are associated with the same value, the largest
+// of those keys wins; use `InvertMulti()` to keep all of them.
+// The source partitions are read-locked only while they are copied.
+// The returned map uses the same number of partitions as the source.
+//
+// Example usage:
+//
+//	ids := New[string, int]().Put("alice", 1).Put("bob", 2)
+//	names := Invert(ids) // 1 -> "alice", 2 -> "bob"
+//
+// Parameters:
+//   - `aPM`: The partitioned map to invert.
+//
+// Returns:
+//   - `*TPartitionMap[V, K]`: A new map with keys and values swapped.
+func Invert[K, V cmp.Ordered](aPM *TPartitionMap[K, V]) *TPartitionMap[V, K] {
+	if nil == aPM {
+		return nil
+	}
+
+	// `Entries()` is sorted by key, so the largest key comes last.
+	result := NewWithPartitions[V, K](len(aPM.slots()))
+	for _, e := range aPM.Entries() {
+		result.Put(e.Value, e.Key)
+	}
+
+	return result
+} // Invert()
+
+// `InvertMulti()` returns a new partitioned map using the values of
+// the given map as keys and the list of their respective keys as
+// values.
+//
+// Unlike `Invert()` no key gets lost: each value is associated with
+// all keys it was associated with in the source, sorted in ascending
+// order. The source partitions are read-locked only while they are
+// copied. The returned map uses the same number of partitions as
+// the source.
+//
+// Parameters:
+//   - `aPM`: The partitioned map to invert.
+//
+// Returns:
+//   - `*TPartitionMap[V, []K]`: A new map with keys and values swapped.
+func InvertMulti[K, V cmp.Ordered](aPM *TPartitionMap[K, V]) *TPartitionMap[V, []K] {
+	if nil == aPM {
+		return nil
+	}
+
+	// `Entries()` is sorted by key, so the lists are sorted as well.
+	groups := make(map[V][]K)
+	for _, e := range aPM.Entries() {
+		groups[e.Value] = append(groups[e.Value], e.Key)
+	}
+
+	return NewWithPartitions[V, []K](len(aPM.slots())).PutAll(groups)
+} // InvertMulti()
+
+// `MapValues()` returns a new partitioned map with the same keys as
+// the given one but with values transformed by the given function.
+//
+// Each key is stored in the same partition (index) as in the source
+// map, so the new map has the identical key distribution. The source
+// partitions are read-locked only while they are copied, the
+// transformation function is called without holding any locks.
+//
+// Example usage:
+//
+//	strMap := MapValues(intMap, func(aKey string, aValue int) string {
+//		return strconv.Itoa(aValue)
+//	})
+//
+// Parameters:
+//   - `aPM`: The partitioned map to transform.
+//   - `aFunc`: The function computing the new values.
+//
+// Returns:
+//   - `*TPartitionMap[K, W]`: A new map with the transformed values.
+func MapValues[K cmp.Ordered, V, W any](aPM *TPartitionMap[K, V], aFunc func(aKey K, aValue V) W) *TPartitionMap[K, W] {
+	if (nil == aPM) || (nil == aFunc) {
+		return nil
+	}
+
+	list := aPM.partitions()
+	result := newEmptyLike[K, V, W](aPM, len(list))
+
+	for idx, p := range list {
+		if nil == p {
+			continue
+		}
+		src := p.clone()
+		kv := make(tKeyMap[K, W], len(src))
+		for k, v := range src {
+			kv[k] = aFunc(k, v)
+		}
+		result.slots()[idx].Store(wrapPartition(kv, &result.total))
+	}
+
+	return result
+} // MapValues()
+
+// `Reduce()` folds all key/value pairs of the given partitioned map
+// into a single result.
+//
+// The function is called for each key/value pair with the current
+// accumulator and returns the next one. The partitions are processed
+// serially, each on a snapshot taken under its read lock, so the
+// function is called without holding any locks.
+// The order in which the pairs are visited is unspecified.
+//
+// Example usage:
+//
+//	sum := Reduce(pm, 0, func(aSum int, aKey string, aValue int) int {
+//		return aSum + aValue
+//	})
+//
+// Parameters:
+//   - `aPM`: The partitioned map to reduce.
+//   - `aInitial`: The initial accumulator value.
+//   - `aFunc`: The function combining the accumulator and a pair.
+//
+// Returns:
+//   - `A`: The final accumulator value (`aInitial` for a `nil` or empty map).
+func Reduce[K cmp.Ordered, V, A any](aPM *TPartitionMap[K, V], aInitial A, aFunc func(aAcc A, aKey K, aValue V) A) A {
+	if (nil == aPM) || (nil == aFunc) {
+		return aInitial
+	}
+
+	result := aInitial
+	for k, v := range aPM.All() {
+		result = aFunc(result, k, v)
+	}
+
+	return result
+} // Reduce()
+
+// `UnionKeys()` returns the keys present in either partitioned map.
+//
+// Each map is snapshotted (see `TPartitionMap.Snapshot()`) before the
+// keys are combined; the snapshots are taken one after the other so
+// that no locks of both maps are held at the same time.
+// A `nil` map is treated like an empty one.
+//
+// Example usage:
+//
+//	all := UnionKeys(local, remote)
+//
+// Parameters:
+//   - `aPM1`: The first partitioned map.
+//   - `aPM2`: The second partitioned map.
+//
+// Returns:
+//   - `[]K`: The sorted keys present in at least one of the maps.
+func UnionKeys[K cmp.Ordered, V, W any](aPM1 *TPartitionMap[K, V], aPM2 *TPartitionMap[K, W]) []K {
+	keys1, keys2 := aPM1.Snapshot().Keys(), aPM2.Snapshot().Keys()
+
+	var result []K
+	if total := len(keys1) + len(keys2); 0 < total {
+		result = make([]K, 0, total)
+	}
+	i, j := 0, 0
+	for (i < len(keys1)) && (j < len(keys2)) {
+		switch c := cmp.Compare(keys1[i], keys2[j]); {
+		case 0 > c:
+			result = append(result, keys1[i])
+			i++
+		case 0 < c:
+			result = append(result, keys2[j])
+			j++
+		default:
+			result = append(result, keys1[i])
+			i++
+			j++
+		}
+	}
+	result = append(result, keys1[i:]...)
+
+	return append(result, keys2[j:]...)
+} // UnionKeys()
+
+// `ValuesSorted()` returns a slice of all values in the given map
+// sorted in ascending order.
+//
+// Other than `TPartitionMap.Values()`, which orders the values by
+// their keys, this orders them by the values themselves. For value
+// types that aren't ordered use `TPartitionMap.ValuesSortedFunc()`.
+//
+// Example usage:
+//
+//	scores := ValuesSorted(highscores)
+//
+// Parameters:
+//   - `aPM`: The partitioned map whose values to return.
+//
+// Returns:
+//   - `[]V`: The sorted values (`nil` for a `nil` map).
+func ValuesSorted[K, V cmp.Ordered](aPM *TPartitionMap[K, V]) []V {
+	result := aPM.ValuesUnsorted()
+	slices.Sort(result)
+
+	return result
+} // ValuesSorted()
+
+/* _EoF_ */
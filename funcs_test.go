@@ -0,0 +1,954 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_AddTo(t *testing.T) {
+	pm := New[string, int64]()
+
+	tests := []struct {
+		name        string
+		delta       int64
+		wantNew     int64
+		wantOld     int64
+		wantExisted bool
+	}{
+		{"First add", 5, 5, 0, false},
+		{"Second add", 3, 8, 5, true},
+		{"Negative delta", -10, -2, 8, true},
+		{"Zero delta", 0, -2, -2, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotNew, gotOld, gotExisted := AddTo(pm, "counter", tc.delta)
+			if (gotNew != tc.wantNew) || (gotOld != tc.wantOld) || (gotExisted != tc.wantExisted) {
+				t.Errorf("AddTo() = (%d, %d, %v), want (%d, %d, %v)",
+					gotNew, gotOld, gotExisted,
+					tc.wantNew, tc.wantOld, tc.wantExisted)
+			}
+			if got, _ := pm.Get("counter"); got != tc.wantNew {
+				t.Errorf("After AddTo(), Get() = %d, want %d", got, tc.wantNew)
+			}
+		})
+	}
+
+	// A deleted key starts over.
+	pm.Delete("counter")
+	if _, _, existed := AddTo(pm, "counter", 1); existed {
+		t.Error("AddTo() after Delete() reported an existing key")
+	}
+
+	fpm := New[string, float64]()
+	AddTo(fpm, "sum", 0.5)
+	if n, o, ok := AddTo(fpm, "sum", 1.25); (1.75 != n) || (0.5 != o) || !ok {
+		t.Errorf("AddTo() = (%v, %v, %v), want (1.75, 0.5, true)", n, o, ok)
+	}
+
+	var npm *TPartitionMap[string, int]
+	if n, o, ok := AddTo(npm, "counter", 1); (0 != n) || (0 != o) || ok {
+		t.Errorf("AddTo() on nil map = (%d, %d, %v), want (0, 0, false)", n, o, ok)
+	}
+} // Test_AddTo()
+
+func Test_AddTo_Concurrent(t *testing.T) {
+	const (
+		numGoroutines = 1 << 6
+		numAdds       = 1 << 8
+	)
+
+	pm := New[string, int64]()
+	var (
+		firsts atomic.Int64
+		wg     sync.WaitGroup
+	)
+	wg.Add(numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			for range numAdds {
+				if _, _, existed := AddTo(pm, "counter", 1); !existed {
+					firsts.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := firsts.Load(); 1 != got {
+		t.Errorf("AddTo() reported %d first adds, want 1", got)
+	}
+	want := int64(numGoroutines * numAdds)
+	if got, _ := pm.Get("counter"); got != want {
+		t.Errorf("AddTo() total = %d, want %d", got, want)
+	}
+} // Test_AddTo_Concurrent()
+
+func Test_ContainsValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		pm    *TPartitionMap[string, int]
+		value int
+		want  bool
+	}{
+		{
+			name:  "Value found",
+			pm:    New[string, int]().Put("key1", 100).Put("key2", 200),
+			value: 200,
+			want:  true,
+		},
+		{
+			name:  "Value not found",
+			pm:    New[string, int]().Put("key1", 100).Put("key2", 200),
+			value: 300,
+			want:  false,
+		},
+		{
+			name:  "Zero value not found",
+			pm:    New[string, int]().Put("key1", 100),
+			value: 0,
+			want:  false,
+		},
+		{
+			name:  "Empty partition map",
+			pm:    New[string, int](),
+			value: 0,
+			want:  false,
+		},
+		{
+			name:  "Nil partition map",
+			pm:    nil,
+			value: 0,
+			want:  false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ContainsValue(tc.pm, tc.value); got != tc.want {
+				t.Errorf("ContainsValue() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_ContainsValue()
+
+func Test_Diff(t *testing.T) {
+	tests := []struct {
+		name        string
+		pm          *TPartitionMap[string, int]
+		other       *TPartitionMap[string, int]
+		wantAdded   []string
+		wantRemoved []string
+		wantChanged []string
+	}{
+		{
+			name:  "Identical maps",
+			pm:    New[string, int]().Put("a", 1).Put("b", 2),
+			other: NewWithPartitions[string, int](3).Put("b", 2).Put("a", 1),
+		},
+		{
+			name:      "Added keys",
+			pm:        New[string, int]().Put("a", 1),
+			other:     New[string, int]().Put("a", 1).Put("c", 3).Put("b", 2),
+			wantAdded: []string{"b", "c"},
+		},
+		{
+			name:        "Removed keys",
+			pm:          New[string, int]().Put("a", 1).Put("c", 3).Put("b", 2),
+			other:       New[string, int]().Put("b", 2),
+			wantRemoved: []string{"a", "c"},
+		},
+		{
+			name:        "Changed values",
+			pm:          New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			other:       New[string, int]().Put("a", 1).Put("b", 20).Put("c", 30),
+			wantChanged: []string{"b", "c"},
+		},
+		{
+			name:        "Mixed differences",
+			pm:          New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			other:       New[string, int]().Put("b", 2).Put("c", 4).Put("d", 5),
+			wantAdded:   []string{"d"},
+			wantRemoved: []string{"a"},
+			wantChanged: []string{"c"},
+		},
+		{
+			name:      "Nil receiver",
+			pm:        nil,
+			other:     New[string, int]().Put("a", 1),
+			wantAdded: []string{"a"},
+		},
+		{
+			name:        "Nil other",
+			pm:          New[string, int]().Put("a", 1),
+			other:       nil,
+			wantRemoved: []string{"a"},
+		},
+		{
+			name:  "Both nil",
+			pm:    nil,
+			other: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			added, removed, changed := Diff(tc.pm, tc.other)
+			if !slices.Equal(added, tc.wantAdded) {
+				t.Errorf("Diff() added = %v, want %v", added, tc.wantAdded)
+			}
+			if !slices.Equal(removed, tc.wantRemoved) {
+				t.Errorf("Diff() removed = %v, want %v", removed, tc.wantRemoved)
+			}
+			if !slices.Equal(changed, tc.wantChanged) {
+				t.Errorf("Diff() changed = %v, want %v", changed, tc.wantChanged)
+			}
+		})
+	}
+} // Test_Diff()
+
+func Test_DistinctValues(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []int
+	}{
+		{
+			name: "All distinct",
+			pm:   New[string, int]().Put("a", 3).Put("b", 1).Put("c", 2),
+			want: []int{1, 2, 3},
+		},
+		{
+			name: "All identical",
+			pm:   New[string, int]().Put("a", 7).Put("b", 7).Put("c", 7),
+			want: []int{7},
+		},
+		{
+			name: "Mixed",
+			pm: New[string, int]().Put("a", 1).Put("b", 2).Put("c", 1).
+				Put("d", 3).Put("e", 2),
+			want: []int{1, 2, 3},
+		},
+		{
+			name: "Empty map",
+			pm:   New[string, int](),
+			want: []int{},
+		},
+		{
+			name: "Nil map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DistinctValueCount(tc.pm); len(tc.want) != got {
+				t.Errorf("DistinctValueCount() = %d, want %d", got, len(tc.want))
+			}
+			if got := DistinctValues(tc.pm); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("DistinctValues() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// Comparable but unordered values can be counted.
+	type tPoint struct{ x, y int }
+	points := New[string, tPoint]().
+		Put("a", tPoint{1, 2}).Put("b", tPoint{2, 1}).Put("c", tPoint{1, 2})
+	if got := DistinctValueCount(points); 2 != got {
+		t.Errorf("DistinctValueCount() = %d, want 2", got)
+	}
+} // Test_DistinctValues()
+
+func Test_SetKeys(t *testing.T) {
+	set := func(aKeys ...string) *TPartitionMap[string, struct{}] {
+		result := New[string, struct{}]()
+		for _, k := range aKeys {
+			result.Put(k, struct{}{})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		pm1      *TPartitionMap[string, struct{}]
+		pm2      *TPartitionMap[string, struct{}]
+		wantAnd  []string
+		wantOr   []string
+		wantDiff []string
+	}{
+		{
+			name:     "Overlapping",
+			pm1:      set("d", "a", "c", "b"),
+			pm2:      set("e", "c", "d", "f"),
+			wantAnd:  []string{"c", "d"},
+			wantOr:   []string{"a", "b", "c", "d", "e", "f"},
+			wantDiff: []string{"a", "b"},
+		},
+		{
+			name:     "Disjoint",
+			pm1:      set("c", "a"),
+			pm2:      set("d", "b"),
+			wantAnd:  nil,
+			wantOr:   []string{"a", "b", "c", "d"},
+			wantDiff: []string{"a", "c"},
+		},
+		{
+			name:     "Identical",
+			pm1:      set("b", "a", "c"),
+			pm2:      set("c", "b", "a"),
+			wantAnd:  []string{"a", "b", "c"},
+			wantOr:   []string{"a", "b", "c"},
+			wantDiff: nil,
+		},
+		{
+			name:     "Subset",
+			pm1:      set("b"),
+			pm2:      set("a", "b", "c"),
+			wantAnd:  []string{"b"},
+			wantOr:   []string{"a", "b", "c"},
+			wantDiff: nil,
+		},
+		{
+			name:     "Nil first",
+			pm1:      nil,
+			pm2:      set("b", "a"),
+			wantAnd:  nil,
+			wantOr:   []string{"a", "b"},
+			wantDiff: nil,
+		},
+		{
+			name:     "Nil second",
+			pm1:      set("b", "a"),
+			pm2:      nil,
+			wantAnd:  nil,
+			wantOr:   []string{"a", "b"},
+			wantDiff: []string{"a", "b"},
+		},
+		{
+			name: "Both nil",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IntersectKeys(tc.pm1, tc.pm2); !slices.Equal(got, tc.wantAnd) {
+				t.Errorf("IntersectKeys() = %v, want %v", got, tc.wantAnd)
+			}
+			if got := UnionKeys(tc.pm1, tc.pm2); !slices.Equal(got, tc.wantOr) {
+				t.Errorf("UnionKeys() = %v, want %v", got, tc.wantOr)
+			}
+			if got := DifferenceKeys(tc.pm1, tc.pm2); !slices.Equal(got, tc.wantDiff) {
+				t.Errorf("DifferenceKeys() = %v, want %v", got, tc.wantDiff)
+			}
+		})
+	}
+
+	// The value types of both maps may differ.
+	counts := New[string, int]().Put("a", 1).Put("x", 2)
+	if got := IntersectKeys(counts, set("a", "b")); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("IntersectKeys() = %v, want [a]", got)
+	}
+
+	// NaN keys are compared like `cmp.Compare()` does.
+	nan := math.NaN()
+	fpm1 := New[float64, int]().Put(2, 0).Put(nan, 0).Put(1, 0)
+	fpm2 := New[float64, int]().Put(3, 0).Put(2, 0)
+	same := func(a, b float64) bool { return 0 == cmp.Compare(a, b) }
+	if got, want := IntersectKeys(fpm1, fpm2), []float64{2}; !slices.EqualFunc(got, want, same) {
+		t.Errorf("IntersectKeys() = %v, want %v", got, want)
+	}
+	if got, want := UnionKeys(fpm1, fpm2), []float64{nan, 1, 2, 3}; !slices.EqualFunc(got, want, same) {
+		t.Errorf("UnionKeys() = %v, want %v", got, want)
+	}
+	if got, want := DifferenceKeys(fpm1, fpm2), []float64{nan, 1}; !slices.EqualFunc(got, want, same) {
+		t.Errorf("DifferenceKeys() = %v, want %v", got, want)
+	}
+} // Test_SetKeys()
+
+func Test_Equal(t *testing.T) {
+	tests := []struct {
+		name string
+		pm1  *TPartitionMap[string, int]
+		pm2  *TPartitionMap[string, int]
+		want bool
+	}{
+		{
+			name: "Equal maps",
+			pm1:  New[string, int]().Put("a", 1).Put("b", 2),
+			pm2:  New[string, int]().Put("b", 2).Put("a", 1),
+			want: true,
+		},
+		{
+			name: "Different partition counts",
+			pm1:  New[string, int]().Put("a", 1).Put("b", 2),
+			pm2:  NewWithPartitions[string, int](3).Put("b", 2).Put("a", 1),
+			want: true,
+		},
+		{
+			name: "Different values",
+			pm1:  New[string, int]().Put("a", 1).Put("b", 2),
+			pm2:  New[string, int]().Put("a", 1).Put("b", 3),
+			want: false,
+		},
+		{
+			name: "Different key sets",
+			pm1:  New[string, int]().Put("a", 1).Put("b", 2),
+			pm2:  New[string, int]().Put("a", 1).Put("c", 2),
+			want: false,
+		},
+		{
+			name: "Different lengths",
+			pm1:  New[string, int]().Put("a", 1).Put("b", 2),
+			pm2:  New[string, int]().Put("a", 1),
+			want: false,
+		},
+		{
+			name: "Both nil",
+			pm1:  nil,
+			pm2:  nil,
+			want: true,
+		},
+		{
+			name: "Nil and empty",
+			pm1:  nil,
+			pm2:  New[string, int](),
+			want: true,
+		},
+		{
+			name: "Empty and nil",
+			pm1:  New[string, int](),
+			pm2:  nil,
+			want: true,
+		},
+		{
+			name: "Nil and non-empty",
+			pm1:  nil,
+			pm2:  New[string, int]().Put("a", 1),
+			want: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Equal(tc.pm1, tc.pm2); got != tc.want {
+				t.Errorf("Equal() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+} // Test_Equal()
+
+func Test_EqualFunc(t *testing.T) {
+	sameLen := func(aV1 []int, aV2 string) bool {
+		return len(aV1) == len(aV2)
+	}
+
+	tests := []struct {
+		name string
+		pm1  *TPartitionMap[int, []int]
+		pm2  *TPartitionMap[int, string]
+		eq   func([]int, string) bool
+		want bool
+	}{
+		{
+			name: "Equal by function",
+			pm1:  New[int, []int]().Put(1, []int{1}).Put(2, []int{1, 2}),
+			pm2:  New[int, string]().Put(1, "a").Put(2, "ab"),
+			eq:   sameLen,
+			want: true,
+		},
+		{
+			name: "Different by function",
+			pm1:  New[int, []int]().Put(1, []int{1}).Put(2, []int{1, 2}),
+			pm2:  New[int, string]().Put(1, "a").Put(2, "abc"),
+			eq:   sameLen,
+			want: false,
+		},
+		{
+			name: "Different key sets",
+			pm1:  New[int, []int]().Put(1, []int{1}),
+			pm2:  New[int, string]().Put(2, "a"),
+			eq:   sameLen,
+			want: false,
+		},
+		{
+			name: "Nil function",
+			pm1:  New[int, []int](),
+			pm2:  New[int, string](),
+			eq:   nil,
+			want: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := EqualFunc(tc.pm1, tc.pm2, tc.eq); got != tc.want {
+				t.Errorf("EqualFunc() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// Comparing must not count as a lookup in either map.
+	pm1 := NewWithHitStats[string, int]().Put("a", 1)
+	pm2 := NewWithHitStats[string, int]().Put("a", 1)
+	if !Equal(pm1, pm2) {
+		t.Fatal("Equal() = false, want true")
+	}
+	if got := pm1.HitCount("a") + pm2.HitCount("a"); 0 != got {
+		t.Errorf("EqualFunc() counted %d hits, want 0", got)
+	}
+} // Test_EqualFunc()
+
+func Test_GroupBy(t *testing.T) {
+	parity := func(aKey int, aValue string) string {
+		if 0 == aKey%2 {
+			return "even"
+		}
+		return "odd"
+	}
+
+	tests := []struct {
+		name     string
+		pm       *TPartitionMap[int, string]
+		classify func(int, string) string
+		want     map[string][]string
+	}{
+		{
+			name: "Group by parity",
+			pm: New[int, string]().
+				Put(1, "one").
+				Put(2, "two").
+				Put(3, "three").
+				Put(4, "four").
+				Put(5, "five"),
+			classify: parity,
+			want: map[string][]string{
+				"even": {"two", "four"},
+				"odd":  {"one", "three", "five"},
+			},
+		},
+		{
+			name:     "Single group",
+			pm:       New[int, string]().Put(2, "two").Put(4, "four"),
+			classify: parity,
+			want:     map[string][]string{"even": {"two", "four"}},
+		},
+		{
+			name:     "Empty partition map",
+			pm:       New[int, string](),
+			classify: parity,
+			want:     map[string][]string{},
+		},
+		{
+			name:     "Nil function",
+			pm:       New[int, string]().Put(1, "one"),
+			classify: nil,
+			want:     nil,
+		},
+		{
+			name:     "Nil partition map",
+			pm:       nil,
+			classify: parity,
+			want:     nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := GroupBy(tc.pm, tc.classify)
+			if nil == tc.want {
+				if nil != got {
+					t.Errorf("GroupBy() = %v, want nil", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got.ToMap(), tc.want) {
+				t.Errorf("GroupBy() = %v, want %v", got.ToMap(), tc.want)
+			}
+		})
+	}
+} // Test_GroupBy()
+
+func Test_Increment(t *testing.T) {
+	tests := []struct {
+		name  string
+		pm    *TPartitionMap[string, int64]
+		key   string
+		delta int64
+		want  int64
+	}{
+		{
+			name:  "New key",
+			pm:    New[string, int64](),
+			key:   "counter",
+			delta: 5,
+			want:  5,
+		},
+		{
+			name:  "Existing key",
+			pm:    New[string, int64]().Put("counter", 37),
+			key:   "counter",
+			delta: 5,
+			want:  42,
+		},
+		{
+			name:  "Negative delta",
+			pm:    New[string, int64]().Put("counter", 37),
+			key:   "counter",
+			delta: -40,
+			want:  -3,
+		},
+		{
+			name:  "Nil partition map",
+			pm:    nil,
+			key:   "counter",
+			delta: 5,
+			want:  0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Increment(tc.pm, tc.key, tc.delta); got != tc.want {
+				t.Errorf("Increment() = %d, want %d", got, tc.want)
+			}
+			if nil == tc.pm {
+				return
+			}
+			if got, _ := tc.pm.Get(tc.key); got != tc.want {
+				t.Errorf("After Increment(), Get() = %d, want %d",
+					got, tc.want)
+			}
+		})
+	}
+
+	fpm := New[string, float64]()
+	Increment(fpm, "sum", 0.5)
+	if got := Increment(fpm, "sum", 1.25); 1.75 != got {
+		t.Errorf("Increment() = %v, want %v", got, 1.75)
+	}
+} // Test_Increment()
+
+func Test_Increment_Concurrent(t *testing.T) {
+	const (
+		numGoroutines = 1 << 6
+		numIncrements = 1 << 10
+	)
+
+	pm := New[string, int64]()
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			for range numIncrements {
+				Increment(pm, "counter", 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := int64(numGoroutines * numIncrements)
+	if got, _ := pm.Get("counter"); got != want {
+		t.Errorf("Increment() total = %d, want %d", got, want)
+	}
+} // Test_Increment_Concurrent()
+
+func Test_Invert(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want map[int]string
+	}{
+		{
+			name: "Unique values",
+			pm:   New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			want: map[int]string{1: "a", 2: "b", 3: "c"},
+		},
+		{
+			name: "Duplicate values",
+			pm:   New[string, int]().Put("b", 1).Put("c", 1).Put("a", 1).Put("d", 2),
+			want: map[int]string{1: "c", 2: "d"},
+		},
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: map[int]string{},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Invert(tc.pm)
+			if nil == tc.want {
+				if nil != got {
+					t.Errorf("Invert() = %v, want nil", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got.ToMap(), tc.want) {
+				t.Errorf("Invert() = %v, want %v", got.ToMap(), tc.want)
+			}
+		})
+	}
+} // Test_Invert()
+
+func Test_InvertMulti(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want map[int][]string
+	}{
+		{
+			name: "Unique values",
+			pm:   New[string, int]().Put("a", 1).Put("b", 2),
+			want: map[int][]string{1: {"a"}, 2: {"b"}},
+		},
+		{
+			name: "Duplicate values",
+			pm:   New[string, int]().Put("b", 1).Put("c", 1).Put("a", 1).Put("d", 2),
+			want: map[int][]string{1: {"a", "b", "c"}, 2: {"d"}},
+		},
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: map[int][]string{},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := InvertMulti(tc.pm)
+			if nil == tc.want {
+				if nil != got {
+					t.Errorf("InvertMulti() = %v, want nil", got)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got.ToMap(), tc.want) {
+				t.Errorf("InvertMulti() = %v, want %v", got.ToMap(), tc.want)
+			}
+		})
+	}
+} // Test_InvertMulti()
+
+func Test_MapValues(t *testing.T) {
+	toString := func(aKey string, aValue int) string {
+		return aKey + "=" + strconv.Itoa(aValue)
+	}
+
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		fn   func(string, int) string
+		want map[string]string
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			fn:   toString,
+			want: map[string]string{},
+		},
+		{
+			name: "Partition map with values",
+			pm: New[string, int]().
+				Put("key1", 100).
+				Put("key2", 200).
+				Put("key3", 300),
+			fn: toString,
+			want: map[string]string{
+				"key1": "key1=100",
+				"key2": "key2=200",
+				"key3": "key3=300",
+			},
+		},
+		{
+			name: "Nil function",
+			pm:   New[string, int]().Put("key1", 100),
+			fn:   nil,
+			want: nil,
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			fn:   toString,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MapValues(tc.pm, tc.fn)
+			if nil == tc.want {
+				if nil != got {
+					t.Errorf("MapValues() = %v, want nil", got)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(got.ToMap(), tc.want) {
+				t.Errorf("MapValues() = %v, want %v",
+					got.ToMap(), tc.want)
+			}
+			if !reflect.DeepEqual(got.Keys(), tc.pm.Keys()) {
+				t.Errorf("MapValues() Keys() = %v, want %v",
+					got.Keys(), tc.pm.Keys())
+			}
+		})
+	}
+} // Test_MapValues()
+
+func Test_MapValues_Distribution(t *testing.T) {
+	pm := NewWithPartitions[int, int](17)
+	for i := range 1000 {
+		pm.Put(i, i)
+	}
+
+	got := MapValues(pm, func(aKey int, aValue int) string {
+		return strconv.Itoa(aValue)
+	})
+
+	if !reflect.DeepEqual(got.PartitionStats(), pm.PartitionStats()) {
+		t.Errorf("MapValues() distribution = %v, want %v",
+			got.PartitionStats(), pm.PartitionStats())
+	}
+} // Test_MapValues_Distribution()
+
+func Test_Reduce(t *testing.T) {
+	sum := func(aAcc int, aKey string, aValue int) int {
+		return aAcc + aValue
+	}
+
+	tests := []struct {
+		name    string
+		pm      *TPartitionMap[string, int]
+		initial int
+		fn      func(int, string, int) int
+		want    int
+	}{
+		{
+			name:    "Sum values",
+			pm:      New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			initial: 0,
+			fn:      sum,
+			want:    6,
+		},
+		{
+			name:    "Sum values with initial value",
+			pm:      New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			initial: 100,
+			fn:      sum,
+			want:    106,
+		},
+		{
+			name:    "Empty partition map",
+			pm:      New[string, int](),
+			initial: 42,
+			fn:      sum,
+			want:    42,
+		},
+		{
+			name:    "Nil function",
+			pm:      New[string, int]().Put("a", 1),
+			initial: 42,
+			fn:      nil,
+			want:    42,
+		},
+		{
+			name:    "Nil partition map",
+			pm:      nil,
+			initial: 42,
+			fn:      sum,
+			want:    42,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Reduce(tc.pm, tc.initial, tc.fn); got != tc.want {
+				t.Errorf("Reduce() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+} // Test_Reduce()
+
+func Test_Reduce_CollectKeys(t *testing.T) {
+	pm := New[string, int]().Put("b", 2).Put("a", 1).Put("c", 3)
+
+	keys := Reduce(pm, []string{}, func(aAcc []string, aKey string, aValue int) []string {
+		return append(aAcc, aKey)
+	})
+	slices.Sort(keys)
+
+	if got := strings.Join(keys, ""); "abc" != got {
+		t.Errorf("Reduce() = %q, want %q", got, "abc")
+	}
+} // Test_Reduce_CollectKeys()
+
+func Test_ValuesSorted(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []int
+	}{
+		{
+			name: "Unordered values",
+			pm:   New[string, int]().Put("a", 30).Put("b", -10).Put("c", 20).Put("d", 0),
+			want: []int{-10, 0, 20, 30},
+		},
+		{
+			name: "Duplicate values",
+			pm:   New[string, int]().Put("a", 2).Put("b", 1).Put("c", 2),
+			want: []int{1, 2, 2},
+		},
+		{
+			name: "Empty map",
+			pm:   New[string, int](),
+			want: []int{},
+		},
+		{
+			name: "Nil map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ValuesSorted(tc.pm)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ValuesSorted() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+} // Test_ValuesSorted()
+
+/* _EoF_ */
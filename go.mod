@@ -1,3 +1,3 @@
 module github.com/mwat56/partitionmap
 
-go 1.22
+go 1.23
@@ -0,0 +1,190 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides the optional read statistics of a partitioned
+// map, counting how often each key was retrieved by `Get()`.
+//
+// Each key's counter is created (under the partition's write lock)
+// when the key is stored and removed together with the key. Reading
+// a key increments its counter atomically while holding only the
+// partition's read lock, so concurrent readers don't block each
+// other.
+//
+// The counters cost additional memory of about 50 bytes per key (an
+// entry in a second map plus an 8-byte counter allocated on the heap).
+// Updating an existing key keeps its counter. Moving the entries to
+// new partitions (i.e. `Rebalance()` or `Resize()`) resets their
+// counters. The counters are not inherited by
+// copies like those made by `Clone()` or `Snapshot()`.
+
+type (
+	// `tHitMap` holds the read counters of a partition's keys.
+	tHitMap[K cmp.Ordered] map[K]*atomic.Uint64
+
+	// `tHitCount` is a key along with its read counter as collected
+	// by `TopKeys()`.
+	tHitCount[K cmp.Ordered] struct {
+		key  K
+		hits uint64
+	}
+)
+
+// `add()` creates a counter for the given key unless it exists.
+//
+// The caller must hold the partition's write lock.
+//
+// Parameters:
+//   - `aKey`: The key to count the reads of.
+func (hm tHitMap[K]) add(aKey K) {
+	if nil == hm {
+		return
+	}
+
+	if _, ok := hm[aKey]; !ok {
+		hm[aKey] = new(atomic.Uint64)
+	}
+} // add()
+
+// `count()` returns the number of reads of the given key.
+//
+// The caller must hold (at least) the partition's read lock.
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//
+// Returns:
+//   - `uint64`: The key's number of reads.
+func (hm tHitMap[K]) count(aKey K) uint64 {
+	if counter, ok := hm[aKey]; ok {
+		return counter.Load()
+	}
+
+	return 0
+} // count()
+
+// `hit()` increments the counter of the given key.
+//
+// The caller must hold (at least) the partition's read lock.
+//
+// Parameters:
+//   - `aKey`: The key read.
+func (hm tHitMap[K]) hit(aKey K) {
+	if counter, ok := hm[aKey]; ok {
+		counter.Add(1)
+	}
+} // hit()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` constructor:
+
+// `NewWithHitStats()` creates and initialises a new partitioned map
+// instance counting how often each key is read.
+//
+// The counters are incremented by `Get()` (but not by other methods
+// like `GetMany()`, `Has()`, or `ForEach()`) and can be queried by
+// `HitCount()` and `TopKeys()`. See the notes at the top of this file
+// about the additional memory used.
+// Other than that the map behaves like one created by `New()`.
+//
+// Example usage:
+//
+//	cache := NewWithHitStats[string, []byte]()
+//	// ...
+//	fmt.Println("hottest keys:", cache.TopKeys(10))
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewWithHitStats[K cmp.Ordered, V any]() *TPartitionMap[K, V] {
+	result := New[K, V]()
+	result.hitStats = true
+
+	return result
+} // NewWithHitStats()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `HitCount()` returns how often the given key was read by `Get()`
+// since it was stored.
+//
+// For a key not present or a map not created by `NewWithHitStats()`
+// the result is `0`.
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//
+// Returns:
+//   - `uint64`: The key's number of reads.
+func (pm *TPartitionMap[K, V]) HitCount(aKey K) (rCount uint64) {
+	if nil == pm {
+		return
+	}
+
+	aKey = pm.normKey(aKey)
+	if p, ok := pm.partition(aKey, false); ok {
+		p.RLock()
+		rCount = p.hits.count(aKey)
+		p.RUnlock()
+	}
+
+	return
+} // HitCount()
+
+// `TopKeys()` returns the given number of most often read keys.
+//
+// The keys are sorted by their number of reads in descending order;
+// keys read equally often are sorted in ascending order. If the map
+// holds fewer keys, all of them are returned. For a map not created
+// by `NewWithHitStats()` the result is empty.
+//
+// Parameters:
+//   - `aCount`: The max. number of keys to return.
+//
+// Returns:
+//   - `[]K`: The most often read keys.
+func (pm *TPartitionMap[K, V]) TopKeys(aCount int) []K {
+	if (nil == pm) || (0 >= aCount) {
+		return nil
+	}
+
+	var counts []tHitCount[K]
+	for _, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		p.RLock()
+		for k, counter := range p.hits {
+			counts = append(counts, tHitCount[K]{k, counter.Load()})
+		}
+		p.RUnlock()
+	}
+
+	slices.SortFunc(counts, func(a, b tHitCount[K]) int {
+		if c := cmp.Compare(b.hits, a.hits); 0 != c {
+			return c
+		}
+		return cmp.Compare(a.key, b.key)
+	})
+
+	result := make([]K, 0, min(aCount, len(counts)))
+	for _, hc := range counts[:cap(result)] {
+		result = append(result, hc.key)
+	}
+
+	return result
+} // TopKeys()
+
+/* _EoF_ */
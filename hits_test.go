@@ -0,0 +1,119 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TPartitionMap_HitCount(t *testing.T) {
+	pm := NewWithHitStats[string, int]().Put("a", 1).Put("b", 2)
+	for range 3 {
+		pm.Get("a")
+	}
+	pm.Get("b")
+	pm.Get("missing")
+	pm.Put("a", 11) // updates keep the counter
+
+	tests := []struct {
+		key  string
+		want uint64
+	}{
+		{"a", 3},
+		{"b", 1},
+		{"missing", 0},
+	}
+	for _, tc := range tests {
+		if got := pm.HitCount(tc.key); tc.want != got {
+			t.Errorf("HitCount(%q) = %d, want %d", tc.key, got, tc.want)
+		}
+	}
+
+	// Deleted keys start over.
+	pm.Delete("a")
+	pm.Put("a", 111)
+	if got := pm.HitCount("a"); 0 != got {
+		t.Errorf("HitCount(a) after Delete() = %d, want 0", got)
+	}
+
+	// Maps without hit statistics don't count.
+	plain := New[string, int]().Put("a", 1)
+	plain.Get("a")
+	if got := plain.HitCount("a"); 0 != got {
+		t.Errorf("HitCount() without statistics = %d, want 0", got)
+	}
+
+	var npm *TPartitionMap[string, int]
+	if got := npm.HitCount("a"); 0 != got {
+		t.Errorf("HitCount() on nil map = %d, want 0", got)
+	}
+} // Test_TPartitionMap_HitCount()
+
+func Test_TPartitionMap_HitCount_Concurrent(t *testing.T) {
+	const (
+		numGoroutines = 1 << 6
+		numReads      = 1 << 10
+	)
+
+	pm := NewWithHitStats[string, int]().Put("hot", 1)
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			for range numReads {
+				pm.Get("hot")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := pm.HitCount("hot"); numGoroutines*numReads != got {
+		t.Errorf("HitCount() = %d, want %d", got, numGoroutines*numReads)
+	}
+} // Test_TPartitionMap_HitCount_Concurrent()
+
+func Test_TPartitionMap_TopKeys(t *testing.T) {
+	pm := NewWithHitStats[string, int]()
+	reads := map[string]int{"a": 5, "b": 1, "c": 3, "d": 0, "e": 3, "f": 8}
+	for key, n := range reads {
+		pm.Put(key, n)
+		for range n {
+			pm.Get(key)
+		}
+	}
+
+	tests := []struct {
+		count int
+		want  []string
+	}{
+		{0, nil},
+		{1, []string{"f"}},
+		{3, []string{"f", "a", "c"}},
+		{4, []string{"f", "a", "c", "e"}},
+		{10, []string{"f", "a", "c", "e", "b", "d"}},
+	}
+	for _, tc := range tests {
+		if got := pm.TopKeys(tc.count); !slices.Equal(got, tc.want) {
+			t.Errorf("TopKeys(%d) = %v, want %v", tc.count, got, tc.want)
+		}
+	}
+
+	if got := New[string, int]().Put("a", 1).TopKeys(3); 0 != len(got) {
+		t.Errorf("TopKeys() without statistics = %v, want none", got)
+	}
+	var npm *TPartitionMap[string, int]
+	if got := npm.TopKeys(3); nil != got {
+		t.Errorf("TopKeys() on nil map = %v, want nil", got)
+	}
+} // Test_TPartitionMap_TopKeys()
+
+/* _EoF_ */
@@ -0,0 +1,120 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides the change notification hooks of a partitioned
+// map. The hooks are called after a key/value pair was stored in or
+// removed from the map by one of the methods modifying single
+// entries, e.g. `Put()`, `PutAll()`, `Compute()`, `Delete()`,
+// `DeleteIf()`, `GetAndDelete()`, or `Pop()`.
+// Methods resetting the whole map (i.e. `Clear()` and `Drain()`)
+// don't call the delete hook for each removed pair.
+// Besides the hooks, all watchers of the map (see `Watch()`) are
+// notified of each modification.
+//
+// The hooks run synchronously on the goroutine modifying the map,
+// but outside of any partition lock, so they may safely access the
+// map themselves. Since other goroutines may modify the same key
+// meanwhile, a hook can't rely on the map still holding the reported
+// state when it's called.
+
+// `notifyClear()` advances the map's generation and notifies the
+// watchers (if any) that all pairs were removed.
+func (pm *TPartitionMap[K, V]) notifyClear() {
+	pm.generation.Add(1)
+	pm.broadcast(TEvent[K, V]{Kind: EventClear})
+} // notifyClear()
+
+// `notifyDelete()` advances the map's generation and calls the
+// delete hook (if any) for the given key.
+//
+// Parameters:
+//   - `aKey`: The key removed from the map.
+func (pm *TPartitionMap[K, V]) notifyDelete(aKey K) {
+	pm.generation.Add(1)
+	if hook := pm.onDelete.Load(); nil != hook {
+		(*hook)(aKey)
+	}
+	pm.broadcast(TEvent[K, V]{Kind: EventDelete, Key: aKey})
+} // notifyDelete()
+
+// `notifyPut()` advances the map's generation and calls the put
+// hook (if any) for the given pair.
+//
+// Parameters:
+//   - `aKey`: The key stored in the map.
+//   - `aValue`: The value stored with the key.
+func (pm *TPartitionMap[K, V]) notifyPut(aKey K, aValue V) {
+	pm.generation.Add(1)
+	if hook := pm.onPut.Load(); nil != hook {
+		(*hook)(aKey, aValue)
+	}
+	pm.broadcast(TEvent[K, V]{Kind: EventPut, Key: aKey, Value: aValue})
+} // notifyPut()
+
+// `OnDelete()` registers the function to call after a key/value
+// pair was removed from the partitioned map.
+//
+// The hook replaces a previously registered one; a `nil` hook
+// removes it. Hooks belong to the map instance, i.e. they are not
+// inherited by copies like those made by `Clone()`.
+//
+// Example usage:
+//
+//	cache.OnDelete(func(aKey string) {
+//		downstream.Invalidate(aKey)
+//	})
+//
+// Parameters:
+//   - `aHook`: The function to call with the removed key.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) OnDelete(aHook func(aKey K)) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	if nil == aHook {
+		pm.onDelete.Store(nil)
+	} else {
+		pm.onDelete.Store(&aHook)
+	}
+
+	return pm
+} // OnDelete()
+
+// `OnPut()` registers the function to call after a key/value pair
+// was stored in the partitioned map (whether as a new entry or as
+// an update of an existing one).
+//
+// The hook replaces a previously registered one; a `nil` hook
+// removes it. Hooks belong to the map instance, i.e. they are not
+// inherited by copies like those made by `Clone()`.
+//
+// Parameters:
+//   - `aHook`: The function to call with the stored key/value pair.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) OnPut(aHook func(aKey K, aValue V)) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	if nil == aHook {
+		pm.onPut.Store(nil)
+	} else {
+		pm.onPut.Store(&aHook)
+	}
+
+	return pm
+} // OnPut()
+
+/* _EoF_ */
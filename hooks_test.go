@@ -0,0 +1,108 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TPartitionMap_OnPut(t *testing.T) {
+	type tEvent struct {
+		key   string
+		value int
+	}
+	var got []tEvent
+
+	pm := New[string, int]().OnPut(func(aKey string, aValue int) {
+		got = append(got, tEvent{aKey, aValue})
+	})
+
+	pm.Put("one", 1)
+	pm.Put("one", 11) // update must fire as well
+	pm.PutIfAbsent("one", 111)
+	pm.PutIfAbsent("two", 2)
+	pm.Compute("three", func(aOld int, aFound bool) (int, bool) {
+		return 3, false
+	})
+
+	want := []tEvent{{"one", 1}, {"one", 11}, {"two", 2}, {"three", 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OnPut() events = %v, want %v", got, want)
+	}
+
+	// Removing the hook stops notifications.
+	got = nil
+	pm.OnPut(nil).Put("four", 4)
+	if 0 != len(got) {
+		t.Errorf("OnPut(nil) events = %v, want none", got)
+	}
+	if v, ok := pm.Get("four"); !ok || 4 != v {
+		t.Errorf("Get() = (%d, %v), want (4, true)", v, ok)
+	}
+
+	var npm *TPartitionMap[string, int]
+	if nil != npm.OnPut(func(string, int) {}) {
+		t.Error("OnPut() on nil map should return nil")
+	}
+} // Test_TPartitionMap_OnPut()
+
+func Test_TPartitionMap_OnDelete(t *testing.T) {
+	var got []string
+
+	pm := New[string, int]().
+		PutAll(map[string]int{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}).
+		OnDelete(func(aKey string) {
+			got = append(got, aKey)
+		})
+
+	pm.Delete("a")
+	pm.Delete("missing") // no-op deletes must not fire
+	pm.GetAndDelete("b")
+	pm.DeleteAll([]string{"c", "missing"})
+	pm.Compute("d", func(aOld int, aFound bool) (int, bool) {
+		return 0, true
+	})
+	pm.DeleteIf(func(aKey string, aValue int) bool {
+		return 5 == aValue
+	})
+
+	want := []string{"a", "b", "c", "d", "e"}
+	if !slices.Equal(got, want) {
+		t.Errorf("OnDelete() events = %v, want %v", got, want)
+	}
+
+	var npm *TPartitionMap[string, int]
+	if nil != npm.OnDelete(func(string) {}) {
+		t.Error("OnDelete() on nil map should return nil")
+	}
+} // Test_TPartitionMap_OnDelete()
+
+func Test_TPartitionMap_OnPut_Reentrant(t *testing.T) {
+	pm := New[string, int]()
+	pm.OnPut(func(aKey string, aValue int) {
+		// Hooks run outside the partition lock, so accessing the
+		// map from within a hook must not deadlock.
+		if v, ok := pm.Get(aKey); !ok || v != aValue {
+			t.Errorf("Get() in hook = (%d, %v), want (%d, true)",
+				v, ok, aValue)
+		}
+		if "mirror" != aKey {
+			pm.Put("mirror", aValue)
+		}
+	})
+
+	pm.Put("key", 42)
+	if v, ok := pm.Get("mirror"); !ok || 42 != v {
+		t.Errorf("Get(mirror) = (%d, %v), want (42, true)", v, ok)
+	}
+} // Test_TPartitionMap_OnPut_Reentrant()
+
+/* _EoF_ */
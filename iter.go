@@ -0,0 +1,415 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// The number of key/value pairs after which `ForEachContext()`
+	// checks its context within a partition.
+	ctxCheckInterval = 1 << 8
+)
+
+// `All()` returns an iterator over all key/value pairs in the
+// partitioned map.
+//
+// Each partition is snapshotted under its read lock before its
+// key/value pairs are yielded, so no lock is held while the body
+// of the caller's loop is executed. The iteration stops as soon as
+// the loop is left (e.g. by `break` or `return`).
+//
+// The order in which the key/value pairs are yielded is unspecified.
+//
+// Example usage:
+//
+//	for key, value := range pm.All() {
+//		fmt.Println(key, value)
+//	}
+//
+// Returns:
+//   - `iter.Seq2[K, V]`: An iterator over all key/value pairs.
+func (pm *TPartitionMap[K, V]) All() iter.Seq2[K, V] {
+	return func(aYield func(K, V) bool) {
+		if nil == pm {
+			return
+		}
+
+		for _, p := range pm.partitions() {
+			if nil == p {
+				continue
+			}
+			for k, v := range p.clone() {
+				if !aYield(k, v) {
+					return
+				}
+			}
+		}
+	}
+} // All()
+
+// `ForEachChunk()` executes the provided function for chunks of up
+// to the given number of key/value pairs of the partitioned map.
+//
+// This allows e.g. for writing the pairs to a database in batches
+// without copying the whole map first. The partitions are copied one
+// after the other (each under its read lock) and their pairs are
+// gathered into chunks; a chunk is passed to the function as soon as
+// it's full, and a final partial chunk (if any) at the end. The
+// function is called without holding any locks and owns the chunk
+// passed, i.e. it may keep or modify it.
+//
+// A size less than `1` is treated as `1`. The assignment of the pairs
+// to the chunks is unspecified.
+//
+// Example usage:
+//
+//	pm.ForEachChunk(500, func(aChunk map[string]*TRecord) {
+//		db.InsertBatch(aChunk)
+//	})
+//
+// Parameters:
+//   - `aSize`: The max. number of pairs per chunk.
+//   - `aFunc`: The function to execute for each chunk.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) ForEachChunk(aSize int, aFunc func(aChunk map[K]V)) *TPartitionMap[K, V] {
+	if (nil == pm) || (nil == aFunc) {
+		return pm
+	}
+	aSize = max(aSize, 1)
+
+	chunk := make(map[K]V, aSize)
+	for _, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		for k, v := range p.clone() {
+			chunk[k] = v
+			if aSize == len(chunk) {
+				aFunc(chunk)
+				chunk = make(map[K]V, aSize)
+			}
+		}
+	}
+	if 0 < len(chunk) {
+		aFunc(chunk)
+	}
+
+	return pm
+} // ForEachChunk()
+
+// `ForEachContext()` executes the provided function for each key/value
+// pair in the partitioned map as long as the given context is not
+// cancelled and the function doesn't return an error.
+//
+// The context is checked before each partition and periodically while
+// processing a partition's snapshot, so long-running iterations (e.g.
+// in a request handler) can be aborted when the context is cancelled
+// or its deadline expires.
+//
+// The order in which the key/value pairs are visited is unspecified.
+//
+// Parameters:
+//   - `aCtx`: The context controlling the iteration.
+//   - `aFunc`: The function to execute for each key/value pair.
+//
+// Returns:
+//   - `error`: The context's error if it was cancelled, the first error returned by `aFunc`, or `nil`.
+func (pm *TPartitionMap[K, V]) ForEachContext(aCtx context.Context, aFunc func(aKey K, aValue V) error) error {
+	if err := aCtx.Err(); nil != err {
+		return err
+	}
+	if (nil == pm) || (nil == aFunc) {
+		return nil
+	}
+
+	for _, p := range pm.partitions() {
+		if err := aCtx.Err(); nil != err {
+			return err
+		}
+		if nil == p {
+			continue
+		}
+
+		count := 0
+		for k, v := range p.clone() {
+			if count++; 0 == count%ctxCheckInterval {
+				if err := aCtx.Err(); nil != err {
+					return err
+				}
+			}
+			if err := aFunc(k, v); nil != err {
+				return err
+			}
+		}
+	}
+
+	return nil
+} // ForEachContext()
+
+// `ForEachParallel()` executes the provided function for each key/value
+// pair in the partitioned map, processing several partitions in
+// parallel.
+//
+// The non-empty partitions are handed to a pool of worker goroutines
+// (at most `runtime.GOMAXPROCS(0)`), each of which snapshots a partition
+// and calls the function for its key/value pairs. The method returns
+// after all partitions have been processed.
+//
+// NOTE: Since the function is called concurrently from several
+// goroutines, it must be safe for concurrent use.
+//
+// Parameters:
+//   - `aFunc`: The function to execute for each key/value pair.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) ForEachParallel(aFunc func(aKey K, aValue V)) *TPartitionMap[K, V] {
+	if (nil == pm) || (nil == aFunc) {
+		return pm
+	}
+
+	list := pm.partitions()
+	work := make(chan *tPartition[K, V], len(list))
+	for _, p := range list {
+		if 0 < p.len() {
+			work <- p
+		}
+	}
+	close(work)
+
+	workers := min(runtime.GOMAXPROCS(0), len(work))
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				p.forEach(aFunc)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return pm
+} // ForEachParallel()
+
+// `ForEachPartition()` executes the provided function for each
+// partition of the partitioned map.
+//
+// Each partition is snapshotted under its read lock and the function
+// is called with the partition's index and copies of its keys and
+// values, i.e. without holding any locks. `keys[i]` is associated
+// with `values[i]`; the order of the pairs is unspecified.
+// Partitions without any key/value pairs are skipped.
+//
+// This allows e.g. for processing each partition in a goroutine of
+// its own or for inspecting the distribution of the keys in more
+// detail than `PartitionStats()` does.
+//
+// Example usage:
+//
+//	pm.ForEachPartition(func(aIdx int, aKeys []string, aValues []int) {
+//		fmt.Printf("partition %d: %d keys\n", aIdx, len(aKeys))
+//	})
+//
+// Parameters:
+//   - `aFunc`: The function to execute for each non-empty partition.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) ForEachPartition(aFunc func(aIdx int, aKeys []K, aValues []V)) *TPartitionMap[K, V] {
+	if (nil == pm) || (nil == aFunc) {
+		return pm
+	}
+
+	for idx, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		kv := p.clone()
+		if 0 == len(kv) {
+			continue
+		}
+
+		keys := make([]K, 0, len(kv))
+		values := make([]V, 0, len(kv))
+		for k, v := range kv {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		aFunc(idx, keys, values)
+	}
+
+	return pm
+} // ForEachPartition()
+
+// `ForEachSafe()` executes the provided function for each key/value
+// pair in the partitioned map, recovering from panics raised by the
+// function.
+//
+// Like `ForEach()` this method calls the function on a snapshot of
+// each partition, i.e. without holding any locks. If the function
+// panics, the panic is recovered and the iteration *continues* with
+// the next key/value pair, so a single faulty entry doesn't prevent
+// the others from being processed. The first panic is returned as an
+// error naming the key concerned; any further panics are discarded.
+//
+// This allows for running user-supplied callbacks without risking to
+// crash the whole program.
+//
+// The order in which the key/value pairs are visited is unspecified.
+//
+// Example usage:
+//
+//	if err := pm.ForEachSafe(plugin.Visit); nil != err {
+//		log.Println(err)
+//	}
+//
+// Parameters:
+//   - `aFunc`: The function to execute for each key/value pair.
+//
+// Returns:
+//   - `error`: The first panic recovered, or `nil`.
+func (pm *TPartitionMap[K, V]) ForEachSafe(aFunc func(aKey K, aValue V)) (rRecovered error) {
+	if (nil == pm) || (nil == aFunc) {
+		return
+	}
+
+	call := func(aKey K, aValue V) {
+		defer func() {
+			if r := recover(); (nil != r) && (nil == rRecovered) {
+				rRecovered = fmt.Errorf("partitionmap: panic at key %v: %v", aKey, r)
+			}
+		}()
+		aFunc(aKey, aValue)
+	}
+
+	for k, v := range pm.All() {
+		call(k, v)
+	}
+
+	return
+} // ForEachSafe()
+
+// `ForEachWhile()` executes the provided function for each key/value
+// pair in the partitioned map until the function returns `false`.
+//
+// Like `ForEach()` this method calls the function on a snapshot of
+// each partition, i.e. without holding any locks. Once the function
+// returns `false`, neither the current partition's remaining pairs
+// nor any other partitions are visited.
+//
+// The order in which the key/value pairs are visited is unspecified.
+//
+// Parameters:
+//   - `aFunc`: The function to execute for each key/value pair.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) ForEachWhile(aFunc func(aKey K, aValue V) bool) *TPartitionMap[K, V] {
+	if (nil == pm) || (nil == aFunc) {
+		return pm
+	}
+
+	for k, v := range pm.All() {
+		if !aFunc(k, v) {
+			break
+		}
+	}
+
+	return pm
+} // ForEachWhile()
+
+// `KeysSeq()` returns an iterator over all keys in the partitioned map.
+//
+// Other than `Keys()` this method doesn't collect and sort all keys
+// in advance but yields them partition by partition. Hence the order
+// of the keys is unspecified. Callers requiring sorted keys should
+// use `Keys()` instead.
+//
+// Returns:
+//   - `iter.Seq[K]`: An iterator over all keys.
+func (pm *TPartitionMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(aYield func(K) bool) {
+		for k := range pm.All() {
+			if !aYield(k) {
+				return
+			}
+		}
+	}
+} // KeysSeq()
+
+// `ValuesSeq()` returns an iterator over all values in the
+// partitioned map.
+//
+// Other than `Values()` this method doesn't collect all values in
+// advance but yields them partition by partition. Hence the order
+// of the values is unspecified.
+//
+// Returns:
+//   - `iter.Seq[V]`: An iterator over all values.
+func (pm *TPartitionMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(aYield func(V) bool) {
+		for _, v := range pm.All() {
+			if !aYield(v) {
+				return
+			}
+		}
+	}
+} // ValuesSeq()
+
+// `Walk()` executes the provided function for each key/value pair
+// in the partitioned map, passing the index of the pair's partition
+// along.
+//
+// This allows e.g. for correlating the application's keys with their
+// placement when debugging the distribution of the keys. Like
+// `ForEach()` this method calls the function on a snapshot of each
+// partition, i.e. without holding any locks.
+//
+// The partitions are visited in ascending order of their indices;
+// the order of the pairs within a partition is unspecified.
+//
+// Example usage:
+//
+//	pm.Walk(func(aIdx int, aKey string, aValue int) {
+//		fmt.Printf("%3d: %s\n", aIdx, aKey)
+//	})
+//
+// Parameters:
+//   - `aFunc`: The function to execute for each key/value pair.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Walk(aFunc func(aIdx int, aKey K, aValue V)) *TPartitionMap[K, V] {
+	if (nil == pm) || (nil == aFunc) {
+		return pm
+	}
+
+	for idx, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		for k, v := range p.clone() {
+			aFunc(idx, k, v)
+		}
+	}
+
+	return pm
+} // Walk()
+
+/* _EoF_ */
@@ -0,0 +1,689 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TPartitionMap_All(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want map[string]int
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: map[string]int{},
+		},
+		{
+			name: "Partition map with values",
+			pm: New[string, int]().
+				Put("key1", 100).
+				Put("key2", 200).
+				Put("key3", 300),
+			want: map[string]int{"key1": 100, "key2": 200, "key3": 300},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: map[string]int{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := make(map[string]int)
+			for k, v := range tc.pm.All() {
+				if _, ok := got[k]; ok {
+					t.Errorf("All() yielded key %q twice", k)
+				}
+				got[k] = v
+			}
+
+			if len(got) != len(tc.want) {
+				t.Errorf("All() yielded %d pairs, want %d",
+					len(got), len(tc.want))
+			}
+			for k, v := range tc.want {
+				if gv, ok := got[k]; !ok || (gv != v) {
+					t.Errorf("All() key %q = %v (%v), want %v",
+						k, gv, ok, v)
+				}
+			}
+		})
+	}
+} // Test_TPartitionMap_All()
+
+func Test_TPartitionMap_All_Break(t *testing.T) {
+	pm := New[int, int]()
+	for i := range 1000 {
+		pm.Put(i, i)
+	}
+
+	count := 0
+	for range pm.All() {
+		count++
+		if 10 == count {
+			break
+		}
+	}
+
+	if 10 != count {
+		t.Errorf("All() loop ran %d times after break, want %d",
+			count, 10)
+	}
+} // Test_TPartitionMap_All_Break()
+
+func Test_TPartitionMap_All_PutInLoop(t *testing.T) {
+	// Modifying the map inside the loop must not deadlock.
+	pm := New[int, int]().Put(1, 1)
+
+	for k, v := range pm.All() {
+		if 1000 > k {
+			pm.Put(k+1000, v)
+		}
+	}
+
+	if 2 != pm.Len() {
+		t.Errorf("Len() = %d, want %d", pm.Len(), 2)
+	}
+} // Test_TPartitionMap_All_PutInLoop()
+
+func Test_TPartitionMap_ForEachChunk(t *testing.T) {
+	const numKeys = 1000
+
+	pm := New[string, int]()
+	for i := range numKeys {
+		pm.Put(strconv.Itoa(i), i)
+	}
+
+	tests := []struct {
+		name       string
+		pm         *TPartitionMap[string, int]
+		size       int
+		wantChunks int
+	}{
+		{"Even chunks", pm, 100, 10},
+		{"Partial last chunk", pm, 300, 4},
+		{"Single chunk", pm, numKeys, 1},
+		{"Oversized chunk", pm, 5 * numKeys, 1},
+		{"Zero size", pm, 0, numKeys},
+		{"Negative size", pm, -3, numKeys},
+		{"Empty map", New[string, int](), 10, 0},
+		{"Nil map", nil, 10, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			size := max(tc.size, 1)
+			union := make(map[string]int)
+			var sizes []int
+			if got := tc.pm.ForEachChunk(tc.size, func(aChunk map[string]int) {
+				sizes = append(sizes, len(aChunk))
+				for k, v := range aChunk {
+					if _, dup := union[k]; dup {
+						t.Errorf("ForEachChunk() passed key %q twice", k)
+					}
+					union[k] = v
+				}
+			}); got != tc.pm {
+				t.Error("ForEachChunk() didn't return the map itself")
+			}
+
+			if tc.wantChunks != len(sizes) {
+				t.Fatalf("ForEachChunk() called %d times, want %d",
+					len(sizes), tc.wantChunks)
+			}
+			for idx, n := range sizes {
+				if last := len(sizes) - 1; (idx < last) && (size != n) {
+					t.Errorf("ForEachChunk() chunk %d has %d pairs, want %d", idx, n, size)
+				} else if (idx == last) && ((0 == n) || (size < n)) {
+					t.Errorf("ForEachChunk() last chunk has %d pairs", n)
+				}
+			}
+			if want := tc.pm.ToMap(); !reflect.DeepEqual(union, want) && (0 < len(want)) {
+				t.Errorf("ForEachChunk() chunks hold %d pairs, want %d", len(union), len(want))
+			}
+		})
+	}
+} // Test_TPartitionMap_ForEachChunk()
+
+func Test_TPartitionMap_ForEachContext(t *testing.T) {
+	errStop := errors.New("stop")
+	newMap := func() *TPartitionMap[int, int] {
+		pm := New[int, int]()
+		for i := range 1000 {
+			pm.Put(i, i)
+		}
+		return pm
+	}
+
+	tests := []struct {
+		name      string
+		pm        *TPartitionMap[int, int]
+		errAfter  int
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "Visit all pairs",
+			pm:        newMap(),
+			errAfter:  -1,
+			wantCalls: 1000,
+			wantErr:   nil,
+		},
+		{
+			name:      "Function returns error",
+			pm:        newMap(),
+			errAfter:  10,
+			wantCalls: 10,
+			wantErr:   errStop,
+		},
+		{
+			name:      "Nil partition map",
+			pm:        nil,
+			errAfter:  -1,
+			wantCalls: 0,
+			wantErr:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			err := tc.pm.ForEachContext(context.Background(), func(aKey, aValue int) error {
+				if calls++; calls == tc.errAfter {
+					return errStop
+				}
+				return nil
+			})
+
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("ForEachContext() error = %v, want %v",
+					err, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("ForEachContext() called function %d times, want %d",
+					calls, tc.wantCalls)
+			}
+		})
+	}
+} // Test_TPartitionMap_ForEachContext()
+
+func Test_TPartitionMap_ForEachContext_Cancel(t *testing.T) {
+	pm := New[int, int]()
+	for i := range 10000 {
+		pm.Put(i, i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	err := pm.ForEachContext(ctx, func(aKey, aValue int) error {
+		if calls++; 100 == calls {
+			cancel()
+		}
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ForEachContext() error = %v, want %v",
+			err, context.Canceled)
+	}
+	if calls >= pm.Len() {
+		t.Errorf("ForEachContext() didn't stop after cancellation: %d calls",
+			calls)
+	}
+
+	// An already cancelled context doesn't call the function at all.
+	calls = 0
+	if err = pm.ForEachContext(ctx, func(aKey, aValue int) error {
+		calls++
+		return nil
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ForEachContext() error = %v, want %v",
+			err, context.Canceled)
+	}
+	if 0 != calls {
+		t.Errorf("ForEachContext() called function %d times, want %d",
+			calls, 0)
+	}
+} // Test_TPartitionMap_ForEachContext_Cancel()
+
+func Test_TPartitionMap_ForEachParallel(t *testing.T) {
+	tests := []struct {
+		name    string
+		pm      *TPartitionMap[int, int]
+		numKeys int
+	}{
+		{
+			name:    "Empty partition map",
+			pm:      New[int, int](),
+			numKeys: 0,
+		},
+		{
+			name:    "Single entry",
+			pm:      New[int, int](),
+			numKeys: 1,
+		},
+		{
+			name:    "Many entries",
+			pm:      New[int, int](),
+			numKeys: 10000,
+		},
+		{
+			name:    "Nil partition map",
+			pm:      nil,
+			numKeys: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := range tc.numKeys {
+				tc.pm.Put(i, i)
+			}
+
+			var (
+				calls   atomic.Int64
+				mtx     sync.Mutex
+				visited = make(map[int]int, tc.numKeys)
+			)
+			got := tc.pm.ForEachParallel(func(aKey, aValue int) {
+				calls.Add(1)
+				mtx.Lock()
+				visited[aKey] = aValue
+				mtx.Unlock()
+			})
+
+			if got != tc.pm {
+				t.Errorf("ForEachParallel() returned different instance")
+			}
+			if int64(tc.numKeys) != calls.Load() {
+				t.Errorf("ForEachParallel() called function %d times, want %d",
+					calls.Load(), tc.numKeys)
+			}
+			if len(visited) != tc.numKeys {
+				t.Errorf("ForEachParallel() visited %d keys, want %d",
+					len(visited), tc.numKeys)
+			}
+			for k, v := range visited {
+				if k != v {
+					t.Errorf("ForEachParallel() key %d has value %d", k, v)
+				}
+			}
+		})
+	}
+} // Test_TPartitionMap_ForEachParallel()
+
+func Test_TPartitionMap_ForEachPartition(t *testing.T) {
+	const numKeys = 10000
+
+	pm := New[int, int]()
+	for i := range numKeys {
+		pm.Put(i, -i)
+	}
+
+	seen := make(map[int]int, numKeys)
+	parts := 0
+	got := pm.ForEachPartition(func(aIdx int, aKeys []int, aValues []int) {
+		parts++
+		if 0 == len(aKeys) {
+			t.Errorf("ForEachPartition() reported empty partition %d", aIdx)
+		}
+		if len(aKeys) != len(aValues) {
+			t.Fatalf("partition %d: %d keys but %d values",
+				aIdx, len(aKeys), len(aValues))
+		}
+		for i, key := range aKeys {
+			if prev, ok := seen[key]; ok {
+				t.Errorf("key %d reported by partitions %d and %d",
+					key, prev, aIdx)
+			}
+			seen[key] = aIdx
+			if pm.index(key) != aIdx {
+				t.Errorf("key %d reported by partition %d, want %d",
+					key, aIdx, pm.index(key))
+			}
+			if -key != aValues[i] {
+				t.Errorf("key %d paired with value %d, want %d",
+					key, aValues[i], -key)
+			}
+		}
+	})
+
+	if got != pm {
+		t.Errorf("ForEachPartition() returned different instance")
+	}
+	if numKeys != len(seen) {
+		t.Errorf("ForEachPartition() reported %d keys, want %d",
+			len(seen), numKeys)
+	}
+	if want := pm.PartitionStats().Parts; want != parts {
+		t.Errorf("ForEachPartition() called function %d times, want %d",
+			parts, want)
+	}
+
+	// Empty partitions are skipped.
+	pm.Clear().ForEachPartition(func(aIdx int, aKeys []int, aValues []int) {
+		t.Errorf("ForEachPartition() reported partition %d of empty map", aIdx)
+	})
+
+	var npm *TPartitionMap[int, int]
+	if nil != npm.ForEachPartition(func(int, []int, []int) {}) {
+		t.Error("ForEachPartition() on nil map should return nil")
+	}
+} // Test_TPartitionMap_ForEachPartition()
+
+func Benchmark_TPartitionMap_ForEachParallel(b *testing.B) {
+	pm := New[int, string]()
+	for i := range 1 << 14 {
+		pm.Put(i, strconv.Itoa(i))
+	}
+
+	// Some noticeable work per entry:
+	work := func(aKey int, aValue string) {
+		sum := sha256.Sum256([]byte(aValue))
+		for range 16 {
+			sum = sha256.Sum256(sum[:])
+		}
+	}
+
+	b.Run("ForEach", func(b *testing.B) {
+		for range b.N {
+			pm.ForEach(work)
+		}
+	})
+	b.Run("ForEachParallel", func(b *testing.B) {
+		for range b.N {
+			pm.ForEachParallel(work)
+		}
+	})
+} // Benchmark_TPartitionMap_ForEachParallel()
+
+func Test_TPartitionMap_ForEachSafe(t *testing.T) {
+	pm := New[int, int]()
+	for i := range 1000 {
+		pm.Put(i, i)
+	}
+
+	var visited []int
+	err := pm.ForEachSafe(func(aKey, aValue int) {
+		switch aKey {
+		case 13:
+			panic("unlucky number")
+		case 666:
+			panic(errors.New("evil number"))
+		}
+		visited = append(visited, aKey)
+	})
+
+	if nil == err {
+		t.Fatal("ForEachSafe() should report the recovered panic")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "key 13: unlucky number") &&
+		!strings.Contains(msg, "key 666: evil number") {
+		t.Errorf("ForEachSafe() error = %q, want it to name the panicking key", msg)
+	}
+	if 998 != len(visited) {
+		t.Errorf("ForEachSafe() visited %d pairs, want 998", len(visited))
+	}
+	if slices.Contains(visited, 13) || slices.Contains(visited, 666) {
+		t.Error("ForEachSafe() visited the panicking keys")
+	}
+
+	if err := pm.ForEachSafe(func(int, int) {}); nil != err {
+		t.Errorf("ForEachSafe() without panics = %v, want nil", err)
+	}
+
+	var npm *TPartitionMap[int, int]
+	if err := npm.ForEachSafe(func(int, int) { panic("called") }); nil != err {
+		t.Errorf("ForEachSafe() on nil map = %v, want nil", err)
+	}
+} // Test_TPartitionMap_ForEachSafe()
+
+func Test_TPartitionMap_ForEachWhile(t *testing.T) {
+	newMap := func() *TPartitionMap[int, int] {
+		pm := New[int, int]()
+		for i := range 1000 {
+			pm.Put(i, i)
+		}
+		return pm
+	}
+
+	tests := []struct {
+		name      string
+		pm        *TPartitionMap[int, int]
+		stopAfter int
+		wantCalls int
+	}{
+		{
+			name:      "Stop after first pair",
+			pm:        newMap(),
+			stopAfter: 1,
+			wantCalls: 1,
+		},
+		{
+			name:      "Stop after some pairs",
+			pm:        newMap(),
+			stopAfter: 200,
+			wantCalls: 200,
+		},
+		{
+			name:      "Visit all pairs",
+			pm:        newMap(),
+			stopAfter: -1,
+			wantCalls: 1000,
+		},
+		{
+			name:      "Empty partition map",
+			pm:        New[int, int](),
+			stopAfter: 1,
+			wantCalls: 0,
+		},
+		{
+			name:      "Nil partition map",
+			pm:        nil,
+			stopAfter: 1,
+			wantCalls: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			got := tc.pm.ForEachWhile(func(aKey, aValue int) bool {
+				calls++
+				return calls != tc.stopAfter
+			})
+
+			if got != tc.pm {
+				t.Errorf("ForEachWhile() returned different instance")
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("ForEachWhile() called function %d times, want %d",
+					calls, tc.wantCalls)
+			}
+		})
+	}
+} // Test_TPartitionMap_ForEachWhile()
+
+func Test_TPartitionMap_KeysSeq(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []string
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: nil,
+		},
+		{
+			name: "Partition map with keys",
+			pm: New[string, int]().
+				Put("key1", 100).
+				Put("key2", 200).
+				Put("key3", 300),
+			want: []string{"key1", "key2", "key3"},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := slices.Sorted(tc.pm.KeysSeq())
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("KeysSeq() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_KeysSeq()
+
+func Test_TPartitionMap_KeysSeq_Break(t *testing.T) {
+	pm := New[int, int]()
+	for i := range 1000 {
+		pm.Put(i, i)
+	}
+
+	count := 0
+	for range pm.KeysSeq() {
+		count++
+		if 5 == count {
+			break
+		}
+	}
+
+	if 5 != count {
+		t.Errorf("KeysSeq() loop ran %d times after break, want %d",
+			count, 5)
+	}
+} // Test_TPartitionMap_KeysSeq_Break()
+
+func Test_TPartitionMap_ValuesSeq(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []int
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: nil,
+		},
+		{
+			name: "Partition map with values",
+			pm: New[string, int]().
+				Put("key1", 100).
+				Put("key2", 200).
+				Put("key3", 300),
+			want: []int{100, 200, 300},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := slices.Sorted(tc.pm.ValuesSeq())
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("ValuesSeq() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_ValuesSeq()
+
+func Test_TPartitionMap_ValuesSeq_Break(t *testing.T) {
+	pm := New[int, int]()
+	for i := range 1000 {
+		pm.Put(i, i)
+	}
+
+	count := 0
+	for range pm.ValuesSeq() {
+		count++
+		if 5 == count {
+			break
+		}
+	}
+
+	if 5 != count {
+		t.Errorf("ValuesSeq() loop ran %d times after break, want %d",
+			count, 5)
+	}
+} // Test_TPartitionMap_ValuesSeq_Break()
+
+func Test_TPartitionMap_Walk(t *testing.T) {
+	const numKeys = 1000
+
+	pm := New[string, int]()
+	for i := range numKeys {
+		pm.Put("key"+strconv.Itoa(i), i)
+	}
+
+	calls, lastIdx := 0, -1
+	got := pm.Walk(func(aIdx int, aKey string, aValue int) {
+		calls++
+		if want := partitionIndex(aKey, numberOfPartitionsInMap); aIdx != want {
+			t.Errorf("Walk() reported partition %d for %q, want %d", aIdx, aKey, want)
+		}
+		if aIdx < lastIdx {
+			t.Errorf("Walk() visited partition %d after %d", aIdx, lastIdx)
+		}
+		lastIdx = aIdx
+		if "key"+strconv.Itoa(aValue) != aKey {
+			t.Errorf("Walk() passed (%q, %d)", aKey, aValue)
+		}
+	})
+	if got != pm {
+		t.Error("Walk() returned different instance")
+	}
+	if numKeys != calls {
+		t.Errorf("Walk() called function %d times, want %d", calls, numKeys)
+	}
+
+	// Custom hashing is reflected as well.
+	hpm := NewWithHasher[int, int](func(aKey int) uint64 { return uint64(aKey / 10) })
+	for i := range 100 {
+		hpm.Put(i, i)
+	}
+	hpm.Walk(func(aIdx, aKey, _ int) {
+		if aKey/10 != aIdx {
+			t.Errorf("Walk() reported partition %d for %d, want %d", aIdx, aKey, aKey/10)
+		}
+	})
+
+	var npm *TPartitionMap[string, int]
+	npm.Walk(func(int, string, int) {
+		t.Error("Walk() on nil map called the function")
+	})
+} // Test_TPartitionMap_Walk()
+
+/* _EoF_ */
@@ -0,0 +1,202 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"container/list"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides the size limitation of a partitioned map by
+// evicting the least recently used (LRU) entries.
+//
+// An exact LRU order across all partitions would require a global
+// list updated (and hence locked) by every `Get()` and `Put()`,
+// defeating the purpose of partitioning. Instead each partition
+// keeps its own recency list and is limited to an equal share of the
+// map's maximum size. That's an approximation: an entry is evicted
+// when its *partition* is full, even if other partitions still have
+// room, so it's not necessarily the least recently used one of the
+// whole map. In return the map never holds more than the given
+// maximum number of entries and the bookkeeping needs no locks but
+// the partitions' own. Note that `Get()` has to acquire the write
+// lock of the key's partition to update its recency list.
+//
+// Stored (e.g. by `Put()` or `Compute()`) and retrieved (by `Get()`)
+// entries count as used. The evictions call the delete hook (see
+// `OnDelete()`). The size limit is not inherited by copies like
+// those made by `Clone()`, `Snapshot()`, or `Filter()`.
+
+type (
+	// `tLRU` keeps the recency order of a partition's keys.
+	tLRU[K cmp.Ordered] struct {
+		order *list.List          // the keys, most recently used first
+		elems map[K]*list.Element // the keys' list elements
+		limit int                 // max. number of keys in the partition
+	}
+)
+
+// `newLRU()` creates a new recency list for a partition holding at
+// most the given number of keys.
+//
+// Parameters:
+//   - `aLimit`: The max. number of keys.
+//
+// Returns:
+//   - `*tLRU[K]`: A pointer to a newly created recency list.
+func newLRU[K cmp.Ordered](aLimit int) *tLRU[K] {
+	return &tLRU[K]{
+		order: list.New(),
+		elems: make(map[K]*list.Element),
+		limit: max(aLimit, 1),
+	}
+} // newLRU()
+
+// `oldest()` returns the keys exceeding the limit, starting with
+// the least recently used one.
+//
+// The keys are removed from the recency list.
+//
+// Returns:
+//   - `[]K`: The keys to evict.
+func (l *tLRU[K]) oldest() (rKeys []K) {
+	if nil == l {
+		return
+	}
+
+	for l.order.Len() > l.limit {
+		elem := l.order.Back()
+		key := l.order.Remove(elem).(K)
+		delete(l.elems, key)
+		rKeys = append(rKeys, key)
+	}
+
+	return
+} // oldest()
+
+// `remove()` removes the given key from the recency list.
+//
+// Parameters:
+//   - `aKey`: The key to remove.
+func (l *tLRU[K]) remove(aKey K) {
+	if nil == l {
+		return
+	}
+
+	if elem, ok := l.elems[aKey]; ok {
+		l.order.Remove(elem)
+		delete(l.elems, aKey)
+	}
+} // remove()
+
+// `reset()` removes all keys from the recency list.
+func (l *tLRU[K]) reset() {
+	if nil == l {
+		return
+	}
+
+	l.order.Init()
+	clear(l.elems)
+} // reset()
+
+// `use()` marks the given key as the most recently used one.
+//
+// Parameters:
+//   - `aKey`: The key to mark.
+func (l *tLRU[K]) use(aKey K) {
+	if nil == l {
+		return
+	}
+
+	if elem, ok := l.elems[aKey]; ok {
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.elems[aKey] = l.order.PushFront(aKey)
+} // use()
+
+// ---------------------------------------------------------------------------
+// `tPartition` methods:
+
+// `evict()` removes the least recently used key/value pairs from
+// the partition until it holds no more than its share of entries.
+//
+// Returns:
+//   - `[]K`: The keys of the removed key/value pairs.
+func (p *tPartition[K, V]) evict() (rKeys []K) {
+	if (nil == p) || (nil == p.lru) {
+		return
+	}
+
+	if !p.lock() {
+		return
+	}
+	rKeys = p.lru.oldest()
+	for _, key := range rKeys {
+		delete(p.kv, key)
+		p.forget(key)
+	}
+	p.Unlock()
+
+	return
+} // evict()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` constructor:
+
+// `NewLRU()` creates and initialises a new partitioned map instance
+// holding at most the given number of entries.
+//
+// When storing a new entry would exceed the limit, the least recently
+// used entry of the new one's partition is evicted (see the notes
+// at the top of this file about the approximation involved).
+// To keep the partitions' shares meaningful, a small limit results
+// in fewer partitions (e.g. a limit of `8` in eight partitions
+// holding one entry each).
+// If `aMaxEntries` isn't positive the map behaves like one created
+// by `New()`.
+//
+// Example usage:
+//
+//	thumbnails := NewLRU[string, []byte](10_000)
+//
+// Parameters:
+//   - `aMaxEntries`: The max. number of entries in the map.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewLRU[K cmp.Ordered, V any](aMaxEntries int) *TPartitionMap[K, V] {
+	if 0 >= aMaxEntries {
+		return New[K, V]()
+	}
+
+	count := min(aMaxEntries, numberOfPartitionsInMap)
+	result := NewWithPartitions[K, V](count)
+	result.layout.Store(newLayout[K, V](count, nil, aMaxEntries/count))
+
+	return result
+} // NewLRU()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `evict()` enforces the size limit of the given partition after an
+// entry was stored in it.
+//
+// The delete hook (see `OnDelete()`) is called for each evicted key.
+//
+// Parameters:
+//   - `aPartition`: The partition to check.
+func (pm *TPartitionMap[K, V]) evict(aPartition *tPartition[K, V]) {
+	for _, key := range aPartition.evict() {
+		pm.notifyDelete(key)
+	}
+} // evict()
+
+/* _EoF_ */
@@ -0,0 +1,184 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_tLRU(t *testing.T) {
+	l := newLRU[string](2)
+	l.use("a")
+	l.use("b")
+	l.use("a")
+	l.use("c")
+	if got, want := l.oldest(), []string{"b"}; !slices.Equal(got, want) {
+		t.Errorf("oldest() = %v, want %v", got, want)
+	}
+
+	l.remove("a")
+	l.use("d")
+	l.use("e")
+	if got, want := l.oldest(), []string{"c"}; !slices.Equal(got, want) {
+		t.Errorf("oldest() = %v, want %v", got, want)
+	}
+
+	l.reset()
+	if got := l.oldest(); 0 != len(got) {
+		t.Errorf("oldest() after reset() = %v, want none", got)
+	}
+
+	var nl *tLRU[string]
+	nl.use("a") // must not panic
+	nl.remove("a")
+	nl.reset()
+	if got := nl.oldest(); nil != got {
+		t.Errorf("oldest() on nil list = %v, want nil", got)
+	}
+} // Test_tLRU()
+
+func Test_TPartitionMap_NewLRU(t *testing.T) {
+	const maxEntries = 2 * numberOfPartitionsInMap
+
+	var evicted []int
+	// A modulo hasher assigns the integer keys to the partitions so
+	// that partition `n` holds the keys `n`, `n+128`, `n+256`, ...
+	pm := NewLRU[int, int](maxEntries).SetHasher(func(aKey int) uint64 {
+		return uint64(aKey) //#nosec G115
+	}).OnDelete(func(aKey int) {
+		evicted = append(evicted, aKey)
+	})
+	for key := range maxEntries {
+		pm.Put(key, key)
+	}
+	if got := pm.Len(); maxEntries != got {
+		t.Errorf("Len() = %d, want %d", got, maxEntries)
+	}
+
+	// Mark the keys of the first round as used ...
+	for key := range numberOfPartitionsInMap {
+		pm.Get(key)
+	}
+	// ... so the next round evicts those of the second one.
+	for key := maxEntries; key < maxEntries+numberOfPartitionsInMap; key++ {
+		pm.Put(key, key)
+	}
+
+	if got := pm.Len(); maxEntries != got {
+		t.Errorf("Len() after eviction = %d, want %d", got, maxEntries)
+	}
+	slices.Sort(evicted)
+	for idx, key := range evicted {
+		if want := numberOfPartitionsInMap + idx; want != key {
+			t.Fatalf("evicted[%d] = %d, want %d", idx, key, want)
+		}
+	}
+	if numberOfPartitionsInMap != len(evicted) {
+		t.Errorf("len(evicted) = %d, want %d",
+			len(evicted), numberOfPartitionsInMap)
+	}
+	for _, key := range []int{0, 127, 256, 383} {
+		if _, ok := pm.Get(key); !ok {
+			t.Errorf("Get(%d) = false, want true", key)
+		}
+	}
+	if _, ok := pm.Get(128); ok {
+		t.Error("Get(128) = true, want false")
+	}
+} // Test_TPartitionMap_NewLRU()
+
+func Test_TPartitionMap_NewLRU_Small(t *testing.T) {
+	pm := NewLRU[string, int](1)
+	pm.Put("a", 1).Put("b", 2)
+	if got, want := pm.Keys(), []string{"b"}; !slices.Equal(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+
+	// Deleted keys must not be evicted later.
+	pm.Delete("b")
+	pm.Put("c", 3)
+	if got, want := pm.Keys(), []string{"c"}; !slices.Equal(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+
+	ipm := NewLRU[int, int](0)
+	for key := range 1000 {
+		ipm.Put(key, key)
+	}
+	if got := ipm.Len(); 1000 != got {
+		t.Errorf("Len() of unlimited map = %d, want 1000", got)
+	}
+} // Test_TPartitionMap_NewLRU_Small()
+
+func Test_TPartitionMap_NewLRU_Resize_Concurrent(t *testing.T) {
+	const (
+		maxEntries = 1000
+		numWriters = 4
+		numKeys    = 5000
+	)
+
+	// Writes passed on from retired partitions must be evicted in
+	// the partitions they end up in.
+	pm := NewLRU[int, int](maxEntries)
+	var (
+		done atomic.Bool
+		wg   sync.WaitGroup
+	)
+	for g := range numWriters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; (numKeys > i) || !done.Load(); i++ {
+				key := g*numKeys + i%numKeys
+				if 0 == i%2 {
+					pm.LoadOrStore(key, i)
+				} else {
+					pm.PutIfAbsent(key, i)
+				}
+			}
+		}()
+	}
+	for range 4 {
+		for _, count := range []int{1, 7, 64, numberOfPartitionsInMap} {
+			pm.Resize(count)
+		}
+	}
+	done.Store(true)
+	wg.Wait()
+
+	// A partition retired meanwhile passes the writes on and reports
+	// the partition actually written, which is evicted instead.
+	stale, _ := pm.partition(0, true)
+	pm.Resize(64)
+	pairs := make([]TPair[int, int], 0, maxEntries)
+	for i := range maxEntries {
+		_, _, written := stale.loadOrStore(-1-i, i)
+		pm.evict(written)
+		pairs = append(pairs, TPair[int, int]{Key: -1 - maxEntries - i, Value: i})
+	}
+	if nil != stale.putAll(pairs) {
+		t.Error("putAll() on a retired partition returned it")
+	}
+
+	if got := pm.Len(); maxEntries < got {
+		t.Errorf("Len() = %d, want at most %d", got, maxEntries)
+	}
+	limit := pm.layout.Load().lruLimit
+	for idx, p := range pm.partitions() {
+		if got := p.len(); limit < got {
+			t.Errorf("partition %d: len() = %d, want at most %d", idx, got, limit)
+		}
+	}
+	recount(t, pm)
+} // Test_TPartitionMap_NewLRU_Resize_Concurrent()
+
+/* _EoF_ */
@@ -0,0 +1,90 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `mergeSorted()` merges the given lists, each sorted in ascending
+// order, into a single list sorted in ascending order.
+//
+// A k-way merge is used: a binary min-heap holds the (non-empty)
+// lists ordered by their respective first element. This takes
+// O(n log k) time for `n` elements in `k` lists instead of the
+// O(n log n) needed for sorting all elements anew.
+//
+// Parameters:
+//   - `aLists`: The sorted lists to merge.
+//
+// Returns:
+//   - `[]K`: The merged and sorted list.
+func mergeSorted[K cmp.Ordered](aLists [][]K) []K {
+	total := 0
+	heap := make([][]K, 0, len(aLists))
+	for _, list := range aLists {
+		if 0 < len(list) {
+			total += len(list)
+			heap = append(heap, list)
+		}
+	}
+
+	// Establish the heap property:
+	for idx := len(heap)/2 - 1; 0 <= idx; idx-- {
+		siftDown(heap, idx)
+	}
+
+	result := make([]K, 0, total)
+	for 0 < len(heap) {
+		head := heap[0]
+		result = append(result, head[0])
+		if 1 < len(head) {
+			heap[0] = head[1:]
+		} else {
+			last := len(heap) - 1
+			heap[0] = heap[last]
+			heap[last] = nil
+			heap = heap[:last]
+		}
+		siftDown(heap, 0)
+	}
+
+	return result
+} // mergeSorted()
+
+// `siftDown()` moves the list at the given heap index down until
+// the heap property is restored.
+//
+// The elements are compared by `cmp.Less()`, so (like `slices.Sort()`)
+// a floating point NaN sorts before all other values.
+//
+// Parameters:
+//   - `aHeap`: The heap of non-empty sorted lists.
+//   - `aIdx`: The index of the list to move down.
+func siftDown[K cmp.Ordered](aHeap [][]K, aIdx int) {
+	size := len(aHeap)
+	for {
+		smallest := aIdx
+		if left := 2*aIdx + 1; (left < size) &&
+			cmp.Less(aHeap[left][0], aHeap[smallest][0]) {
+			smallest = left
+		}
+		if right := 2*aIdx + 2; (right < size) &&
+			cmp.Less(aHeap[right][0], aHeap[smallest][0]) {
+			smallest = right
+		}
+		if smallest == aIdx {
+			return
+		}
+		aHeap[aIdx], aHeap[smallest] = aHeap[smallest], aHeap[aIdx]
+		aIdx = smallest
+	}
+} // siftDown()
+
+/* _EoF_ */
@@ -0,0 +1,120 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_mergeSorted(t *testing.T) {
+	tests := []struct {
+		name  string
+		lists [][]int
+		want  []int
+	}{
+		{
+			name:  "No lists",
+			lists: nil,
+			want:  []int{},
+		},
+		{
+			name:  "Empty lists",
+			lists: [][]int{{}, nil, {}},
+			want:  []int{},
+		},
+		{
+			name:  "Single list",
+			lists: [][]int{{1, 2, 3}},
+			want:  []int{1, 2, 3},
+		},
+		{
+			name:  "Interleaved lists",
+			lists: [][]int{{1, 4, 7}, {2, 5, 8}, {3, 6, 9}},
+			want:  []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:  "Uneven lists",
+			lists: [][]int{{10}, {}, {-5, 0, 5, 20, 30}, {6, 7}},
+			want:  []int{-5, 0, 5, 6, 7, 10, 20, 30},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := mergeSorted(tc.lists)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("mergeSorted() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+} // Test_mergeSorted()
+
+func Test_TPartitionMap_Keys_Merge(t *testing.T) {
+	// Compare the merge based result with a plain sort.
+	for _, count := range []int{1, 7, numberOfPartitionsInMap} {
+		pm := NewWithPartitions[string, int](count)
+		for range 10000 {
+			n := rand.IntN(1 << 20)
+			pm.Put(fmt.Sprintf("key-%d", n), n)
+		}
+
+		want := pm.KeysUnsorted()
+		slices.Sort(want)
+		if got := pm.Keys(); !slices.Equal(got, want) {
+			t.Errorf("Keys() with %d partitions differs from sorted keys",
+				count)
+		}
+	}
+
+	// NaN keys must be ordered like `slices.Sort()` does.
+	fpm := New[float64, int]()
+	for i := range 300 {
+		fpm.Put(rand.Float64()*1000, i)
+	}
+	fpm.Put(math.NaN(), -1)
+	want := fpm.KeysUnsorted()
+	slices.Sort(want)
+	same := func(a, b float64) bool { return 0 == cmp.Compare(a, b) }
+	got := fpm.Keys()
+	if !slices.IsSorted(got) || !slices.EqualFunc(got, want, same) {
+		t.Errorf("Keys() with NaN differs from sorted keys")
+	}
+	got = fpm.KeysDesc()
+	slices.Reverse(want)
+	if !slices.EqualFunc(got, want, same) {
+		t.Errorf("KeysDesc() with NaN differs from reversed sorted keys")
+	}
+} // Test_TPartitionMap_Keys_Merge()
+
+func Benchmark_TPartitionMap_Keys(b *testing.B) {
+	pm := New[string, int]()
+	for i := range 1 << 18 {
+		pm.Put(fmt.Sprintf("key-%d", i), i)
+	}
+
+	b.Run("SortAll", func(b *testing.B) {
+		for range b.N {
+			keys := pm.KeysUnsorted()
+			slices.Sort(keys)
+		}
+	})
+	b.Run("MergePartitions", func(b *testing.B) {
+		for range b.N {
+			_ = pm.Keys()
+		}
+	})
+} // Benchmark_TPartitionMap_Keys()
+
+/* _EoF_ */
@@ -0,0 +1,140 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"slices"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides the optional recording of the order in which
+// the keys were inserted into a partitioned map.
+//
+// Each partition stores a sequence number for each of its keys,
+// drawn from a counter shared by all partitions when the key is
+// inserted. Updating an existing key keeps its sequence number,
+// while a key deleted and inserted again gets a new one (i.e. it
+// moves to the end of the order).
+//
+// The sequence numbers cost additional memory of about 40 bytes per
+// key (an entry in a second map holding a copy of the key and an
+// 8-byte number). `Rebalance()` and `Resize()` keep the keys' order.
+// `SetAll()` keeps the order of the keys present before and appends
+// the new ones in an unspecified order. The order is not inherited
+// by copies like those made by `Clone()` or `Snapshot()`.
+
+// ---------------------------------------------------------------------------
+// `tPartition` methods:
+
+// `enlist()` assigns the next sequence number to the given key
+// unless it already has one.
+//
+// The caller must hold the partition's write lock.
+//
+// Parameters:
+//   - `aKey`: The key of the stored key/value pair.
+func (p *tPartition[K, V]) enlist(aKey K) {
+	if nil == p.order {
+		return
+	}
+
+	if _, ok := p.order[aKey]; !ok {
+		p.order[aKey] = p.seq.Add(1)
+	}
+} // enlist()
+
+// `reorder()` replaces the sequence number of the given key, e.g.
+// after it was moved from another partition.
+//
+// Parameters:
+//   - `aKey`: The key to update.
+//   - `aSeq`: The key's (original) sequence number; `0` is ignored.
+func (p *tPartition[K, V]) reorder(aKey K, aSeq uint64) {
+	if (nil == p) || (0 == aSeq) {
+		return
+	}
+
+	p.Lock()
+	if _, ok := p.order[aKey]; ok {
+		p.order[aKey] = aSeq
+	}
+	p.Unlock()
+} // reorder()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` constructor:
+
+// `NewOrdered()` creates and initialises a new partitioned map
+// instance recording the order in which the keys are inserted.
+//
+// The order can be retrieved by `OrderedKeys()`. See the notes at
+// the top of this file about the additional memory used.
+// Other than that the map behaves like one created by `New()`.
+//
+// Example usage:
+//
+//	audit := NewOrdered[string, *TEvent]()
+//	// ...
+//	for _, id := range audit.OrderedKeys() {
+//		// ...
+//	}
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewOrdered[K cmp.Ordered, V any]() *TPartitionMap[K, V] {
+	result := New[K, V]()
+	result.ordered = true
+
+	return result
+} // NewOrdered()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `OrderedKeys()` returns all keys of the partitioned map in the
+// order they were inserted.
+//
+// For a map not created by `NewOrdered()` the result is empty.
+//
+// Returns:
+//   - `[]K`: The keys sorted by their insertion order.
+func (pm *TPartitionMap[K, V]) OrderedKeys() []K {
+	if nil == pm {
+		return nil
+	}
+
+	type tSeqKey struct {
+		key K
+		seq uint64
+	}
+
+	var pairs []tSeqKey
+	for _, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		p.RLock()
+		for k, seq := range p.order {
+			pairs = append(pairs, tSeqKey{k, seq})
+		}
+		p.RUnlock()
+	}
+	slices.SortFunc(pairs, func(a, b tSeqKey) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+
+	result := make([]K, len(pairs))
+	for idx, pair := range pairs {
+		result[idx] = pair.key
+	}
+
+	return result
+} // OrderedKeys()
+
+/* _EoF_ */
@@ -0,0 +1,85 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TPartitionMap_OrderedKeys(t *testing.T) {
+	pm := NewOrdered[string, int]()
+	keys := []string{"pear", "apple", "zucchini", "banana", "fig", "cherry"}
+	for i, key := range keys {
+		pm.Put(key, i)
+	}
+	if got := pm.OrderedKeys(); !slices.Equal(got, keys) {
+		t.Errorf("OrderedKeys() = %v, want %v", got, keys)
+	}
+
+	// Updating keeps the order, re-inserting moves to the end.
+	pm.Put("apple", 11).Delete("zucchini").Put("zucchini", 22)
+	want := []string{"pear", "apple", "banana", "fig", "cherry", "zucchini"}
+	if got := pm.OrderedKeys(); !slices.Equal(got, want) {
+		t.Errorf("OrderedKeys() after update = %v, want %v", got, want)
+	}
+
+	// Moving the entries keeps the order.
+	pm.Resize(7)
+	if got := pm.OrderedKeys(); !slices.Equal(got, want) {
+		t.Errorf("OrderedKeys() after Resize() = %v, want %v", got, want)
+	}
+	pm.SetHasher(func(aKey string) uint64 { return uint64(len(aKey)) }).Rebalance()
+	if got := pm.OrderedKeys(); !slices.Equal(got, want) {
+		t.Errorf("OrderedKeys() after Rebalance() = %v, want %v", got, want)
+	}
+
+	// `SetAll()` keeps the keys present before.
+	pm.SetAll(map[string]int{"fig": 1, "pear": 2, "kiwi": 3})
+	if got, want := pm.OrderedKeys(), []string{"pear", "fig", "kiwi"}; !slices.Equal(got, want) {
+		t.Errorf("OrderedKeys() after SetAll() = %v, want %v", got, want)
+	}
+
+	pm.Clear().Put("b", 1).Put("a", 2)
+	if got, want := pm.OrderedKeys(), []string{"b", "a"}; !slices.Equal(got, want) {
+		t.Errorf("OrderedKeys() after Clear() = %v, want %v", got, want)
+	}
+
+	if got := New[string, int]().Put("a", 1).OrderedKeys(); 0 != len(got) {
+		t.Errorf("OrderedKeys() without recording = %v, want none", got)
+	}
+	var npm *TPartitionMap[string, int]
+	if nil != npm.OrderedKeys() {
+		t.Error("OrderedKeys() on nil map should return nil")
+	}
+} // Test_TPartitionMap_OrderedKeys()
+
+func Test_TPartitionMap_OrderedKeys_Scrambled(t *testing.T) {
+	const numKeys = 1000
+
+	pm := NewOrdered[string, int]()
+	want := make([]string, 0, numKeys)
+	for _, i := range rand.Perm(numKeys) {
+		key := fmt.Sprintf("key%04d", i)
+		pm.Put(key, i)
+		want = append(want, key)
+	}
+
+	got := pm.OrderedKeys()
+	if !slices.Equal(got, want) {
+		t.Error("OrderedKeys() doesn't reflect the insertion order")
+	}
+	if slices.Equal(got, pm.Keys()) {
+		t.Error("OrderedKeys() equals the sorted keys")
+	}
+} // Test_TPartitionMap_OrderedKeys_Scrambled()
+
+/* _EoF_ */
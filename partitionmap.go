@@ -8,20 +8,50 @@ package partitionmap
 
 import (
 	"cmp"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"hash/crc32"
+	"hash/maphash"
 	"maps"
+	"math"
+	"math/rand/v2"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
 
 const (
-	// The number of partitions to use for the key/value pairs.
-	numberOfPartitionsInMap = 128 // well within byte range
+	// The default number of partitions to use for the key/value pairs.
+	numberOfPartitionsInMap = 128
+
+	// The lower and upper limits of a map's number of partitions.
+	minPartitionsInMap = 1
+	maxPartitionsInMap = 1 << 16
+
+	// Rough figures of Go's map implementation used by
+	// `EstimatedBytes()`: the size of a map's header and the
+	// additional bytes needed per entry (control data and slack).
+	mapHeaderBytes   = 48
+	mapEntryOverhead = 8
+
+	// A partition is rebuilt by `ShrinkToFit()` if it holds no more
+	// than a quarter of the entries it once held, but not if it
+	// never held more than a handful of entries.
+	shrinkFactor  = 4
+	shrinkMinPeak = 64
+)
+
+var (
+	// `ErrKeyNotFound` is returned when trying to update a key which
+	// is not present in the partitioned map.
+	ErrKeyNotFound = errors.New("partitionmap: key not found")
 )
 
 type (
@@ -30,18 +60,73 @@ type (
 
 	// `tPartition` implements a single partition in a `tPartitionList`.
 	tPartition[K cmp.Ordered, V any] struct {
-		sync.RWMutex               // protect the key/value store
-		kv           tKeyMap[K, V] // the key/value store
+		sync.RWMutex                      // protect the key/value store
+		kv           tKeyMap[K, V]        // the key/value store
+		expiry       *tExpiry             // the map's TTL configuration (if any)
+		deadlines    map[K]int64          // expiry times of the entries with a TTL
+		lru          *tLRU[K]             // recency of the keys in an LRU map
+		peak         int                  // max. number of entries since the last rebuild
+		inflight     map[K]*tFlight[V]    // values currently computed by `GetOrCompute()`
+		size         atomic.Int64         // live number of entries (see `len()`)
+		total        *atomic.Int64        // the map's running number of entries
+		hits         tHitMap[K]           // read counters (see `NewWithHitStats()`)
+		order        map[K]uint64         // insertion sequence (see `NewOrdered()`)
+		seq          *atomic.Uint64       // the map's insertion sequence counter
+		moved        *TPartitionMap[K, V] // set once the entries moved to a new layout
+	}
+
+	// `tFlight` is a value being computed by `GetOrCompute()` which
+	// other callers asking for the same key wait for.
+	tFlight[V any] struct {
+		done chan struct{} // closed when the computation finished
+		val  V             // the computed value
+		ok   bool          // `false` if the computation panicked
 	}
 
-	// `tPartitionList` is a slice of `tPartition` instances.
-	tPartitionList[K cmp.Ordered, V any] []*tPartition[K, V]
+	// `tPartitionList` is a slice of slots each holding a (lazily
+	// created) `tPartition` instance. The slots are accessed atomically
+	// so no map-wide lock is needed to look up or create a partition.
+	tPartitionList[K cmp.Ordered, V any] []atomic.Pointer[tPartition[K, V]]
+
+	// `tLayout` is the arrangement of a map's partitions. Apart from
+	// lazily creating the partitions in its slots a layout is never
+	// modified; changing the number of partitions or the hash function
+	// replaces the map's layout as a whole (see
+	// `TPartitionMap.relayout()`).
+	tLayout[K cmp.Ordered, V any] struct {
+		tPartitionList[K, V]                // the list of partitions
+		hasher               func(K) uint64 // optional custom hash function
+		lruLimit             int            // max. entries per partition (LRU map)
+	}
 
 	// `TPartitionMap` is a slice of partitions holding the
 	// key/value pairs.
 	TPartitionMap[K cmp.Ordered, V any] struct {
-		sync.RWMutex         // protect the list of partitions
-		tPartitionList[K, V] // the list of partitions
+		sync.RWMutex                                   // serialise whole-map operations
+		layout       atomic.Pointer[tLayout[K, V]]     // the current list of partitions
+		capacity     int                               // expected total number of entries
+		normalize    func(K) K                         // optional key normalisation
+		onPut        atomic.Pointer[func(K, V)]        // optional change hook
+		onDelete     atomic.Pointer[func(K)]           // optional change hook
+		expiry       *tExpiry                          // optional TTL configuration
+		generation   atomic.Uint64                     // number of modifications
+		total        atomic.Int64                      // running number of entries
+		hitStats     bool                              // whether to count reads
+		seed         uint32                            // optional hash seed
+		secure       *maphash.Seed                     // optional keyed hashing
+		encode       func(K) []byte                    // optional key serialisation
+		ordered      bool                              // whether to record the insertion order
+		sequence     atomic.Uint64                     // insertion sequence counter
+		sparse       int                               // partition count before `CompactSparse()`
+		watchers     atomic.Pointer[[]*tWatcher[K, V]] // subscribers (see `Watch()`)
+		sweeper      chan struct{}                     // stops the background sweeper
+	}
+
+	// `TPair` is a single key/value pair as returned by
+	// `TPartitionMap.Entries()`.
+	TPair[K cmp.Ordered, V any] struct {
+		Key   K
+		Value V
 	}
 )
 
@@ -52,33 +137,146 @@ type (
 // initialises a new partition.
 // Each partition holds a set of key/value pairs.
 //
-// The function takes no parameters and returns a pointer to a new
-// `tPartition` instance.
-//
 // The returned partition is initialised with a read-write mutex and
-// an empty map.
+// an empty map with room for (at least) the given number of entries.
 //
 // Example usage:
 //
-//	partition := newPartition[string, int]()
+//	partition := newPartition[string, int](0)
 //	partition.put("key1", 10)
 //	partition.put("key2", 20)
-//	value, ok := partition.get("key1")
+//	value, ok, _ := partition.get("key1")
 //	fmt.Println(value, ok) // Output: 10 true
 //
+// Parameters:
+//   - `aSize`: The number of entries to allocate space for.
+//
 // Returns:
 //   - `*tPartition[K, V]`: A pointer to a newly created partition.
-func newPartition[K cmp.Ordered, V any]() *tPartition[K, V] {
+func newPartition[K cmp.Ordered, V any](aSize int) *tPartition[K, V] {
 	p := &tPartition[K, V]{
-		kv: make(tKeyMap[K, V]),
+		kv:   make(tKeyMap[K, V], max(aSize, 0)),
+		peak: max(aSize, 0),
 	}
 
 	return p
 } // newPartition()
 
+// `wrapPartition()` creates a new partition using the given
+// key/value store.
+//
+// Parameters:
+//   - `aKV`: The key/value pairs of the new partition.
+//   - `aTotal`: The running number of entries of the partition's map.
+//
+// Returns:
+//   - `*tPartition[K, V]`: A pointer to a newly created partition.
+func wrapPartition[K cmp.Ordered, V any](aKV tKeyMap[K, V], aTotal *atomic.Int64) *tPartition[K, V] {
+	p := &tPartition[K, V]{
+		kv:    aKV,
+		peak:  len(aKV),
+		total: aTotal,
+	}
+	p.sync()
+
+	return p
+} // wrapPartition()
+
 // ---------------------------------------------------------------------------
 // `tPartition` methods:
 
+// `anyMatch()` reports whether the given predicate returns `true`
+// for any key/value pair in the partition.
+//
+// The predicate is called with the partition's read lock held.
+// The search stops at the first match.
+//
+// Parameters:
+//   - `aPred`: The predicate to check.
+//
+// Returns:
+//   - `bool`: `true` if a matching pair was found.
+func (p *tPartition[K, V]) anyMatch(aPred func(aKey K, aValue V) bool) bool {
+	if nil == p {
+		return false
+	}
+
+	p.RLock()
+	defer p.RUnlock() // in case `aPred` panics
+
+	for k, v := range p.kv {
+		if aPred(k, v) {
+			return true
+		}
+	}
+
+	return false
+} // anyMatch()
+
+// `assign()` replaces the partition's key/value pairs by the given
+// ones.
+//
+// Other than `touch()` this method doesn't update the map's running
+// total; that's left to the caller.
+// The caller must hold the partition's write lock.
+//
+// Parameters:
+//   - `aKV`: The new key/value pairs (owned by the partition afterwards).
+func (p *tPartition[K, V]) assign(aKV tKeyMap[K, V]) {
+	p.kv = aKV
+	p.deadlines = nil
+	if nil != p.hits {
+		p.hits = make(tHitMap[K], len(aKV))
+	}
+	p.lru.reset()
+	order := p.order
+	if nil != order {
+		// Keys present before keep their position.
+		p.order = make(map[K]uint64, len(aKV))
+		for key := range aKV {
+			if seq, ok := order[key]; ok {
+				p.order[key] = seq
+			}
+		}
+	}
+	for key := range aKV {
+		p.renew(key)
+		p.hits.add(key)
+		p.enlist(key)
+		p.lru.use(key)
+	}
+	p.peak = max(p.peak, len(aKV))
+	p.size.Store(int64(len(aKV)))
+} // assign()
+
+// `bounds()` returns the smallest and largest key in the partition.
+//
+// Returns:
+//   - `K`: The smallest key.
+//   - `K`: The largest key.
+//   - `bool`: `false` if the partition is empty.
+func (p *tPartition[K, V]) bounds() (rMin, rMax K, rOk bool) {
+	if nil == p {
+		return
+	}
+
+	p.RLock()
+	for k := range p.kv {
+		if !rOk {
+			rMin, rMax, rOk = k, k, true
+			continue
+		}
+		if k < rMin {
+			rMin = k
+		} else if k > rMax {
+			rMax = k
+		}
+	}
+	p.RUnlock()
+
+	return
+} // bounds()
+
 // `clear()` removes all key/value pairs from the partition.
 //
 // Returns:
@@ -88,10 +286,17 @@ func (p *tPartition[K, V]) clear() *tPartition[K, V] {
 		return nil
 	}
 
-	p.Lock()
+	if !p.lock() {
+		return p
+	}
 	// For maps, `clear()` deletes all entries,
 	// resulting in an empty map.
 	clear(p.kv)
+	clear(p.deadlines)
+	clear(p.hits)
+	clear(p.order)
+	p.lru.reset()
+	p.sync()
 	p.Unlock()
 
 	return p
@@ -112,6 +317,70 @@ func (p *tPartition[K, V]) clone() tKeyMap[K, V] {
 	return result
 } // clone()
 
+// `compute()` atomically updates the value of the given key.
+//
+// The given function is called with the partition's write lock
+// held, hence it must not access the partition itself.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to update.
+//   - `aFunc`: The function computing the new value.
+//
+// Returns:
+//   - `V`: The resulting value associated with the key.
+//   - `bool`: Indicating whether the key exists after the update.
+//   - `bool`: Indicating whether an existing key was removed.
+//   - `*tPartition[K, V]`: The partition actually updated (see `lock()`).
+func (p *tPartition[K, V]) compute(aKey K, aFunc func(aOld V, aFound bool) (V, bool)) (rVal V, rOk, rRemoved bool, rPartition *tPartition[K, V]) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return p.successor(aKey).compute(aKey, aFunc)
+	}
+	defer p.Unlock() // in case `aFunc` panics
+
+	old, found := p.kv[aKey]
+	val, del := aFunc(old, found)
+	if del {
+		delete(p.kv, aKey)
+		p.forget(aKey)
+		return rVal, false, found, p
+	}
+	p.kv[aKey] = val
+	p.touch(aKey)
+
+	return val, true, false, p
+} // compute()
+
+// `count()` returns the number of key/value pairs in the partition
+// for which the given predicate returns `true`.
+//
+// The predicate is called with the partition's read lock held.
+//
+// Parameters:
+//   - `aPred`: The predicate deciding which pairs to count.
+//
+// Returns:
+//   - `int`: The number of matching key/value pairs.
+func (p *tPartition[K, V]) count(aPred func(aKey K, aValue V) bool) (rCount int) {
+	if nil == p {
+		return
+	}
+
+	p.RLock()
+	defer p.RUnlock() // in case `aPred` panics
+
+	for k, v := range p.kv {
+		if aPred(k, v) {
+			rCount++
+		}
+	}
+
+	return
+} // count()
+
 // `del()` removes a key/value pair from the partition.
 //
 // This method is used to delete a key/value pair from the partition.
@@ -121,19 +390,163 @@ func (p *tPartition[K, V]) clone() tKeyMap[K, V] {
 //   - `aKey`: The key of the key/value pair to be deleted.
 //
 // Returns:
-//   - `*tPartition[K, V]`: The partition itself, allowing method chaining.
-func (p *tPartition[K, V]) del(aKey K) *tPartition[K, V] {
+//   - `bool`: Indicating whether the key was found and removed.
+func (p *tPartition[K, V]) del(aKey K) (rOk bool) {
 	if nil == p {
-		return nil
+		return
 	}
 
-	p.Lock()
-	delete(p.kv, aKey)
+	if !p.lock() {
+		return p.successor(aKey).del(aKey)
+	}
+	if _, rOk = p.kv[aKey]; rOk {
+		delete(p.kv, aKey)
+		p.forget(aKey)
+	}
 	p.Unlock()
 
-	return p
+	return
 } // del()
 
+// `delAll()` removes the key/value pairs with the given keys from
+// the partition.
+//
+// All keys are removed under a single acquisition of the write lock.
+// The given slice is reused to return the keys actually removed.
+//
+// Parameters:
+//   - `aKeys`: The keys of the key/value pairs to be deleted.
+//
+// Returns:
+//   - `[]K`: The keys of the key/value pairs actually removed.
+func (p *tPartition[K, V]) delAll(aKeys []K) (rKeys []K) {
+	if nil == p {
+		return
+	}
+
+	rKeys = aKeys[:0]
+	if !p.lock() {
+		for _, key := range aKeys {
+			if p.successor(key).del(key) {
+				rKeys = append(rKeys, key)
+			}
+		}
+		return
+	}
+	for _, key := range aKeys {
+		if _, ok := p.kv[key]; ok {
+			delete(p.kv, key)
+			p.forget(key)
+			rKeys = append(rKeys, key)
+		}
+	}
+	p.Unlock()
+
+	return
+} // delAll()
+
+// `deleteIf()` removes all key/value pairs from the partition for
+// which the given predicate returns `true`.
+//
+// The predicate is called with the partition's write lock held.
+//
+// Parameters:
+//   - `aPred`: The predicate deciding which pairs to remove.
+//
+// Returns:
+//   - `[]K`: The keys of the removed key/value pairs.
+func (p *tPartition[K, V]) deleteIf(aPred func(aKey K, aValue V) bool) (rKeys []K) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return
+	}
+	defer p.Unlock() // in case `aPred` panics
+
+	for k, v := range p.kv {
+		if aPred(k, v) {
+			delete(p.kv, k)
+			p.forget(k)
+			rKeys = append(rKeys, k)
+		}
+	}
+
+	return
+} // deleteIf()
+
+// `drain()` removes all key/value pairs from the partition and
+// returns them.
+//
+// The partition's key/value store is swapped for an empty one under
+// the write lock, hence no pair is copied.
+//
+// Returns:
+//   - `tKeyMap[K, V]`: The removed key/value pairs.
+func (p *tPartition[K, V]) drain() (rKV tKeyMap[K, V]) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return
+	}
+	rKV = p.kv
+	p.kv = make(tKeyMap[K, V])
+	p.deadlines = nil
+	if nil != p.hits {
+		p.hits = make(tHitMap[K])
+	}
+	if nil != p.order {
+		p.order = make(map[K]uint64)
+	}
+	p.lru.reset()
+	p.peak = 0
+	p.sync()
+	p.Unlock()
+
+	return
+} // drain()
+
+// `estimatedBytes()` returns a rough estimate of the partition's
+// heap usage.
+//
+// Parameters:
+//   - `aKeyStr`: Whether the key type's underlying type is a string.
+//   - `aValStr`: Whether the value type's underlying type is a string.
+//
+// Returns:
+//   - `int64`: The estimated number of bytes.
+func (p *tPartition[K, V]) estimatedBytes(aKeyStr, aValStr bool) (rBytes int64) {
+	if nil == p {
+		return
+	}
+
+	var (
+		k K
+		v V
+	)
+	entrySize := int64(unsafe.Sizeof(k) + unsafe.Sizeof(v) + mapEntryOverhead)
+	rBytes = int64(unsafe.Sizeof(*p)) + mapHeaderBytes
+
+	p.RLock()
+	rBytes += int64(len(p.kv)) * entrySize
+	if aKeyStr || aValStr {
+		for k, v := range p.kv {
+			if aKeyStr {
+				rBytes += int64(reflect.ValueOf(k).Len())
+			}
+			if aValStr {
+				rBytes += int64(reflect.ValueOf(v).Len())
+			}
+		}
+	}
+	p.RUnlock()
+
+	return
+} // estimatedBytes()
+
 // `forEach()` executes the provided function for each key/value pair
 // in the partition.
 //
@@ -159,6 +572,22 @@ func (p *tPartition[K, V]) forEach(aFunc func(aKey K, aValue V)) *tPartition[K,
 	return p
 } // forEach()
 
+// `forget()` updates the bookkeeping data (i.e. expiry time, read
+// counter, recency, and the partition's live size) after the given key's value was
+// deleted.
+//
+// The caller must hold the partition's write lock.
+//
+// Parameters:
+//   - `aKey`: The key of the deleted key/value pair.
+func (p *tPartition[K, V]) forget(aKey K) {
+	delete(p.deadlines, aKey)
+	delete(p.hits, aKey)
+	delete(p.order, aKey)
+	p.lru.remove(aKey)
+	p.sync()
+} // forget()
+
 // `get()` retrieves a key/value pair from the partition.
 //
 // This method is used to fetch the value associated with a given
@@ -168,6 +597,8 @@ func (p *tPartition[K, V]) forEach(aFunc func(aKey K, aValue V)) *tPartition[K,
 // was found.
 // If the key is not found, the method returns the zero value of
 // type `V` and a boolean value of `false`.
+// An expired entry is treated as absent but not removed; that's left
+// to the caller. In an LRU partition the key is marked as used.
 //
 // Parameters:
 //   - `aKey`: The key of the key/value pair to be retrieved.
@@ -175,535 +606,3849 @@ func (p *tPartition[K, V]) forEach(aFunc func(aKey K, aValue V)) *tPartition[K,
 // Returns:
 //   - `V`: The value associated with the key (if found).
 //   - `bool`: Indicating whether the key was found.
-func (p *tPartition[K, V]) get(aKey K) (rVal V, rOk bool) {
+//   - `bool`: Indicating whether the key was found but is expired.
+func (p *tPartition[K, V]) get(aKey K) (rVal V, rOk, rExpired bool) {
 	if nil == p {
 		return
 	}
 
-	p.RLock()
-	rVal, rOk = p.kv[aKey]
-	p.RUnlock()
+	// Updating the recency of an LRU partition requires the write lock.
+	lock, unlock := p.rlock, p.RUnlock
+	if nil != p.lru {
+		lock, unlock = p.lock, p.Unlock
+	}
+
+	if !lock() {
+		return p.successor(aKey).get(aKey)
+	}
+	if rVal, rOk = p.kv[aKey]; rOk {
+		if p.expired(aKey, p.expiry.nanos()) {
+			var zeroVal V
+			rVal, rOk, rExpired = zeroVal, false, true
+		} else {
+			p.hits.hit(aKey)
+			p.lru.use(aKey)
+		}
+	}
+	unlock()
 
 	return
 } // get()
 
-// `keys()` returns a slice of all keys in the partition.
+// `getAndDelete()` removes a key/value pair from the partition
+// and returns the removed value.
 //
-// The partition holds a set of key/value pairs. This method retrieves
-// the keys from the partition and returns them in a sorted slice.
+// Parameters:
+//   - `aKey`: The key of the key/value pair to be removed.
 //
 // Returns:
-//   - `rKeys`: A slice of the keys in the current partition.
-func (p *tPartition[K, V]) keys() (rKeys []K) {
+//   - `V`: The removed value (if found).
+//   - `bool`: Indicating whether the key was found.
+func (p *tPartition[K, V]) getAndDelete(aKey K) (rVal V, rOk bool) {
 	if nil == p {
 		return
 	}
 
-	p.RLock()
-	for k := range p.kv {
-		rKeys = append(rKeys, k)
+	if !p.lock() {
+		return p.successor(aKey).getAndDelete(aKey)
 	}
-	p.RUnlock()
-
-	slices.Sort(rKeys)
+	if rVal, rOk = p.kv[aKey]; rOk {
+		delete(p.kv, aKey)
+		p.forget(aKey)
+	}
+	p.Unlock()
 
 	return
-} // keys()
+} // getAndDelete()
 
-// `len()` returns the number of key/value pairs in the partition.
+// `getMany()` looks up the given keys in the partition and adds the
+// found key/value pairs to the given map.
 //
-// Returns:
-//   - `rLen`: The number of key/value pairs in the partition.
-func (p *tPartition[K, V]) len() (rLen int) {
-	if nil != p {
-		p.RLock()
-		rLen = len(p.kv)
-		p.RUnlock()
+// All keys are looked up under a single acquisition of the read lock.
+// Expired entries are skipped.
+//
+// Parameters:
+//   - `aKeys`: The keys to look up.
+//   - `aResult`: The map to add the found key/value pairs to.
+func (p *tPartition[K, V]) getMany(aKeys []K, aResult map[K]V) {
+	if nil == p {
+		return
 	}
 
-	return
-} // len()
+	if !p.rlock() {
+		for _, key := range aKeys {
+			p.successor(key).getMany([]K{key}, aResult)
+		}
+		return
+	}
+	now := p.expiry.nanos()
+	for _, key := range aKeys {
+		if val, ok := p.kv[key]; ok && !p.expired(key, now) {
+			aResult[key] = val
+		}
+	}
+	p.RUnlock()
+} // getMany()
 
-// `put()` stores a key/value pair in the partition.
-// If the key already exists, it will be updated.
+// `getOrCompute()` returns the value of the given key, computing and
+// storing it if the key is not present (or expired).
+//
+// The computation runs without holding the partition's lock. Other
+// callers asking for the same key meanwhile wait for its result
+// instead of computing the value again.
 //
 // Parameters:
-//   - `aKey`: The key to be store in the partition.
-//   - `aValue`: The value associated with the key.
+//   - `aKey`: The key to look up.
+//   - `aCompute`: The function computing a missing value.
 //
 // Returns:
-//   - `*tPartition[K, V]`: The partition itself, allowing method chaining.
-func (p *tPartition[K, V]) put(aKey K, aVal V) *tPartition[K, V] {
-	if nil == p {
-		return nil
-	}
+//   - `V`: The existing or computed value.
+//   - `bool`: `true` if the value was computed by this call.
+//   - `*tPartition[K, V]`: The partition actually updated (see `lock()`).
+func (p *tPartition[K, V]) getOrCompute(aKey K, aCompute func(aKey K) V) (V, bool, *tPartition[K, V]) {
+	for {
+		if !p.lock() {
+			return p.successor(aKey).getOrCompute(aKey, aCompute)
+		}
+		if val, ok := p.kv[aKey]; ok && !p.expired(aKey, p.expiry.nanos()) {
+			p.lru.use(aKey)
+			p.Unlock()
+			return val, false, p
+		}
+		if flight, ok := p.inflight[aKey]; ok {
+			p.Unlock()
+			if <-flight.done; flight.ok {
+				return flight.val, false, p
+			}
+			continue // the computation panicked, so try ourselves
+		}
 
-	p.Lock()
-	p.kv[aKey] = aVal
-	p.Unlock()
+		flight := &tFlight[V]{done: make(chan struct{})}
+		if nil == p.inflight {
+			p.inflight = make(map[K]*tFlight[V])
+		}
+		p.inflight[aKey] = flight
+		p.Unlock()
 
-	return p
-} // put()
+		written := p.runFlight(aKey, aCompute, flight)
 
-// `String()` returns a string representation of the partition.
+		return flight.val, true, written
+	}
+} // getOrCompute()
+
+// `has()` reports whether the partition contains the given key.
 //
-// The method iterates over all key/value pairs in the partition
-// and concatenates their string representations.
-// The keys in the returned string are sorted in ascending order.
+// Other than `get()` this method neither treats the key as used nor
+// copies its value; an expired entry is treated as absent.
+//
+// Parameters:
+//   - `aKey`: The key to look for.
 //
 // Returns:
-//   - `string`: A string representation of the partition.
-func (p *tPartition[K, V]) String() string {
+//   - `bool`: `true` if the key is present.
+func (p *tPartition[K, V]) has(aKey K) (rOk bool) {
 	if nil == p {
-		return ""
+		return
 	}
 
-	// Create a snapshot of keys/values under lock to
-	// avoid holding lock during processing
-	kvMap := p.clone()
-	keys := make([]K, 0, len(kvMap))
-	for k := range kvMap {
-		keys = append(keys, k)
+	if !p.rlock() {
+		return p.successor(aKey).has(aKey)
+	}
+	_, rOk = p.kv[aKey]
+	rOk = rOk && !p.expired(aKey, p.expiry.nanos())
+	p.RUnlock()
+
+	return
+} // has()
+
+// `keys()` returns a slice of all keys in the partition.
+//
+// The partition holds a set of key/value pairs. This method retrieves
+// the keys from the partition and returns them in a sorted slice.
+//
+// Returns:
+//   - `rKeys`: A slice of the keys in the current partition.
+func (p *tPartition[K, V]) keys() (rKeys []K) {
+	if rKeys = p.keysUnsorted(); nil != rKeys {
+		slices.Sort(rKeys)
+	}
+
+	return
+} // keys()
+
+// `keysUnsorted()` returns a slice of all keys in the partition.
+//
+// Other than `keys()` this method doesn't sort the keys.
+//
+// Returns:
+//   - `rKeys`: A slice of the keys in the current partition.
+func (p *tPartition[K, V]) keysUnsorted() (rKeys []K) {
+	if nil == p {
+		return
+	}
+
+	p.RLock()
+	rKeys = slices.AppendSeq(make([]K, 0, len(p.kv)), maps.Keys(p.kv))
+	p.RUnlock()
+
+	return
+} // keysUnsorted()
+
+// `len()` returns the number of key/value pairs in the partition.
+//
+// The number is maintained by every modification of the partition,
+// so reading it requires no lock.
+//
+// Returns:
+//   - `rLen`: The number of key/value pairs in the partition.
+func (p *tPartition[K, V]) len() (rLen int) {
+	if nil != p {
+		rLen = int(p.size.Load())
+	}
+
+	return
+} // len()
+
+// `lock()` acquires the partition's write lock unless the partition
+// was retired by a layout change (see `TPartitionMap.relayout()`).
+//
+// A retired partition keeps its final contents for readers still
+// iterating the old layout but mustn't be modified anymore. Key based
+// methods therefore pass the request on to the key's partition in
+// the current layout (see `successor()`) if this method fails.
+//
+// Returns:
+//   - `bool`: `true` if the lock is held, `false` if the partition is retired.
+func (p *tPartition[K, V]) lock() bool {
+	p.Lock()
+	if nil != p.moved {
+		p.Unlock()
+		return false
+	}
+
+	return true
+} // lock()
+
+// `loadOrStore()` returns the existing value for the given key if
+// present. Otherwise, it stores and returns the given value.
+//
+// The check and the insertion are done under a single acquisition
+// of the partition's write lock.
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//   - `aValue`: The value to store if the key is not present.
+//
+// Returns:
+//   - `V`: The existing or newly stored value.
+//   - `bool`: `true` if the value was loaded, `false` if stored.
+//   - `*tPartition[K, V]`: The partition actually looked up (see `lock()`).
+func (p *tPartition[K, V]) loadOrStore(aKey K, aValue V) (rVal V, rLoaded bool, rPartition *tPartition[K, V]) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return p.successor(aKey).loadOrStore(aKey, aValue)
+	}
+	if rVal, rLoaded = p.kv[aKey]; !rLoaded {
+		p.kv[aKey] = aValue
+		p.touch(aKey)
+		rVal = aValue
+	}
+	p.Unlock()
+
+	return rVal, rLoaded, p
+} // loadOrStore()
+
+// `pop()` removes an arbitrary key/value pair from the partition
+// and returns it.
+//
+// Returns:
+//   - `K`: The removed key.
+//   - `V`: The removed value.
+//   - `bool`: `true` if a pair was removed, `false` if the partition is empty.
+func (p *tPartition[K, V]) pop() (rKey K, rVal V, rOk bool) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return
+	}
+	for rKey, rVal = range p.kv {
+		delete(p.kv, rKey)
+		p.forget(rKey)
+		rOk = true
+		break
+	}
+	p.Unlock()
+
+	return
+} // pop()
+
+// `put()` stores a key/value pair in the partition.
+// If the key already exists, it will be updated.
+//
+// Parameters:
+//   - `aKey`: The key to be store in the partition.
+//   - `aValue`: The value associated with the key.
+//
+// Returns:
+//   - `*tPartition[K, V]`: The partition itself, allowing method chaining.
+func (p *tPartition[K, V]) put(aKey K, aVal V) *tPartition[K, V] {
+	if nil == p {
+		return nil
+	}
+
+	if !p.lock() {
+		return p.successor(aKey).put(aKey, aVal)
+	}
+	p.kv[aKey] = aVal
+	p.touch(aKey)
+	p.Unlock()
+
+	return p
+} // put()
+
+// `putAll()` stores the given key/value pairs in the partition.
+//
+// All pairs are stored under a single acquisition of the write lock.
+// The pairs of a retired partition are passed on to their successors
+// one by one, each of which is checked against its size limit right
+// away (see `TPartitionMap.evict()`).
+//
+// Parameters:
+//   - `aPairs`: The key/value pairs to store.
+//
+// Returns:
+//   - `*tPartition[K, V]`: The partition itself (`nil` if the pairs were passed on).
+func (p *tPartition[K, V]) putAll(aPairs []TPair[K, V]) *tPartition[K, V] {
+	if nil == p {
+		return nil
+	}
+
+	if !p.lock() {
+		for _, pair := range aPairs {
+			p.moved.evict(p.successor(pair.Key).put(pair.Key, pair.Value))
+		}
+		return nil
+	}
+	for _, pair := range aPairs {
+		p.kv[pair.Key] = pair.Value
+		p.touch(pair.Key)
+	}
+	p.Unlock()
+
+	return p
+} // putAll()
+
+// `putIfPresent()` stores the given value only if the key is already
+// present (and not expired) in the partition.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to update.
+//   - `aVal`: The new value.
+//
+// Returns:
+//   - `bool`: `true` if the value was stored.
+func (p *tPartition[K, V]) putIfPresent(aKey K, aVal V) (rOk bool) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return p.successor(aKey).putIfPresent(aKey, aVal)
+	}
+	if _, rOk = p.kv[aKey]; rOk && !p.expired(aKey, p.expiry.nanos()) {
+		p.kv[aKey] = aVal
+		p.touch(aKey)
+	} else {
+		rOk = false
+	}
+	p.Unlock()
+
+	return
+} // putIfPresent()
+
+// `rangeEach()` calls the given function for each key/value pair in
+// the partition whose key lies within the given (inclusive) bounds.
+//
+// The function is called with the partition's read lock held.
+//
+// Parameters:
+//   - `aLo`: The lower bound of the keys.
+//   - `aHi`: The upper bound of the keys.
+//   - `aFunc`: The function to call for each matching pair.
+func (p *tPartition[K, V]) rangeEach(aLo, aHi K, aFunc func(aKey K, aValue V)) {
+	if nil == p {
+		return
+	}
+
+	p.RLock()
+	for k, v := range p.kv {
+		if (0 <= cmp.Compare(k, aLo)) && (0 >= cmp.Compare(k, aHi)) {
+			aFunc(k, v)
+		}
+	}
+	p.RUnlock()
+} // rangeEach()
+
+// `rlock()` acquires the partition's read lock unless the partition
+// was retired by a layout change (see `lock()`).
+//
+// Returns:
+//   - `bool`: `true` if the lock is held, `false` if the partition is retired.
+func (p *tPartition[K, V]) rlock() bool {
+	p.RLock()
+	if nil != p.moved {
+		p.RUnlock()
+		return false
+	}
+
+	return true
+} // rlock()
+
+// `runFlight()` runs the given computation for `getOrCompute()` and
+// stores its result.
+//
+// The waiting callers are released even if the computation panics.
+//
+// Parameters:
+//   - `aKey`: The key whose value to compute.
+//   - `aCompute`: The function computing the value.
+//   - `aFlight`: The computation's state shared with the waiting callers.
+//
+// Returns:
+//   - `*tPartition[K, V]`: The partition the result was stored in (see `lock()`).
+func (p *tPartition[K, V]) runFlight(aKey K, aCompute func(aKey K) V, aFlight *tFlight[V]) (rPartition *tPartition[K, V]) {
+	defer func() {
+		p.Lock()
+		delete(p.inflight, aKey)
+		moved := (nil != p.moved)
+		if aFlight.ok && !moved {
+			p.kv[aKey] = aFlight.val
+			p.touch(aKey)
+			rPartition = p
+		}
+		p.Unlock()
+		if aFlight.ok && moved {
+			rPartition = p.successor(aKey).put(aKey, aFlight.val)
+		}
+		close(aFlight.done)
+	}()
+
+	aFlight.val = aCompute(aKey)
+	aFlight.ok = true
+
+	return
+} // runFlight()
+
+// `shrink()` rebuilds the partition's key/value store if it holds
+// far less entries than it once did.
+//
+// Go's maps never release the memory of deleted entries; only a
+// fresh map sized for the current entries does.
+//
+// Returns:
+//   - `bool`: Indicating whether the partition was rebuilt.
+func (p *tPartition[K, V]) shrink() bool {
+	if nil == p {
+		return false
+	}
+
+	if !p.lock() {
+		return false
+	}
+	defer p.Unlock()
+
+	if (shrinkMinPeak > p.peak) || (len(p.kv)*shrinkFactor > p.peak) {
+		return false // already compact
+	}
+
+	kv := make(tKeyMap[K, V], len(p.kv))
+	maps.Copy(kv, p.kv)
+	p.kv = kv
+	if nil != p.deadlines {
+		deadlines := make(map[K]int64, len(p.deadlines))
+		maps.Copy(deadlines, p.deadlines)
+		p.deadlines = deadlines
+	}
+	if nil != p.hits {
+		hits := make(tHitMap[K], len(p.hits))
+		maps.Copy(hits, p.hits)
+		p.hits = hits
+	}
+	if nil != p.order {
+		order := make(map[K]uint64, len(p.order))
+		maps.Copy(order, p.order)
+		p.order = order
+	}
+	p.peak = len(kv)
+
+	return true
+} // shrink()
+
+// `String()` returns a string representation of the partition.
+//
+// The method iterates over all key/value pairs in the partition
+// and concatenates their string representations.
+// The keys in the returned string are sorted in ascending order.
+//
+// Returns:
+//   - `string`: A string representation of the partition.
+func (p *tPartition[K, V]) String() string {
+	if nil == p {
+		return ""
+	}
+
+	// Create a snapshot of keys/values under lock to
+	// avoid holding lock during processing
+	kvMap := p.clone()
+	keys := make([]K, 0, len(kvMap))
+	for k := range kvMap {
+		keys = append(keys, k)
 	}
 	slices.Sort(keys)
 
-	var builder strings.Builder
-	for _, k := range keys {
-		builder.WriteString(fmt.Sprintf("%v: '%v'\n", k, kvMap[k]))
+	var builder strings.Builder
+	for _, k := range keys {
+		builder.WriteString(fmt.Sprintf("%v: '%v'\n", k, kvMap[k]))
+	}
+
+	return builder.String()
+} // String()
+
+// `successor()` returns the partition which took over the given key
+// from this retired partition, i.e. the key's partition in the map's
+// current layout (see `lock()`).
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//
+// Returns:
+//   - `*tPartition[K, V]`: The key's current partition.
+func (p *tPartition[K, V]) successor(aKey K) *tPartition[K, V] {
+	result, _ := p.moved.partition(aKey, true)
+
+	return result
+} // successor()
+
+// `sync()` updates the partition's live number of entries as well
+// as the running total of its map.
+//
+// Since it's called with the partition's write lock held after every
+// modification, the difference to the previous number is exactly the
+// number of inserted (or removed) keys; updates of existing keys
+// don't change it.
+//
+// The caller must hold the partition's write lock.
+func (p *tPartition[K, V]) sync() {
+	size := int64(len(p.kv))
+	if delta := size - p.size.Swap(size); (0 != delta) && (nil != p.total) {
+		p.total.Add(delta)
+	}
+} // sync()
+
+// `take()` copies up to the given number of arbitrary key/value
+// pairs from the partition into the given map.
+//
+// Parameters:
+//   - `aCount`: The max. number of pairs to copy.
+//   - `aResult`: The map to copy the pairs to.
+func (p *tPartition[K, V]) take(aCount int, aResult map[K]V) {
+	if nil == p {
+		return
+	}
+
+	now := p.expiry.nanos()
+	p.RLock()
+	for k, v := range p.kv {
+		if 0 >= aCount {
+			break
+		}
+		if !p.expired(k, now) {
+			aResult[k] = v
+			aCount--
+		}
+	}
+	p.RUnlock()
+} // take()
+
+// `takeAndDelete()` moves up to the given number of arbitrary
+// key/value pairs from the partition into the given map.
+//
+// Parameters:
+//   - `aCount`: The max. number of pairs to move.
+//   - `aResult`: The map to move the pairs to.
+//
+// Returns:
+//   - `[]K`: The keys of the removed key/value pairs.
+func (p *tPartition[K, V]) takeAndDelete(aCount int, aResult map[K]V) (rKeys []K) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return
+	}
+	now := p.expiry.nanos()
+	for k, v := range p.kv {
+		if 0 >= aCount {
+			break
+		}
+		if !p.expired(k, now) {
+			aResult[k] = v
+			rKeys = append(rKeys, k)
+			aCount--
+		}
+	}
+	for _, key := range rKeys {
+		delete(p.kv, key)
+		p.forget(key)
+	}
+	p.Unlock()
+
+	return
+} // takeAndDelete()
+
+// `touch()` updates the bookkeeping data (i.e. expiry time, read
+// counter, insertion sequence, recency, and the partition's peak and
+// live size) after the given key's value was stored.
+//
+// The caller must hold the partition's write lock.
+//
+// Parameters:
+//   - `aKey`: The key of the stored key/value pair.
+func (p *tPartition[K, V]) touch(aKey K) {
+	p.renew(aKey)
+	p.hits.add(aKey)
+	p.enlist(aKey)
+	p.lru.use(aKey)
+	p.peak = max(p.peak, len(p.kv))
+	p.sync()
+} // touch()
+
+// `upsert()` stores the given key/value pairs in the partition,
+// combining them with existing values by the given function.
+//
+// All pairs are stored under a single acquisition of the write lock.
+// The values of the given pairs are replaced by the values actually
+// stored. Like `putAll()` a retired partition passes the pairs on to
+// their successors.
+//
+// Parameters:
+//   - `aPairs`: The key/value pairs to store.
+//   - `aMerge`: The function combining an existing and an incoming value.
+//
+// Returns:
+//   - `*tPartition[K, V]`: The partition itself (`nil` if the pairs were passed on).
+func (p *tPartition[K, V]) upsert(aPairs []TPair[K, V], aMerge func(aExisting, aIncoming V) V) *tPartition[K, V] {
+	if nil == p {
+		return nil
+	}
+
+	if !p.lock() {
+		for idx, pair := range aPairs {
+			p.moved.evict(p.successor(pair.Key).upsert(aPairs[idx:idx+1], aMerge))
+		}
+		return nil
+	}
+	defer p.Unlock() // in case `aMerge` panics
+
+	now := p.expiry.nanos()
+	for idx, pair := range aPairs {
+		if old, ok := p.kv[pair.Key]; ok && !p.expired(pair.Key, now) {
+			aPairs[idx].Value = aMerge(old, pair.Value)
+		}
+		p.kv[pair.Key] = aPairs[idx].Value
+		p.touch(pair.Key)
+	}
+
+	return p
+} // upsert()
+
+// `update()` replaces the value of the given key by the result of
+// the given function.
+//
+// The function is called with the partition's write lock held.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to update.
+//   - `aFunc`: The function computing the new value.
+//
+// Returns:
+//   - `V`: The new value (if stored).
+//   - `error`: `ErrKeyNotFound` or the function's error, if any.
+func (p *tPartition[K, V]) update(aKey K, aFunc func(aOld V) (V, error)) (rVal V, rErr error) {
+	if nil == p {
+		return rVal, ErrKeyNotFound
+	}
+
+	if !p.lock() {
+		return p.successor(aKey).update(aKey, aFunc)
+	}
+	defer p.Unlock() // in case `aFunc` panics
+
+	old, ok := p.kv[aKey]
+	if !ok || p.expired(aKey, p.expiry.nanos()) {
+		return rVal, ErrKeyNotFound
+	}
+	val, err := aFunc(old)
+	if nil != err {
+		return rVal, err
+	}
+	p.kv[aKey] = val
+	p.touch(aKey)
+
+	return val, nil
+} // update()
+
+// ---------------------------------------------------------------------------
+// `tLayout` constructor:
+
+// `newLayout()` creates a layout with the given number of (not yet
+// created) partitions.
+//
+// Parameters:
+//   - `aCount`: The number of partitions (already clamped).
+//   - `aHasher`: The optional custom hash function (`nil` for the default).
+//   - `aLRULimit`: The max. number of entries per partition (`0` for no limit).
+//
+// Returns:
+//   - `*tLayout[K, V]`: A pointer to a newly created layout.
+func newLayout[K cmp.Ordered, V any](aCount int, aHasher func(K) uint64, aLRULimit int) *tLayout[K, V] {
+	return &tLayout[K, V]{
+		tPartitionList: make(tPartitionList[K, V], aCount),
+		hasher:         aHasher,
+		lruLimit:       aLRULimit,
+	}
+} // newLayout()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` constructor:
+
+// `New()` creates and initialises a new partitioned map instance.
+//
+// This is a constructor function that returns a pointer to a new
+// `TPartitionMap` instance with the specified key and value types.
+//
+// The returned partitioned map is initialised with the predefined number
+// of partitions (128), but the actual partition instances are created
+// lazily when needed.
+//
+// Example usage:
+//
+//	pm := New[string, string]()
+//	pm.Put("key1", "value1")
+//	value, exists := pm.Get("key1")
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func New[K cmp.Ordered, V any]() *TPartitionMap[K, V] {
+	return NewWithPartitions[K, V](numberOfPartitionsInMap)
+} // New()
+
+// `NewWithPartitions()` creates and initialises a new partitioned map
+// instance using the given number of partitions.
+//
+// A small number of partitions reduces the memory overhead of small
+// maps, while a large number of partitions reduces the lock contention
+// in maps with many entries and heavy concurrent write access.
+//
+// The given number is clamped to the range `1` to `65536`.
+//
+// Example usage:
+//
+//	pm := NewWithPartitions[string, string](16)
+//
+// Parameters:
+//   - `aCount`: The number of partitions to use.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewWithPartitions[K cmp.Ordered, V any](aCount int) *TPartitionMap[K, V] {
+	aCount = min(max(aCount, minPartitionsInMap), maxPartitionsInMap)
+
+	// Unfortunately, Go doesn't support the use of sparse arrays
+	// (i.e. slices). That forces us to initialise the whole list
+	// at once. With the default of 128 indices that takes 1024 bytes.
+	//
+	// An empty map is allocated with enough space to hold the
+	// specified number of elements.
+	result := &TPartitionMap[K, V]{}
+	result.layout.Store(newLayout[K, V](aCount, nil, 0))
+
+	// Leave the partition instances to lazy/late initialisation;
+	// see `TPartitionMap.partition()`.
+
+	return result
+} // NewWithPartitions()
+
+// `NewWithCapacity()` creates and initialises a new partitioned map
+// instance prepared to hold (about) the given number of entries.
+//
+// The capacity hint is the expected total size of the map; it is
+// divided evenly across all partitions. Whenever a partition is
+// (lazily) created its key/value store is allocated with room for
+// its share of the entries, thus avoiding repeated re-hashing during
+// bulk loads. Other than that the map behaves like one created by
+// `New()`.
+//
+// Example usage:
+//
+//	pm := NewWithCapacity[string, string](1_000_000)
+//
+// Parameters:
+//   - `aHint`: The expected total number of entries.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewWithCapacity[K cmp.Ordered, V any](aHint int) *TPartitionMap[K, V] {
+	result := New[K, V]()
+	result.capacity = max(aHint, 0)
+
+	return result
+} // NewWithCapacity()
+
+// `NewWithHasher()` creates and initialises a new partitioned map
+// instance using the given hash function to assign keys to partitions.
+//
+// The key's partition index is the hash value modulo the number of
+// partitions. This allows for domain-specific hashing of keyspaces
+// which the built-in hashing would distribute badly.
+// If `aHasher` is `nil` the map behaves like one created by `New()`.
+//
+// NOTE: The hash function must be deterministic (i.e. always return
+// the same value for the same key) and safe for concurrent use.
+//
+// Example usage:
+//
+//	// IDs are multiples of 128:
+//	pm := NewWithHasher[int, string](func(aKey int) uint64 {
+//		return uint64(aKey / 128)
+//	})
+//
+// Parameters:
+//   - `aHasher`: The function computing a key's hash value.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewWithHasher[K cmp.Ordered, V any](aHasher func(aKey K) uint64) *TPartitionMap[K, V] {
+	result := New[K, V]()
+	result.layout.Load().hasher = aHasher
+
+	return result
+} // NewWithHasher()
+
+// `NewWithKeyBytes()` creates and initialises a new partitioned map
+// instance serialising its keys for hashing by the given function.
+//
+// Keys of the built-in integer, floating point, and string types are
+// hashed directly. Keys of named types (e.g. `type UserID string`)
+// however are formatted by `fmt` to get the bytes to hash, which is
+// comparatively slow and allocates memory with every access. The
+// given function provides those bytes instead, e.g.
+// `[]byte(string(aKey))` for a named string type.
+//
+// The function must return the same bytes for equal keys (and
+// should return different bytes for different keys), otherwise keys
+// can't be found anymore. It's called for every access of a key, so
+// it should be fast. A `nil` function results in the default
+// serialisation, i.e. the map behaves like one created by `New()`.
+// It's also used by a map's seed or secure hashing (see
+// `NewWithSeed()` and `NewWithSecureHash()`), but not by a custom
+// hash function (see `NewWithHasher()`).
+//
+// Example usage:
+//
+//	type TUserID string
+//	users := NewWithKeyBytes[TUserID, *TUser](func(aKey TUserID) []byte {
+//		return []byte(aKey)
+//	})
+//
+// Parameters:
+//   - `aEncode`: The function returning the bytes to hash for a key.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewWithKeyBytes[K cmp.Ordered, V any](aEncode func(aKey K) []byte) *TPartitionMap[K, V] {
+	result := New[K, V]()
+	result.encode = aEncode
+
+	return result
+} // NewWithKeyBytes()
+
+// `NewWithSecureHash()` creates and initialises a new partitioned
+// map instance using a keyed hash function to assign keys to
+// partitions.
+//
+// The built-in CRC32 hashing is fast, but collisions are trivial to
+// construct: somebody controlling the keys (e.g. user names sent to
+// a server) can force all entries into a single partition, thus
+// serialising all accesses to the map. This constructor uses the
+// hash function of `hash/maphash` with a random seed per map
+// instead, so the placement of string and other non-integer keys
+// can't be predicted from the outside.
+//
+// The keyed hashing is somewhat slower than CRC32 for long keys, and
+// the placement of the keys differs between map instances (and
+// program runs). Non-negative integer keys keep using the fast
+// (unkeyed) integer mixing and are thus *not* protected; use
+// `NewWithSeed()` with a random seed to make their placement less
+// predictable.
+// A custom hash function (see `SetHasher()`) takes precedence over
+// the keyed hashing.
+//
+// Example usage:
+//
+//	sessions := NewWithSecureHash[string, *TSession]()
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewWithSecureHash[K cmp.Ordered, V any]() *TPartitionMap[K, V] {
+	seed := maphash.MakeSeed()
+	result := New[K, V]()
+	result.secure = &seed
+
+	return result
+} // NewWithSecureHash()
+
+// `NewWithSeed()` creates and initialises a new partitioned map
+// instance mixing the given seed into the built-in hashing.
+//
+// The same seed always results in the same placement of the keys,
+// while different seeds place them (pseudo-randomly) differently.
+// This allows e.g. for testing code under different distributions
+// of the keys, or for a per-instance randomisation of the placement
+// of integer keys (whose default placement is trivially predictable).
+// Note that keys colliding under the built-in CRC32 hashing still
+// share a partition whatever the seed.
+// A seed of `0` results in the default placement, i.e. the map
+// behaves like one created by `New()`. A custom hash function (see
+// `NewWithHasher()` and `SetHasher()`) takes precedence over the seed.
+//
+// Example usage:
+//
+//	pm := NewWithSeed[string, int](rand.Uint32())
+//
+// Parameters:
+//   - `aSeed`: The seed to mix into the keys' hash values.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewWithSeed[K cmp.Ordered, V any](aSeed uint32) *TPartitionMap[K, V] {
+	result := New[K, V]()
+	result.seed = aSeed
+
+	return result
+} // NewWithSeed()
+
+// `NewNormalized()` creates and initialises a new partitioned map
+// instance applying the given transformation to every key.
+//
+// The transformation is applied consistently to the keys passed to
+// all methods (e.g. `Put()`, `Get()`, `Delete()`, `RangeKeys()`), so
+// different keys with the same normalised form refer to the same
+// entry. Consequently, methods returning keys (e.g. `Keys()`,
+// `Entries()`, `MinKey()`) return their normalised form.
+// If `aTransform` is `nil` the map behaves like one created by `New()`.
+//
+// NOTE: The transformation must be deterministic and idempotent
+// (i.e. `f(f(k)) == f(k)`) and safe for concurrent use.
+//
+// Example usage:
+//
+//	// case-insensitive user names:
+//	users := NewNormalized[string, *TUser](strings.ToLower)
+//	users.Put("Alice", alice)
+//	u, _ := users.Get("ALICE") // -> alice
+//
+// Parameters:
+//   - `aTransform`: The function normalising the keys.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewNormalized[K cmp.Ordered, V any](aTransform func(aKey K) K) *TPartitionMap[K, V] {
+	result := New[K, V]()
+	result.normalize = aTransform
+
+	return result
+} // NewNormalized()
+
+// `FromMap()` creates a new partitioned map holding all key/value
+// pairs of the given map.
+//
+// The partitions are pre-sized according to the source's size and
+// the pairs are inserted directly into the partitions, bypassing
+// the locking overhead of `Put()`.
+// Later modifications of the source map don't affect the returned
+// partitioned map.
+//
+// Example usage:
+//
+//	pm := FromMap(map[string]int{"one": 1, "two": 2})
+//
+// Parameters:
+//   - `aSource`: The map to copy the key/value pairs from.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func FromMap[K cmp.Ordered, V any](aSource map[K]V) *TPartitionMap[K, V] {
+	result := NewWithCapacity[K, V](len(aSource))
+	list := result.slots()
+	count := len(list)
+
+	// The new map isn't shared yet, so there's no need for locking.
+	for k, v := range aSource {
+		slot := &list[result.index(k)]
+		p := slot.Load()
+		if nil == p {
+			p = newPartition[K, V](result.capacity / count)
+			p.total = &result.total
+			slot.Store(p)
+		}
+		p.kv[k] = v
+	}
+	for _, p := range result.partitions() {
+		if nil != p {
+			p.sync()
+		}
+	}
+
+	return result
+} // FromMap()
+
+// `newEmptyLike()` returns a new, empty partitioned map using the
+// same configuration as the given one.
+//
+// The value type of the new map may differ from the given one's.
+//
+// Parameters:
+//   - `aPM`: The partitioned map whose configuration to use.
+//   - `aCount`: The number of partitions of the new map.
+//
+// Returns:
+//   - `*TPartitionMap[K, W]`: A pointer to a newly created partitioned map.
+func newEmptyLike[K cmp.Ordered, V, W any](aPM *TPartitionMap[K, V], aCount int) *TPartitionMap[K, W] {
+	result := NewWithPartitions[K, W](aCount)
+	result.capacity = aPM.capacity
+	result.layout.Load().hasher = aPM.layout.Load().hasher
+	result.normalize = aPM.normalize
+	result.seed = aPM.seed
+	result.secure = aPM.secure
+	result.encode = aPM.encode
+
+	return result
+} // newEmptyLike()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+var (
+	// `crc32Table`: To avoid (re-)allocation with every call
+	// to `partitionIndex()` we create it here once.
+	gCrc32Table = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// `signedKey()` prepares a signed integer for the partition index
+// computation.
+//
+// Non-negative values are returned as is for the integer mixing (see
+// `mixHash()`). Converting a negative value to `uint64` would yield
+// huge numbers whose modulo covers only a narrow band of partitions,
+// so for those the two's-complement bytes are returned to be hashed
+// instead.
+//
+// Parameters:
+//   - `aVal`: The signed integer key.
+//
+// Returns:
+//   - `uint64`: The value to use for the integer mixing.
+//   - `[]byte`: The bytes to hash for negative values.
+func signedKey(aVal int64) (uint64, []byte) {
+	if 0 <= aVal {
+		return uint64(aVal), nil
+	}
+
+	return 0, binary.LittleEndian.AppendUint64(nil, uint64(aVal)) //#nosec G115
+} // signedKey()
+
+// `keyBytes()` prepares the given key for hashing.
+//
+// Non-negative integer keys are returned as numbers, all other keys
+// as the bytes of their (textual or binary) representation. Keys of
+// named types (e.g. `type ID string`) are serialised by the given
+// function or, if that's `nil`, by `fmt`.
+//
+// Parameters:
+//   - `aKey`: The key to prepare.
+//   - `aEncode`: The optional serialisation of named key types.
+//
+// Returns:
+//   - `uint64`: The numeric value of an integer key.
+//   - `[]byte`: The bytes to hash for all other keys.
+//   - `bool`: Whether the key's bytes need to be hashed.
+func keyBytes[K cmp.Ordered](aKey K, aEncode func(K) []byte) (uint64, []byte, bool) {
+	var (
+		uintKey uint64
+		key     []byte
+		hashKey bool // whether to hash the bytes instead of mixing the number
+	)
+
+	switch val := any(aKey).(type) {
+	case int:
+		uintKey, key = signedKey(int64(val))
+		hashKey = (nil != key)
+	case int8:
+		uintKey, key = signedKey(int64(val))
+		hashKey = (nil != key)
+	case int16:
+		uintKey, key = signedKey(int64(val))
+		hashKey = (nil != key)
+	case int32:
+		uintKey, key = signedKey(int64(val))
+		hashKey = (nil != key)
+	case int64:
+		uintKey, key = signedKey(val)
+		hashKey = (nil != key)
+	case uint:
+		uintKey = uint64(val)
+	case uint8:
+		uintKey = uint64(val)
+	case uint16:
+		uintKey = uint64(val)
+	case uint32:
+		uintKey = uint64(val)
+	case uint64:
+		uintKey = val
+	case uintptr:
+		uintKey = uint64(val)
+	case float32:
+		key, hashKey = []byte(strconv.FormatFloat(float64(val), 'f', -1, 32)), true
+	case float64:
+		key, hashKey = []byte(strconv.FormatFloat(val, 'f', -1, 64)), true
+	case string:
+		key, hashKey = []byte(val), true
+	default:
+		if nil != aEncode {
+			key = aEncode(aKey)
+		} else {
+			key = fmt.Appendf(nil, "%v", aKey)
+		}
+		hashKey = true
+	} // switch
+
+	return uintKey, key, hashKey
+} // keyBytes()
+
+// `keyHash()` computes the default hash value of a given key.
+//
+// Non-negative integer keys are mixed by `mixHash()`, all other keys
+// are hashed using the CRC32 algorithm.
+//
+// Parameters:
+//   - `aKey`: The key for which the hash value is to be computed.
+//   - `aEncode`: The optional serialisation of named key types.
+//
+// Returns:
+//   - `uint64`: The key's hash value.
+func keyHash[K cmp.Ordered](aKey K, aEncode func(K) []byte) uint64 {
+	uintKey, key, hashKey := keyBytes(aKey, aEncode)
+	if !hashKey {
+		// All integer keys (including zero) use the fast path.
+		return mixHash(uintKey)
+	}
+
+	// We use CRC32 for speed and adequate distribution.
+	// While it's not cryptographically secure, it's perfect for our
+	// partitioning needs.
+	// If two different keys hash to the same partition, they'll
+	// simply share a partition.
+
+	return uint64(crc32.Checksum(key, gCrc32Table))
+} // keyHash()
+
+// `mixHash()` scrambles the bits of the given value.
+//
+// Using integer keys directly for the modulo computation would put
+// keys with a common stride (e.g. IDs `0, 128, 256, ...`) into the
+// same partition. The mixing (the finaliser of the SplitMix64
+// generator) is a bijection spreading any change of the input over
+// all bits of the result, so sequential as well as strided keys are
+// distributed evenly. It's fast and doesn't allocate.
+//
+// Parameters:
+//   - `aHash`: The value to mix.
+//
+// Returns:
+//   - `uint64`: The mixed value.
+func mixHash(aHash uint64) uint64 {
+	h := (aHash ^ (aHash >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+
+	return h ^ (h >> 31)
+} // mixHash()
+
+// `partitionIndex()` computes the partition index for a given key.
+// It uses `keyHash()` to generate a hash value for the key, then
+// takes the modulus of the hash value with the number of partitions
+// to obtain the partition index.
+//
+// Parameters:
+//   - `aKey`: The key for which the partition index is to be computed.
+//   - `aCount`: The number of partitions to distribute the keys over.
+//
+// Returns:
+//   - `int`: The partition index to use for the given key.
+func partitionIndex[K cmp.Ordered](aKey K, aCount int) int {
+	return int(keyHash(aKey, nil) % uint64(aCount)) //#nosec G115
+} // partitionIndex()
+
+// `PartitionIndex()` returns the index of the partition the given
+// key is stored in by a map with the given number of partitions.
+//
+// This allows callers to group their keys by partition in advance,
+// e.g. to implement their own batching of bulk operations. The
+// number of partitions is clamped like `NewWithPartitions()` does.
+//
+// NOTE: The result reflects the default placement of keys. It
+// doesn't apply to maps using a custom hash function, a seed, or a
+// secure hash (see `NewWithHasher()`, `NewWithSeed()`, and
+// `NewWithSecureHash()`); use `TPartitionMap.GetWithPartition()`
+// for those. Keys of a map using a key transformation (see
+// `NewNormalized()`) must be normalised before.
+//
+// Example usage:
+//
+//	idx := PartitionIndex("user42", 128)
+//
+// Parameters:
+//   - `aKey`: The key to compute the partition index for.
+//   - `aCount`: The map's number of partitions.
+//
+// Returns:
+//   - `int`: The index of the key's partition.
+func PartitionIndex[K cmp.Ordered](aKey K, aCount int) int {
+	aCount = min(max(aCount, minPartitionsInMap), maxPartitionsInMap)
+
+	return partitionIndex(aKey, aCount)
+} // PartitionIndex()
+
+// `secureHash()` computes a keyed hash value of a given key.
+//
+// Non-negative integer keys are mixed like `keyHash()` does, all
+// other keys are hashed by `hash/maphash` using the given seed.
+//
+// Parameters:
+//   - `aKey`: The key for which the hash value is to be computed.
+//   - `aSeed`: The (random) seed of the hash function.
+//   - `aEncode`: The optional serialisation of named key types.
+//
+// Returns:
+//   - `uint64`: The key's hash value.
+func secureHash[K cmp.Ordered](aKey K, aSeed maphash.Seed, aEncode func(K) []byte) uint64 {
+	if str, ok := any(aKey).(string); ok {
+		return maphash.String(aSeed, str)
+	}
+
+	uintKey, key, hashKey := keyBytes(aKey, aEncode)
+	if !hashKey {
+		return mixHash(uintKey)
+	}
+
+	return maphash.Bytes(aSeed, key)
+} // secureHash()
+
+// `seededHash()` mixes the given seed into the given hash value.
+//
+// The mixing (the finaliser of the SplitMix64 generator) is a
+// bijection of the hash values for any given seed, so keys with
+// different hash values never collide because of the seed, while
+// different seeds result in (pseudo-randomly) different values.
+//
+// Parameters:
+//   - `aHash`: The key's hash value (see `keyHash()`).
+//   - `aSeed`: The seed to mix in.
+//
+// Returns:
+//   - `uint64`: The seeded hash value.
+func seededHash(aHash uint64, aSeed uint32) uint64 {
+	return mixHash(aHash + uint64(aSeed)*0x9e3779b97f4a7c15)
+} // seededHash()
+
+// `eachPartition()` calls the given function for each partition of
+// the map's current layout, e.g. to modify all partitions one after
+// the other.
+//
+// Retired partitions refuse any modification (see `relayout()`), so
+// if the layout is replaced meanwhile, the partitions of the new
+// layout are visited as well.
+//
+// Parameters:
+//   - `aFunc`: The function to call; returning `false` stops the iteration.
+func (pm *TPartitionMap[K, V]) eachPartition(aFunc func(aPartition *tPartition[K, V]) bool) {
+	for layout := pm.layout.Load(); ; {
+		for idx := range layout.tPartitionList {
+			if !aFunc(layout.tPartitionList[idx].Load()) {
+				return
+			}
+		}
+		next := pm.layout.Load()
+		if next == layout {
+			return
+		}
+		layout = next
+	}
+} // eachPartition()
+
+// `groupKeys()` sorts the given (normalised) keys into groups by
+// their partition index.
+//
+// Parameters:
+//   - `aKeys`: The keys to group.
+//
+// Returns:
+//   - `*tLayout[K, V]`: The layout the partition indices refer to.
+//   - `[][]K`: The keys grouped by partition index (`nil` for partitions without keys).
+func (pm *TPartitionMap[K, V]) groupKeys(aKeys []K) (*tLayout[K, V], [][]K) {
+	layout := pm.layout.Load()
+	result := make([][]K, len(layout.tPartitionList))
+	for _, key := range aKeys {
+		key = pm.normKey(key)
+		idx := pm.indexIn(layout, key)
+		result[idx] = append(result[idx], key)
+	}
+
+	return layout, result
+} // groupKeys()
+
+// `index()` returns the index of the partition the given key
+// belongs to in the map's current layout (see `indexIn()`).
+//
+// Parameters:
+//   - `aKey`: The key to compute the partition index for.
+//
+// Returns:
+//   - `int`: The partition index.
+func (pm *TPartitionMap[K, V]) index(aKey K) int {
+	return pm.indexIn(pm.layout.Load(), aKey)
+} // index()
+
+// `indexIn()` returns the index of the partition the given key
+// belongs to in the given layout.
+//
+// If the layout has a custom hash function (see `SetHasher()`) that
+// one is used, otherwise the keyed hash of a secure map (see
+// `NewWithSecureHash()`) or the package's default `PartitionIndex()`
+// (with the map's seed mixed in, if any; see `NewWithSeed()`).
+//
+// Parameters:
+//   - `aLayout`: The layout to compute the partition index for.
+//   - `aKey`: The key to compute the partition index for.
+//
+// Returns:
+//   - `int`: The partition index.
+func (pm *TPartitionMap[K, V]) indexIn(aLayout *tLayout[K, V], aKey K) int {
+	count := len(aLayout.tPartitionList)
+	if nil != aLayout.hasher {
+		return int(aLayout.hasher(aKey) % uint64(count)) //#nosec G115
+	}
+	if nil != pm.secure {
+		return int(secureHash(aKey, *pm.secure, pm.encode) % uint64(count)) //#nosec G115
+	}
+	if 0 != pm.seed {
+		return int(seededHash(keyHash(aKey, pm.encode), pm.seed) % uint64(count)) //#nosec G115
+	}
+	if nil != pm.encode {
+		return int(keyHash(aKey, pm.encode) % uint64(count)) //#nosec G115
+	}
+
+	return PartitionIndex(aKey, count)
+} // indexIn()
+
+// `normKey()` returns the normalised form of the given key.
+//
+// If the map was created with a key transformation (see
+// `NewNormalized()`) that one is applied, otherwise the key is
+// returned unchanged. Every method accepting keys from the caller
+// must normalise them before accessing the partitions.
+//
+// Parameters:
+//   - `aKey`: The key to normalise.
+//
+// Returns:
+//   - `K`: The normalised key.
+func (pm *TPartitionMap[K, V]) normKey(aKey K) K {
+	if nil != pm.normalize {
+		return pm.normalize(aKey)
+	}
+
+	return aKey
+} // normKey()
+
+// `makePartition()` creates a new, empty partition for a slot of the
+// given layout which is configured like the map's other partitions.
+//
+// The partition isn't attached to the map's running total (see
+// `Len()`); that's left to the caller.
+//
+// Parameters:
+//   - `aLayout`: The layout the partition is meant for.
+//
+// Returns:
+//   - `*tPartition[K, V]`: A pointer to a newly created partition.
+func (pm *TPartitionMap[K, V]) makePartition(aLayout *tLayout[K, V]) *tPartition[K, V] {
+	p := newPartition[K, V](pm.capacity / len(aLayout.tPartitionList))
+	p.expiry = pm.expiry
+	if pm.hitStats {
+		p.hits = make(tHitMap[K])
+	}
+	if pm.ordered {
+		p.order = make(map[K]uint64)
+		p.seq = &pm.sequence
+	}
+	if 0 < aLayout.lruLimit {
+		p.lru = newLRU[K](aLayout.lruLimit)
+	}
+
+	return p
+} // makePartition()
+
+// `move()` stores the key/value pairs of the given partitions in the
+// partitions their keys belong to in the given (unpublished) layout.
+//
+// Entries keep their expiry times and insertion sequences. The
+// caller must hold the given partitions' write locks (see
+// `relayout()`).
+//
+// Parameters:
+//   - `aOld`: The partitions to copy the entries from (`nil` for empty slots).
+//   - `aLayout`: The layout to store the entries in.
+//
+// Returns:
+//   - `*tLayout[K, V]`: The given layout.
+func (pm *TPartitionMap[K, V]) move(aOld []*tPartition[K, V], aLayout *tLayout[K, V]) *tLayout[K, V] {
+	for _, p := range aOld {
+		if nil == p {
+			continue
+		}
+		for k, v := range p.kv {
+			target := pm.stage(aLayout, pm.indexIn(aLayout, k))
+			target.putTTL(k, v, p.deadlines[k])
+			target.reorder(k, p.order[k])
+		}
+	}
+
+	return aLayout
+} // move()
+
+// `partition()` retrieves a partition from the partitioned map based
+// on the provided key.
+//
+// If the partition already exists, it is returned along with a boolean
+// value indicating its existence.
+//
+// If the partition doesn't exist yet and the create parameter is set to
+// `true`, a new partition is created and returned.
+//
+// If the partition doesn't exist and the create parameter is set to
+// `false`, the method returns `nil` and a boolean value of `false`.
+//
+// Both, the lookup and the creation are lock-free: the map's current
+// layout and the partition slots are loaded atomically and a new
+// partition is installed by a compare-and-swap on its own slot, so
+// creating one partition never blocks accesses to any other partition.
+// If the layout is replaced meanwhile, the returned partition is
+// retired and passes any request on to its successor (see `lock()`).
+//
+// Parameters:
+//   - `aKey`: The key used to identify the partition.
+//   - `aCreate`: A boolean value indicating whether a new partition for the given key should be created if it doesn't exist yet.
+//
+// Returns:
+//   - `*tPartition[K, V]`: The partition associated with the provided key, or `nil` if the partition does not exist and `aCreate` is `false`.
+//   - `bool`: A boolean value indicating whether the partition was successfully retrieved.
+func (pm *TPartitionMap[K, V]) partition(aKey K, aCreate bool) (*tPartition[K, V], bool) {
+	if nil == pm {
+		return nil, false
+	}
+	layout := pm.layout.Load()
+
+	return pm.partitionAt(layout, pm.indexIn(layout, aKey), aCreate)
+} // partition()
+
+// `partitionAt()` retrieves the partition with the given index of
+// the given layout.
+//
+// This is the index based counterpart of `partition()` for callers
+// that already computed the partition index of their key(s).
+//
+// Parameters:
+//   - `aLayout`: The layout the index refers to.
+//   - `aIdx`: The partition's index (as returned by `indexIn()`).
+//   - `aCreate`: Whether to create the partition if it doesn't exist yet.
+//
+// Returns:
+//   - `*tPartition[K, V]`: The partition with the given index, or `nil` if the partition does not exist and `aCreate` is `false`.
+//   - `bool`: A boolean value indicating whether the partition was successfully retrieved.
+func (pm *TPartitionMap[K, V]) partitionAt(aLayout *tLayout[K, V], aIdx int, aCreate bool) (*tPartition[K, V], bool) {
+	slot := &aLayout.tPartitionList[aIdx]
+
+	if p := slot.Load(); nil != p {
+		return p, true
+	}
+
+	if !aCreate {
+		return nil, false
+	}
+
+	// Here we do the lazy initialisation of the required `tPartition`.
+	// Another goroutine might create the same partition meanwhile in
+	// which case its instance wins and ours is discarded. A retired
+	// layout's empty slots are sealed (see `relayout()`), so the
+	// new partition can't get lost in a layout no longer used.
+	p := pm.makePartition(aLayout)
+	p.total = &pm.total
+	if !slot.CompareAndSwap(nil, p) {
+		p = slot.Load()
+	}
+
+	return p, true
+} // partitionAt()
+
+// `partitions()` returns a copy of the current list of partitions.
+//
+// The slots of the map's current layout are simply loaded atomically
+// without taking the map's lock. Callers rely on the partitions' own
+// locks while accessing them. If the layout is replaced meanwhile,
+// the returned partitions are retired: they keep their final contents
+// but refuse any modification (see `relayout()`).
+// Slots whose partition isn't created yet are `nil`.
+//
+// Returns:
+//   - `[]*tPartition[K, V]`: A copy of the map's list of partitions.
+func (pm *TPartitionMap[K, V]) partitions() []*tPartition[K, V] {
+	list := pm.slots()
+	result := make([]*tPartition[K, V], len(list))
+	for idx := range list {
+		result[idx] = list[idx].Load()
+	}
+
+	return result
+} // partitions()
+
+// `relayout()` replaces the map's layout by the one returned from
+// the given function.
+//
+// First all partitions of the current layout are write-locked and
+// its empty slots are sealed, so no partition can be created there
+// anymore. Then the function moves the locked partitions' entries
+// into a new layout, which is published by a single atomic swap
+// together with the adjustment of the map's running total. Finally
+// the old partitions are retired and unlocked: key based methods
+// which were waiting for their locks pass their requests on to the
+// new layout (see `tPartition.lock()`), while readers still holding
+// the old layout see its final contents.
+//
+// The function must store the entries in partitions created by
+// `stage()`. The caller must hold the map's write lock.
+//
+// Parameters:
+//   - `aBuild`: The function building the new layout from the old partitions (`nil` for empty slots).
+//
+// Returns:
+//   - `*tLayout[K, V]`: The new (published) layout.
+func (pm *TPartitionMap[K, V]) relayout(aBuild func(aOld []*tPartition[K, V]) *tLayout[K, V]) *tLayout[K, V] {
+	old := pm.layout.Load()
+	list := make([]*tPartition[K, V], len(old.tPartitionList))
+	seal := pm.seal()
+	for idx := range old.tPartitionList {
+		slot := &old.tPartitionList[idx]
+		if !slot.CompareAndSwap(nil, seal) {
+			list[idx] = slot.Load()
+			list[idx].Lock()
+		}
+	}
+
+	layout := aBuild(list)
+
+	// The running total is adjusted by the difference only, so the
+	// concurrent modifications of other partitions aren't lost.
+	var delta int64
+	for idx := range layout.tPartitionList {
+		if p := layout.tPartitionList[idx].Load(); nil != p {
+			p.total = &pm.total
+			delta += p.size.Load()
+		}
+	}
+	for _, p := range list {
+		if nil != p {
+			delta -= p.size.Load()
+		}
+	}
+	pm.total.Add(delta)
+	pm.layout.Store(layout)
+	seal.Unlock()
+
+	for _, p := range list {
+		if nil != p {
+			p.moved = pm
+			p.Unlock()
+		}
+	}
+
+	return layout
+} // relayout()
+
+// `resize()` moves all key/value pairs into a new layout with the
+// given number of partitions (see `Resize()`).
+//
+// The caller must hold the map's write lock.
+//
+// Parameters:
+//   - `aCount`: The new number of partitions (already clamped).
+func (pm *TPartitionMap[K, V]) resize(aCount int) {
+	old := pm.layout.Load()
+	if len(old.tPartitionList) == aCount {
+		return
+	}
+	lruLimit := old.lruLimit
+	if 0 < lruLimit {
+		lruLimit = max(lruLimit*len(old.tPartitionList)/aCount, 1)
+	}
+
+	layout := pm.relayout(func(aOld []*tPartition[K, V]) *tLayout[K, V] {
+		return pm.move(aOld, newLayout[K, V](aCount, old.hasher, lruLimit))
+	})
+	for idx := range layout.tPartitionList {
+		pm.evict(layout.tPartitionList[idx].Load())
+	}
+} // resize()
+
+// `seal()` returns a write-locked placeholder for the empty slots of
+// a layout about to be replaced (see `relayout()`).
+//
+// Since the placeholder counts as retired, requests for the slots'
+// keys wait for its lock and are then passed on to the new layout.
+// The caller must unlock it once the new layout is published.
+//
+// Returns:
+//   - `*tPartition[K, V]`: The locked placeholder partition.
+func (pm *TPartitionMap[K, V]) seal() *tPartition[K, V] {
+	result := &tPartition[K, V]{moved: pm}
+	result.Lock()
+
+	return result
+} // seal()
+
+// `slots()` returns the partition slots of the map's current layout.
+//
+// Returns:
+//   - `tPartitionList[K, V]`: The current layout's list of partitions.
+func (pm *TPartitionMap[K, V]) slots() tPartitionList[K, V] {
+	return pm.layout.Load().tPartitionList
+} // slots()
+
+// `stage()` returns the partition with the given index of a layout
+// which isn't published yet (see `relayout()`), creating it if needed.
+//
+// The partition isn't attached to the map's running total before the
+// layout is published, so filling it doesn't affect `Len()`.
+//
+// Parameters:
+//   - `aLayout`: The unpublished layout.
+//   - `aIdx`: The partition's index (as returned by `indexIn()`).
+//
+// Returns:
+//   - `*tPartition[K, V]`: The partition with the given index.
+func (pm *TPartitionMap[K, V]) stage(aLayout *tLayout[K, V], aIdx int) *tPartition[K, V] {
+	slot := &aLayout.tPartitionList[aIdx]
+	p := slot.Load()
+	if nil == p {
+		p = pm.makePartition(aLayout)
+		slot.Store(p)
+	}
+
+	return p
+} // stage()
+
+//
+// CRUD interface
+//
+// `C`: create == Put()
+// `R`: read == Get()
+// `U`: update == Put()
+// `D`: delete == Delete()
+//
+
+// `AllMatch()` reports whether the given predicate returns `true`
+// for all key/value pairs in the partitioned map.
+//
+// The partitions are checked one after the other, each under its
+// read lock, and the check stops at the first non-matching pair.
+// For an empty map (or a `nil` predicate) the result is `true`.
+//
+// NOTE: The predicate is executed while holding a partition's read
+// lock. It must therefore not call any methods of the partitioned
+// map, otherwise a deadlock may occur.
+//
+// Parameters:
+//   - `aPred`: The predicate to check.
+//
+// Returns:
+//   - `bool`: `true` if no pair fails the predicate.
+func (pm *TPartitionMap[K, V]) AllMatch(aPred func(aKey K, aValue V) bool) bool {
+	if (nil == pm) || (nil == aPred) {
+		return true
+	}
+
+	return !pm.AnyMatch(func(aKey K, aValue V) bool {
+		return !aPred(aKey, aValue)
+	})
+} // AllMatch()
+
+// `AnyMatch()` reports whether the given predicate returns `true`
+// for any key/value pair in the partitioned map.
+//
+// The partitions are checked one after the other, each under its
+// read lock, and the check stops at the first matching pair.
+// For an empty map (or a `nil` predicate) the result is `false`.
+//
+// NOTE: The predicate is executed while holding a partition's read
+// lock. It must therefore not call any methods of the partitioned
+// map, otherwise a deadlock may occur.
+//
+// Parameters:
+//   - `aPred`: The predicate to check.
+//
+// Returns:
+//   - `bool`: `true` if at least one pair matches the predicate.
+func (pm *TPartitionMap[K, V]) AnyMatch(aPred func(aKey K, aValue V) bool) bool {
+	if (nil == pm) || (nil == aPred) {
+		return false
+	}
+
+	for _, p := range pm.partitions() {
+		if p.anyMatch(aPred) {
+			return true
+		}
+	}
+
+	return false
+} // AnyMatch()
+
+// `ApproxLen()` returns an estimate of the number of key/value pairs
+// in the partitioned map.
+//
+// The partitions' live sizes (which are atomic counters updated by
+// every modification) are summed up without acquiring any lock. While
+// other goroutines modify the map, the result may thus be slightly
+// stale or mix sizes from before and after a modification; without
+// concurrent modifications it's exact and equals `Len()`.
+// This allows e.g. a metrics scraper to poll the map's size at a high
+// frequency without contending with other accesses.
+//
+// Returns:
+//   - `int`: The approximate number of key/value pairs in the map.
+func (pm *TPartitionMap[K, V]) ApproxLen() (rLen int) {
+	if nil == pm {
+		return
+	}
+
+	for _, p := range pm.partitions() {
+		rLen += p.len()
+	}
+
+	return
+} // ApproxLen()
+
+// `BusiestPartition()` returns the index and the keys of the
+// partition holding the most key/value pairs.
+//
+// This helps to diagnose a skewed distribution of the keys (see
+// `PartitionStats()` for an overview). The partitions' sizes are
+// read without locking; only the busiest partition is read-locked
+// while its keys are copied. If several partitions hold the same
+// number of pairs, the one with the lowest index wins.
+//
+// Example usage:
+//
+//	if idx, keys := pm.BusiestPartition(); 0 <= idx {
+//		log.Printf("partition %d holds %d keys", idx, len(keys))
+//	}
+//
+// Returns:
+//   - `int`: The index of the busiest partition (`-1` for an empty map).
+//   - `[]K`: The sorted keys of the busiest partition.
+func (pm *TPartitionMap[K, V]) BusiestPartition() (int, []K) {
+	if nil == pm {
+		return -1, nil
+	}
+
+	list := pm.partitions()
+	busiest, most := -1, 0
+	for idx, p := range list {
+		if pLen := p.len(); pLen > most {
+			busiest, most = idx, pLen
+		}
+	}
+	if 0 > busiest {
+		return -1, nil
+	}
+
+	return busiest, list[busiest].keys()
+} // BusiestPartition()
+
+// `Clear()` removes all key/value pairs from the partitioned map.
+//
+// The emptied partitions keep their allocated storage, so refilling
+// the map is fast; see `Reset()` for releasing the memory instead.
+//
+// The partitions are cleared one after the other, each under its
+// own write lock only, so accesses to the other partitions proceed
+// meanwhile. Hence the clearing isn't atomic across partitions: a
+// concurrent reader may see a partly cleared map, and pairs stored
+// concurrently in an already cleared partition are kept. Use
+// `SetAll()` with an empty map for an atomic replacement.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Clear() *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	pm.eachPartition(func(aPartition *tPartition[K, V]) bool {
+		aPartition.clear()
+		return true
+	})
+	pm.notifyClear()
+
+	return pm
+} // Clear()
+
+// `Clone()` returns an independent copy of the partitioned map.
+//
+// The copy uses the same partition layout as the original and holds
+// a shallow copy of all key/value pairs: the new keys and values are
+// set using ordinary assignment (like `maps.Clone()` does).
+// Afterwards, changes to the copy don't affect the original and
+// vice versa.
+//
+// Each partition is read-locked only while it's being copied, hence
+// the clone is a point-in-time snapshot per partition which can be
+// used e.g. for lengthy iterations without blocking any writers.
+// For a copy consistent across all partitions use `Snapshot()`.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A copy of the partitioned map.
+func (pm *TPartitionMap[K, V]) Clone() *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	list := pm.partitions()
+	result := newEmptyLike[K, V, V](pm, len(list))
+
+	for idx, p := range list {
+		if nil != p {
+			result.slots()[idx].Store(wrapPartition(p.clone(), &result.total))
+		}
+	}
+
+	return result
+} // Clone()
+
+// `CloneFunc()` returns an independent copy of the partitioned map
+// with each value copied by the given function.
+//
+// Other than `Clone()`, which shares pointers, slices, and maps held
+// by the values between the original and the copy, this allows for
+// a deep copy of such values. Keys are copied by assignment.
+//
+// Like `Clone()` each partition is read-locked only while it's being
+// copied; the function is called afterwards without holding any
+// locks. If `aCopy` is `nil`, the result equals that of `Clone()`.
+//
+// Example usage:
+//
+//	backup := pm.CloneFunc(slices.Clone[[]string])
+//
+// Parameters:
+//   - `aCopy`: The function returning a copy of the given value.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A copy of the partitioned map.
+func (pm *TPartitionMap[K, V]) CloneFunc(aCopy func(aValue V) V) *TPartitionMap[K, V] {
+	if (nil == pm) || (nil == aCopy) {
+		return pm.Clone()
+	}
+
+	list := pm.partitions()
+	result := newEmptyLike[K, V, V](pm, len(list))
+
+	for idx, p := range list {
+		if nil == p {
+			continue
+		}
+		kv := p.clone()
+		for k, v := range kv {
+			kv[k] = aCopy(v)
+		}
+		result.slots()[idx].Store(wrapPartition(kv, &result.total))
+	}
+
+	return result
+} // CloneFunc()
+
+// `CompactSparse()` reduces the map to a single partition while it
+// holds only few entries, and restores its previous number of
+// partitions once it has grown again.
+//
+// With only a few entries per partition, the partitions' mutexes and
+// maps cost more memory (and time when iterating) than they save by
+// reducing lock contention. Calling this method periodically (e.g.
+// from a `time.Ticker` loop) adapts the map's layout to its size:
+//
+//   - If the map holds fewer than `aThreshold` entries, all of them
+//     are moved into a single partition. The previous number of
+//     partitions is remembered.
+//   - If a compacted map holds `2 * aThreshold` or more entries, the
+//     remembered number of partitions is restored (see `Resize()`).
+//
+// Between both thresholds nothing is done. This hysteresis ensures
+// that a map whose size fluctuates around `aThreshold` isn't
+// compacted and expanded again on each call. A threshold of `0` (or
+// less) never compacts but restores a compacted map.
+// A single-partition map is never compacted, and calling `Resize()`
+// discards the remembered number of partitions.
+//
+// The whole operation is done under the map's write lock, hence it's
+// serialised with other whole-map operations like `SetAll()` or
+// `Snapshot()`. Like `Resize()` it publishes the new list of
+// partitions by a single atomic swap, so key based methods like
+// `Put()` or `Get()` may keep running while a background ticker
+// calls this method.
+//
+// Example usage:
+//
+//	pm.CompactSparse(1024) // single partition below 1024 entries
+//
+// Parameters:
+//   - `aThreshold`: The number of entries below which to compact.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) CompactSparse(aThreshold int) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	pm.Lock()
+	defer pm.Unlock()
+
+	size := int(pm.total.Load())
+	if 0 == pm.sparse {
+		// Not compacted (yet).
+		if count := len(pm.slots()); (1 < count) && (size < aThreshold) {
+			pm.sparse = count
+			pm.resize(minPartitionsInMap)
+		}
+	} else if size >= 2*aThreshold {
+		pm.resize(pm.sparse)
+		pm.sparse = 0
+	}
+
+	return pm
+} // CompactSparse()
+
+// `Compute()` atomically updates the value associated with the
+// given key.
+//
+// The given function is called with the current value (or the zero
+// value of `V`) and a flag indicating whether the key exists. It
+// returns the new value and a flag indicating whether the key
+// should be deleted instead. This allows for read-modify-write
+// operations (like incrementing a counter or appending to a slice)
+// without the race between a separate `Get()` and `Put()`.
+//
+// NOTE: The function is executed while holding the write lock of
+// the key's partition. It must therefore not call any methods of
+// the partitioned map, otherwise a deadlock may occur.
+//
+// Example usage:
+//
+//	pm.Compute("counter", func(aOld int, aFound bool) (int, bool) {
+//		return aOld + 1, false
+//	})
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to update.
+//   - `aFunc`: The function computing the new value.
+//
+// Returns:
+//   - `V`: The resulting value associated with the key.
+//   - `bool`: Indicating whether the key exists after the update.
+func (pm *TPartitionMap[K, V]) Compute(aKey K, aFunc func(aOld V, aFound bool) (V, bool)) (V, bool) {
+	if (nil == pm) || (nil == aFunc) {
+		var zeroVal V
+		return zeroVal, false
+	}
+
+	aKey = pm.normKey(aKey)
+	p, _ := pm.partition(aKey, true)
+
+	val, ok, removed, written := p.compute(aKey, aFunc)
+	if ok {
+		pm.evict(written)
+		pm.notifyPut(aKey, val)
+	} else if removed {
+		pm.notifyDelete(aKey)
+	}
+
+	return val, ok
+} // Compute()
+
+// `Count()` returns the number of key/value pairs for which the given
+// predicate returns `true`.
+//
+// The partitions are processed one after the other, each under its
+// read lock, without collecting any of the pairs.
+// A `nil` predicate counts all pairs, i.e. it's equivalent to `Len()`.
+//
+// NOTE: The predicate is executed while holding a partition's read
+// lock. It must therefore not call any methods of the partitioned
+// map, otherwise a deadlock may occur.
+//
+// Parameters:
+//   - `aPred`: The predicate deciding which pairs to count.
+//
+// Returns:
+//   - `int`: The number of matching key/value pairs.
+func (pm *TPartitionMap[K, V]) Count(aPred func(aKey K, aValue V) bool) (rCount int) {
+	if nil == pm {
+		return
+	}
+	if nil == aPred {
+		return pm.Len()
+	}
+
+	for _, p := range pm.partitions() {
+		rCount += p.count(aPred)
+	}
+
+	return
+} // Count()
+
+// `Delete()` removes a key/value pair from the partitioned map.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to be deleted.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Delete(aKey K) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	aKey = pm.normKey(aKey)
+	if p, ok := pm.partition(aKey, false); ok && p.del(aKey) {
+		pm.notifyDelete(aKey)
+	}
+
+	return pm
+} // Delete()
+
+// `DeleteAll()` removes the key/value pairs with the given keys from
+// the partitioned map.
+//
+// The keys are grouped by their partition first, then each affected
+// partition is write-locked only once to remove its whole group.
+// Keys not present in the map are skipped silently.
+//
+// Parameters:
+//   - `aKeys`: The keys of the key/value pairs to be deleted.
+//
+// Returns:
+//   - `int`: The total number of key/value pairs actually removed.
+func (pm *TPartitionMap[K, V]) DeleteAll(aKeys []K) (rCount int) {
+	if (nil == pm) || (0 == len(aKeys)) {
+		return
+	}
+
+	layout, groups := pm.groupKeys(aKeys)
+	for idx, group := range groups {
+		if 0 == len(group) {
+			continue
+		}
+		if p, ok := pm.partitionAt(layout, idx, false); ok {
+			removed := p.delAll(group)
+			rCount += len(removed)
+			for _, key := range removed {
+				pm.notifyDelete(key)
+			}
+		}
+	}
+
+	return
+} // DeleteAll()
+
+// `DeleteIf()` removes all key/value pairs for which the given
+// predicate returns `true`.
+//
+// Each partition is processed under its write lock, i.e. the
+// predicate's evaluation and the removal of the matching pairs
+// happen atomically per partition. This avoids the races a loop
+// over `Keys()` calling `Delete()` would have.
+//
+// NOTE: The predicate is executed while holding a partition's write
+// lock. It must therefore not call any methods of the partitioned
+// map, otherwise a deadlock may occur.
+//
+// Parameters:
+//   - `aPred`: The predicate deciding which pairs to remove.
+//
+// Returns:
+//   - `int`: The total number of removed key/value pairs.
+func (pm *TPartitionMap[K, V]) DeleteIf(aPred func(aKey K, aValue V) bool) (rCount int) {
+	if (nil == pm) || (nil == aPred) {
+		return
+	}
+
+	pm.eachPartition(func(aPartition *tPartition[K, V]) bool {
+		removed := aPartition.deleteIf(aPred)
+		rCount += len(removed)
+		for _, key := range removed {
+			pm.notifyDelete(key)
+		}
+		return true
+	})
+
+	return
+} // DeleteIf()
+
+// `Drain()` removes all key/value pairs from the partitioned map and
+// returns them.
+//
+// Each partition is emptied in a single step under its write lock,
+// hence every pair written concurrently by other goroutines is either
+// part of the returned map or remains in the partitioned map, but
+// never both and never none.
+//
+// Example usage:
+//
+//	// graceful shutdown: process each pending entry exactly once
+//	for key, value := range pm.Drain() {
+//		process(key, value)
+//	}
+//
+// Returns:
+//   - `map[K]V`: All key/value pairs removed from the partitioned map.
+func (pm *TPartitionMap[K, V]) Drain() map[K]V {
+	if nil == pm {
+		return nil
+	}
+
+	result := make(map[K]V)
+	pm.eachPartition(func(aPartition *tPartition[K, V]) bool {
+		maps.Copy(result, aPartition.drain())
+		return true
+	})
+	pm.notifyClear()
+
+	return result
+} // Drain()
+
+// `Entries()` returns a slice of all key/value pairs in the
+// partitioned map.
+//
+// The keys and values of each partition are collected together under
+// the partition's read lock, so each returned key is guaranteed to be
+// paired with the value it was associated with at that moment, even
+// if other goroutines modify the map concurrently.
+//
+// The returned pairs are sorted by their keys in ascending order.
+//
+// Returns:
+//   - `[]TPair[K, V]`: A slice of all key/value pairs in the partitioned map.
+func (pm *TPartitionMap[K, V]) Entries() []TPair[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	result := make([]TPair[K, V], 0, pm.Len())
+	for _, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		for k, v := range p.clone() {
+			result = append(result, TPair[K, V]{Key: k, Value: v})
+		}
+	}
+
+	slices.SortFunc(result, func(a, b TPair[K, V]) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return result
+} // Entries()
+
+// `EstimatedBytes()` returns a rough estimate of the heap memory
+// used by the partitioned map.
+//
+// The estimate sums up the map's list of partition slots, the
+// overhead of each allocated partition (mutex and map header), and
+// for each entry the (fixed) sizes of its key and value plus the
+// map's per-entry overhead. If the key or value type is a string
+// type, the lengths of the strings are added as well; memory
+// referenced by other types (e.g. slices, pointers) is not taken
+// into account.
+//
+// NOTE: The result is an estimate only, useful e.g. for capacity
+// planning; the actual memory usage depends on Go's map
+// implementation and the allocator.
+//
+// Returns:
+//   - `int64`: The estimated number of bytes.
+func (pm *TPartitionMap[K, V]) EstimatedBytes() (rBytes int64) {
+	if nil == pm {
+		return
+	}
+
+	keyStr := reflect.String == reflect.TypeFor[K]().Kind()
+	valStr := reflect.String == reflect.TypeFor[V]().Kind()
+
+	list := pm.slots()
+	rBytes = int64(unsafe.Sizeof(*pm)) + int64(unsafe.Sizeof(tLayout[K, V]{})) +
+		int64(len(list))*int64(unsafe.Sizeof(list[0]))
+	for _, p := range pm.partitions() {
+		rBytes += p.estimatedBytes(keyStr, valStr)
+	}
+
+	return
+} // EstimatedBytes()
+
+// `Filter()` returns a new partitioned map holding only those
+// key/value pairs for which the given predicate returns `true`.
+//
+// The current map remains untouched. Its partitions are read-locked
+// only while they are copied, and the predicate is called on those
+// snapshots, i.e. without holding any locks.
+// The returned map uses the same partition layout as the current one.
+//
+// Parameters:
+//   - `aPred`: The predicate deciding which pairs to keep.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A new map with the matching pairs.
+func (pm *TPartitionMap[K, V]) Filter(aPred func(aKey K, aValue V) bool) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	list := pm.partitions()
+	result := newEmptyLike[K, V, V](pm, len(list))
+	if nil == aPred {
+		return result
+	}
+
+	for idx, p := range list {
+		if nil == p {
+			continue
+		}
+		kv := p.clone()
+		maps.DeleteFunc(kv, func(aKey K, aValue V) bool {
+			return !aPred(aKey, aValue)
+		})
+		if 0 < len(kv) {
+			result.slots()[idx].Store(wrapPartition(kv, &result.total))
+		}
+	}
+
+	return result
+} // Filter()
+
+// `ForEach()` executes the provided function for each key/value pair
+// in the partitioned map.
+//
+// Parameters:
+//   - `aFunc`: The function to execute for each key/value pair.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) ForEach(aFunc func(aKey K, aValue V)) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	for _, p := range pm.partitions() {
+		p.forEach(aFunc)
+	}
+
+	return pm
+} // ForEach()
+
+// `Generation()` returns the map's generation, i.e. a counter
+// advanced by every modification of the partitioned map.
+//
+// This allows for cheaply detecting whether a cached result (e.g. of
+// `Keys()`) is still up to date: remember the generation before
+// computing the result and compare it with the current generation
+// before using the result again. The counter is advanced after each
+// modification took effect, so a result taken concurrently with a
+// modification is reported as outdated rather than missing it.
+//
+// Example usage:
+//
+//	gen := pm.Generation()
+//	keys := pm.Keys()
+//	// ...
+//	if gen != pm.Generation() {
+//		gen, keys = pm.Generation(), pm.Keys()
+//	}
+//
+// Returns:
+//   - `uint64`: The current generation.
+func (pm *TPartitionMap[K, V]) Generation() uint64 {
+	if nil == pm {
+		return 0
+	}
+
+	return pm.generation.Load()
+} // Generation()
+
+// `Get()` retrieves a key/value pair from the partitioned map.
+//
+// If the partitioned map contains a key/value pair with the specified key,
+// the function returns the associated value and a boolean value indicating
+// whether the key was found. If the key is not found, the function returns
+// the zero value of type V and a boolean value of false.
+//
+// An expired entry (see `NewWithTTL()`) is treated as absent and
+// removed from the map.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to be retrieved.
+//
+// Returns:
+//   - `V`: The value associated with the key (if found).
+//   - `bool`: Indicating for whether the key was found.
+func (pm *TPartitionMap[K, V]) Get(aKey K) (V, bool) {
+	var zeroVal V
+	if nil == pm {
+		return zeroVal, false
+	}
+
+	aKey = pm.normKey(aKey)
+	if p, ok := pm.partition(aKey, false); ok {
+		val, found, expired := p.get(aKey)
+		if expired && p.expire(aKey) {
+			pm.notifyDelete(aKey)
+		}
+		return val, found
+	}
+
+	return zeroVal, false
+} // Get()
+
+// `GetAndDelete()` retrieves and removes a key/value pair from the
+// partitioned map in one atomic step.
+//
+// Other than a call to `Get()` followed by a call to `Delete()` this
+// guarantees that only one of several concurrent callers receives
+// the value of a given key.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to be removed.
+//
+// Returns:
+//   - `V`: The removed value (if found).
+//   - `bool`: Indicating whether the key was found.
+func (pm *TPartitionMap[K, V]) GetAndDelete(aKey K) (V, bool) {
+	if nil != pm {
+		aKey = pm.normKey(aKey)
+		if p, ok := pm.partition(aKey, false); ok {
+			val, found := p.getAndDelete(aKey)
+			if found {
+				pm.notifyDelete(aKey)
+			}
+			return val, found
+		}
+	}
+
+	var zeroVal V
+	return zeroVal, false
+} // GetAndDelete()
+
+// `GetOrCompute()` returns the value associated with the given key,
+// computing and storing it if the key is not present.
+//
+// The given function is called at most once per missing key, even
+// if several goroutines ask for the same key concurrently: the first
+// caller computes the value while the others wait for its result.
+// This makes it suitable for caching values which are expensive to
+// compute.
+//
+// The function is executed without holding any lock, so it may take
+// its time and may even access the partitioned map (other than
+// asking for the same key again, which would deadlock).
+// If the function panics, nothing is stored and the waiting callers
+// try to compute the value themselves.
+//
+// Example usage:
+//
+//	page := pages.GetOrCompute(url, func(aURL string) []byte {
+//		return render(aURL)
+//	})
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//   - `aCompute`: The function computing a missing value.
+//
+// Returns:
+//   - `V`: The existing or computed value.
+func (pm *TPartitionMap[K, V]) GetOrCompute(aKey K, aCompute func(aKey K) V) V {
+	var zeroVal V
+	if (nil == pm) || (nil == aCompute) {
+		return zeroVal
+	}
+
+	aKey = pm.normKey(aKey)
+	p, _ := pm.partition(aKey, true)
+
+	val, computed, written := p.getOrCompute(aKey, aCompute)
+	if computed {
+		pm.evict(written)
+		pm.notifyPut(aKey, val)
+	}
+
+	return val
+} // GetOrCompute()
+
+// `GetMany()` retrieves the values associated with the given keys.
+//
+// The keys are grouped by their partition first, then each affected
+// partition is read-locked only once to look up its whole group.
+//
+// Parameters:
+//   - `aKeys`: The keys to look up.
+//
+// Returns:
+//   - `map[K]V`: The found key/value pairs; keys not present in the partitioned map are absent.
+func (pm *TPartitionMap[K, V]) GetMany(aKeys []K) map[K]V {
+	if (nil == pm) || (0 == len(aKeys)) {
+		return map[K]V{}
+	}
+
+	result := make(map[K]V, len(aKeys))
+	layout, groups := pm.groupKeys(aKeys)
+	for idx, group := range groups {
+		if 0 == len(group) {
+			continue
+		}
+		if p, ok := pm.partitionAt(layout, idx, false); ok {
+			p.getMany(group, result)
+		}
+	}
+
+	return result
+} // GetMany()
+
+// `GetManyPartitioned()` retrieves the values associated with the
+// given keys, reporting the keys not found as well.
+//
+// Like `GetMany()` the lookups are grouped by partition. The keys
+// not present in the map (or expired) are returned sorted in
+// ascending order and without duplicates, e.g. to fetch them from a
+// backing store in a read-through cache:
+//
+//	found, missing := cache.GetManyPartitioned(ids)
+//	for id, item := range loadItems(missing) {
+//		cache.Put(id, item)
+//		found[id] = item
+//	}
+//
+// Parameters:
+//   - `aKeys`: The keys to look up.
+//
+// Returns:
+//   - `map[K]V`: The found key/value pairs.
+//   - `[]K`: The sorted keys not present in the partitioned map.
+func (pm *TPartitionMap[K, V]) GetManyPartitioned(aKeys []K) (rFound map[K]V, rMissing []K) {
+	rFound = pm.GetMany(aKeys)
+	for _, key := range aKeys {
+		if nil != pm {
+			key = pm.normKey(key)
+		}
+		if _, ok := rFound[key]; !ok {
+			rMissing = append(rMissing, key)
+		}
+	}
+	slices.Sort(rMissing)
+
+	return rFound, slices.Compact(rMissing)
+} // GetManyPartitioned()
+
+// `GetOrDefault()` retrieves a value for the given key, or returns
+// the given default value if the key doesn't exist in the partitioned map.
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//   - `aDefault`: The default value to return if the key is not found.
+//
+// Returns:
+//   - `V`: The value associated with `aKey`, or `aDefault` if not found.
+func (pm *TPartitionMap[K, V]) GetOrDefault(aKey K, aDefault V) V {
+	if nil != pm {
+		if result, ok := pm.Get(aKey); ok {
+			return result
+		}
+	}
+
+	return aDefault
+} // GetOrDefault()
+
+// `GetWithPartition()` retrieves the value associated with the given
+// key along with the index of the partition the key belongs to.
+//
+// Other than that it behaves like `Get()`. The index is returned
+// whether the key is present or not, so it can be used to group
+// keys by their partitions (e.g. for locality-aware processing).
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//
+// Returns:
+//   - `V`: The value associated with the key (if found).
+//   - `int`: The index of the key's partition (`-1` for a `nil` map).
+//   - `bool`: Indicating whether the key was found.
+func (pm *TPartitionMap[K, V]) GetWithPartition(aKey K) (V, int, bool) {
+	var zeroVal V
+	if nil == pm {
+		return zeroVal, -1, false
+	}
+
+	aKey = pm.normKey(aKey)
+	layout := pm.layout.Load()
+	idx := pm.indexIn(layout, aKey)
+	if p, ok := pm.partitionAt(layout, idx, false); ok {
+		val, found, expired := p.get(aKey)
+		if expired && p.expire(aKey) {
+			pm.notifyDelete(aKey)
+		}
+		return val, idx, found
+	}
+
+	return zeroVal, idx, false
+} // GetWithPartition()
+
+// `Has()` reports whether the partitioned map contains the given key.
+//
+// Other than `Get()` this method doesn't mark the key as used in an
+// LRU map (see `NewLRU()`). An expired entry (see `NewWithTTL()`) is
+// treated as absent.
+//
+// Parameters:
+//   - `aKey`: The key to look for.
+//
+// Returns:
+//   - `bool`: `true` if the key is present.
+func (pm *TPartitionMap[K, V]) Has(aKey K) bool {
+	if nil == pm {
+		return false
+	}
+
+	aKey = pm.normKey(aKey)
+	p, ok := pm.partition(aKey, false)
+
+	return ok && p.has(aKey)
+} // Has()
+
+// `Intersection()` returns a new partitioned map holding those
+// key/value pairs of the current map whose keys are present in the
+// given map as well.
+//
+// Both maps are snapshotted (see `Snapshot()`) one after the other,
+// so no locks of both maps are held at the same time and the result
+// is computed without holding any locks.
+// The values are taken from the current map; the returned map uses
+// its partition layout. A `nil` map given is treated like an empty
+// one.
+//
+// Example usage:
+//
+//	// the sessions of all users currently online:
+//	active := sessions.Intersection(online)
+//
+// Parameters:
+//   - `aOther`: The partitioned map whose keys to keep.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A new map with the common keys.
+func (pm *TPartitionMap[K, V]) Intersection(aOther *TPartitionMap[K, V]) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	snap := pm.Snapshot()
+	other := aOther.Snapshot()
+
+	return snap.Filter(func(aKey K, _ V) bool {
+		return other.Has(aKey)
+	})
+} // Intersection()
+
+// `Keys()` returns a slice of all keys in the partitioned map.
+//
+// The partitioned map is divided into multiple partitions, each holding
+// a subset of the keys. This method retrieves the (sorted) keys from
+// all partitions and merges them into a sorted slice.
+//
+// The returned slice is a copy of the keys from all partitions, sorted
+// in ascending order.
+//
+// Returns:
+//   - `[]K`: A slice of all the keys in the current partitioned map.
+func (pm *TPartitionMap[K, V]) Keys() []K {
+	if nil == pm {
+		return nil
+	}
+
+	list := pm.partitions()
+	sorted := make([][]K, 0, len(list))
+	totalKeys := 0
+	for _, p := range list {
+		if keys := p.keys(); 0 < len(keys) {
+			sorted = append(sorted, keys)
+			totalKeys += len(keys)
+		}
+	}
+
+	if 0 == totalKeys {
+		// No point in wasting time and resources ...
+		return []K{}
+	}
+
+	// Each partition's keys are already sorted, so a k-way merge
+	// is cheaper than sorting all keys again.
+	return mergeSorted(sorted)
+} // Keys()
+
+// `KeysDesc()` returns a slice of all keys in the partitioned map,
+// sorted in descending order.
+//
+// This is the counterpart of `Keys()`, e.g. for paginating backwards.
+// The keys are merged like `Keys()` does and then reversed in place,
+// which takes linear time instead of sorting them anew.
+//
+// Returns:
+//   - `[]K`: A slice of all the keys in the current partitioned map.
+func (pm *TPartitionMap[K, V]) KeysDesc() []K {
+	result := pm.Keys()
+	slices.Reverse(result)
+
+	return result
+} // KeysDesc()
+
+// `KeysUnsorted()` returns a slice of all keys in the partitioned map.
+//
+// Other than `Keys()` this method doesn't sort the keys, which saves
+// considerable time for large maps if the caller just needs the set
+// of keys. The order of the returned keys is unspecified.
+//
+// Returns:
+//   - `[]K`: A slice of all the keys in the current partitioned map.
+func (pm *TPartitionMap[K, V]) KeysUnsorted() []K {
+	if nil == pm {
+		return nil
+	}
+
+	result := make([]K, 0, pm.Len())
+	for _, p := range pm.partitions() {
+		if nil != p {
+			p.RLock()
+			result = slices.AppendSeq(result, maps.Keys(p.kv))
+			p.RUnlock()
+		}
+	}
+
+	return result
+} // KeysUnsorted()
+
+// `Len()` returns the total number of key/value pairs in the partitioned map.
+//
+// The number is a running total maintained by every modification
+// (counting inserted and removed keys only), so this is an O(1)
+// operation acquiring no lock at all. It's thus suitable for
+// frequent polling (e.g. by a metrics scraper) without contending
+// with other accesses. While other goroutines modify the map the
+// result may lag behind their latest modifications; without
+// concurrent modifications it's exact.
+//
+// Returns:
+//   - `int`: The number of all key/value pairs in the partitioned map.
+func (pm *TPartitionMap[K, V]) Len() int {
+	if nil == pm {
+		return 0
+	}
+
+	return int(pm.total.Load())
+} // Len()
+
+// `LoadOrStore()` returns the existing value for the given key if
+// present. Otherwise, it stores and returns the given value.
+//
+// The lookup and the (possible) insertion are performed atomically
+// with respect to other operations on the same key, thus avoiding
+// the race between a separate `Get()` and `Put()`.
+// The semantics are the same as those of `sync.Map.LoadOrStore()`.
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//   - `aValue`: The value to store if the key is not present.
+//
+// Returns:
+//   - `V`: The existing value if loaded, otherwise `aValue`.
+//   - `bool`: `true` if the value was loaded, `false` if stored.
+func (pm *TPartitionMap[K, V]) LoadOrStore(aKey K, aValue V) (V, bool) {
+	if nil == pm {
+		var zeroVal V
+		return zeroVal, false
+	}
+
+	aKey = pm.normKey(aKey)
+	p, _ := pm.partition(aKey, true)
+
+	val, loaded, written := p.loadOrStore(aKey, aValue)
+	if !loaded {
+		pm.evict(written)
+		pm.notifyPut(aKey, val)
+	}
+
+	return val, loaded
+} // LoadOrStore()
+
+// `Merge()` copies all key/value pairs of the given map into the
+// current one.
+//
+// Keys not yet present in the current map are simply inserted. For
+// keys present in both maps the given `aOnConflict` function decides
+// which value to store: it's called with the existing and the
+// incoming value and returns the value to keep. If `aOnConflict`
+// is `nil`, the incoming value wins.
+//
+// The given map is snapshotted (partition by partition) before any
+// modification of the current map takes place, so no locks of both
+// maps are held at the same time and merging a map into itself is
+// safe.
+//
+// NOTE: The conflict function is executed while holding the write
+// lock of the key's partition. It must therefore not call any methods
+// of the current map, otherwise a deadlock may occur.
+//
+// Parameters:
+//   - `aOther`: The partitioned map whose pairs to merge in.
+//   - `aOnConflict`: The function resolving conflicts (may be `nil`).
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Merge(aOther *TPartitionMap[K, V], aOnConflict func(aExisting, aIncoming V) V) *TPartitionMap[K, V] {
+	if (nil == pm) || (nil == aOther) {
+		return pm
+	}
+
+	for _, src := range aOther.partitions() {
+		if nil == src {
+			continue
+		}
+		for k, incoming := range src.clone() {
+			k = pm.normKey(k)
+			p, _ := pm.partition(k, true)
+			val, _, _, written := p.compute(k, func(aOld V, aFound bool) (V, bool) {
+				if aFound && (nil != aOnConflict) {
+					return aOnConflict(aOld, incoming), false
+				}
+				return incoming, false
+			})
+			pm.evict(written)
+			pm.notifyPut(k, val)
+		}
+	}
+
+	return pm
+} // Merge()
+
+type (
+	// `TMetrics` provides statistics about the partition usage.
+	//
+	// `Parts` is the number of partitions that are actually in use.
+	// `Keys` is the total number of keys across all partitions.
+	// `Avg` is the average number of keys per partition (truncated).
+	// `AvgF` is the exact average number of keys per partition.
+	// `MinKeys` is the smallest number of keys in a partition in use.
+	// `MaxKeys` is the largest number of keys in a partition in use.
+	// `EmptyParts` is the number of partitions in use but empty.
+	// `StdDev` is the standard deviation of the number of keys per
+	// partition in use; the larger it is (compared to `AvgF`) the
+	// more skewed is the distribution of keys.
+	// `PartKeys` is a map where the key is the partition index and the
+	// value is the number of keys in that partition.
+	TMetrics struct {
+		Parts      int
+		Keys       int
+		Avg        int
+		AvgF       float64
+		MinKeys    int
+		MaxKeys    int
+		EmptyParts int
+		StdDev     float64
+		PartKeys   map[int]int
+	}
+)
+
+// `MaxKey()` returns the largest key in the partitioned map.
+//
+// The partitions are scanned one after the other, each under its
+// read lock, without collecting or sorting all keys.
+//
+// Returns:
+//   - `K`: The largest key (or the zero value of `K`).
+//   - `bool`: `false` if the map is empty.
+func (pm *TPartitionMap[K, V]) MaxKey() (rKey K, rOk bool) {
+	if nil == pm {
+		return
+	}
+
+	for _, p := range pm.partitions() {
+		if _, hi, ok := p.bounds(); ok {
+			if !rOk || (hi > rKey) {
+				rKey, rOk = hi, true
+			}
+		}
+	}
+
+	return
+} // MaxKey()
+
+// `MinKey()` returns the smallest key in the partitioned map.
+//
+// The partitions are scanned one after the other, each under its
+// read lock, without collecting or sorting all keys.
+//
+// Returns:
+//   - `K`: The smallest key (or the zero value of `K`).
+//   - `bool`: `false` if the map is empty.
+func (pm *TPartitionMap[K, V]) MinKey() (rKey K, rOk bool) {
+	if nil == pm {
+		return
+	}
+
+	for _, p := range pm.partitions() {
+		if lo, _, ok := p.bounds(); ok {
+			if !rOk || (lo < rKey) {
+				rKey, rOk = lo, true
+			}
+		}
+	}
+
+	return
+} // MinKey()
+
+// `PartitionStats()` returns statistics about the partition usage.
+//
+// This method returns information about how many partitions are actually
+// in use, how many keys are distributed across the partitions, and how
+// many key/value pairs are stored in each partition.
+// This can be useful for monitoring and optimising the distribution
+// of keys across partitions.
+//
+// All figures refer to the partitions in use (i.e. those created
+// to hold a key at some time); slots without a partition aren't
+// taken into account.
+//
+// The partitions' sizes are read from counters maintained by every
+// modification, so no partition lock is acquired and the method can
+// be called frequently (e.g. by a monitoring loop).
+//
+// Returns:
+//   - `*TMetrics`: A pointer to a `TMetrics` instance containing the statistics.
+func (pm *TPartitionMap[K, V]) PartitionStats() *TMetrics {
+	if nil == pm {
+		return nil
+	}
+
+	pLen := 0
+	list := pm.slots()
+	sizes := make([]int, 0, len(list))
+	result := &TMetrics{
+		PartKeys: make(map[int]int),
+	}
+
+	for idx := range list {
+		if p := list[idx].Load(); nil != p {
+			pLen = p.len()
+			if (0 == result.Parts) || (pLen < result.MinKeys) {
+				result.MinKeys = pLen
+			}
+			result.MaxKeys = max(result.MaxKeys, pLen)
+			if 0 == pLen {
+				result.EmptyParts++
+			}
+			result.Parts++
+			result.Keys += pLen
+			result.PartKeys[idx] = pLen
+			sizes = append(sizes, pLen)
+		}
+	}
+
+	if (0 == result.Parts) || (0 == result.Keys) {
+		return result
+	}
+	result.Avg = result.Keys / result.Parts
+	result.AvgF = float64(result.Keys) / float64(result.Parts)
+
+	// Sum up in partition order to get reproducible results.
+	var variance float64
+	for _, pLen = range sizes {
+		diff := float64(pLen) - result.AvgF
+		variance += diff * diff
+	}
+	result.StdDev = math.Sqrt(variance / float64(result.Parts))
+
+	return result
+} // PartitionStats()
+
+// `Pop()` removes an arbitrary key/value pair from the partitioned
+// map and returns it.
+//
+// To spread the contention between several goroutines popping
+// concurrently, the search for a non-empty partition starts at a
+// pseudo-random partition each time. Hence, neither the order of
+// the popped pairs nor their fairness are specified.
+//
+// Example usage:
+//
+//	for key, job, ok := work.Pop(); ok; key, job, ok = work.Pop() {
+//		process(key, job)
+//	}
+//
+// Returns:
+//   - `K`: The removed key.
+//   - `V`: The removed value.
+//   - `bool`: `true` if a pair was removed, `false` if the map is empty.
+func (pm *TPartitionMap[K, V]) Pop() (rKey K, rVal V, rOk bool) {
+	if nil == pm {
+		return
+	}
+
+	// A retired partition refuses to give up any pair, so the
+	// partitions of a new layout are searched as well.
+	for layout := pm.layout.Load(); ; {
+		count := len(layout.tPartitionList)
+		start := rand.IntN(count) //#nosec G404
+		for offset := range count {
+			p := layout.tPartitionList[(start+offset)%count].Load()
+			if rKey, rVal, rOk = p.pop(); rOk {
+				pm.notifyDelete(rKey)
+				return
+			}
+		}
+		next := pm.layout.Load()
+		if next == layout {
+			return
+		}
+		layout = next
 	}
+} // Pop()
 
-	return builder.String()
-} // String()
+// `Put()` stores a key/value pair into the partitioned map.
+// If the key already exists, it will be updated.
+//
+// Parameters:
+//   - `aKey`: The key to be put into the partitioned map.
+//   - `aValue`: The value associated with the key.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Put(aKey K, aValue V) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
 
-// ---------------------------------------------------------------------------
-// `TPartitionMap` constructor:
+	aKey = pm.normKey(aKey)
+	if p, ok := pm.partition(aKey, true); ok {
+		// Store the key/value pair in the partition
+		pm.evict(p.put(aKey, aValue))
+		pm.notifyPut(aKey, aValue)
+	}
 
-// `New()` creates and initialises a new partitioned map instance.
-//
-// This is a constructor function that returns a pointer to a new
-// `TPartitionMap` instance with the specified key and value types.
+	return pm
+} // Put()
+
+// `PutAll()` stores all key/value pairs of the given map into the
+// partitioned map. Existing keys will be updated.
 //
-// The returned partitioned map is initialised with the predefined number
-// of partitions (128), but the actual partition instances are created
-// lazily when needed.
+// The pairs are grouped by their partition first, then each affected
+// partition is write-locked only once to store its whole group.
+// This is considerably cheaper than calling `Put()` for each pair.
 //
 // Example usage:
 //
-//	pm := New[string, string]()
-//	pm.Put("key1", "value1")
-//	value, exists := pm.Get("key1")
+//	pm.PutAll(map[string]int{"one": 1, "two": 2})
+//
+// Parameters:
+//   - `aSource`: The key/value pairs to store.
 //
 // Returns:
-//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
-func New[K cmp.Ordered, V any]() *TPartitionMap[K, V] {
-	// Unfortunately, Go doesn't support the use of sparse arrays
-	// (i.e. slices). That forces us to initialise the whole list
-	// at once. With 128 possible values/indices that takes 1024 bytes.
-	//
-	// An empty map is allocated with enough space to hold the
-	// specified number of elements.
-	result := &TPartitionMap[K, V]{
-		tPartitionList: make(tPartitionList[K, V], numberOfPartitionsInMap),
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) PutAll(aSource map[K]V) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+	if 0 == len(aSource) {
+		return pm
 	}
 
-	// Leave the partition instances to lazy/late initialisation;
-	// see `TPartitionMap.partition()`.
+	layout := pm.layout.Load()
+	groups := make([][]TPair[K, V], len(layout.tPartitionList))
+	for k, v := range aSource {
+		k = pm.normKey(k)
+		idx := pm.indexIn(layout, k)
+		groups[idx] = append(groups[idx], TPair[K, V]{Key: k, Value: v})
+	}
+
+	for idx, group := range groups {
+		if 0 == len(group) {
+			continue
+		}
+		if p, ok := pm.partitionAt(layout, idx, true); ok {
+			pm.evict(p.putAll(group))
+			for _, pair := range group {
+				pm.notifyPut(pair.Key, pair.Value)
+			}
+		}
+	}
+
+	return pm
+} // PutAll()
+
+// `PutIfAbsent()` stores a key/value pair into the partitioned map
+// only if the key is not already present.
+//
+// The check and the insertion are performed atomically with respect
+// to other operations on the same key.
+//
+// Parameters:
+//   - `aKey`: The key to be put into the partitioned map.
+//   - `aValue`: The value associated with the key.
+//
+// Returns:
+//   - `bool`: `true` if the pair was inserted, `false` if the key already existed.
+func (pm *TPartitionMap[K, V]) PutIfAbsent(aKey K, aValue V) bool {
+	if nil == pm {
+		return false
+	}
+
+	aKey = pm.normKey(aKey)
+	p, _ := pm.partition(aKey, true)
+	_, loaded, written := p.loadOrStore(aKey, aValue)
+	if loaded {
+		return false
+	}
+	pm.evict(written)
+	pm.notifyPut(aKey, aValue)
+
+	return true
+} // PutIfAbsent()
+
+// `PutIfPresent()` updates the value of the given key only if the
+// key is already present in the partitioned map.
+//
+// This is the counterpart of `PutIfAbsent()`, e.g. for refreshing
+// existing cache entries without populating the cache with new ones.
+// The check and the update are performed atomically with respect to
+// other operations on the same key. No partition is created for an
+// absent key. An expired entry (see `NewWithTTL()`) is treated as
+// absent.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to update.
+//   - `aValue`: The new value associated with the key.
+//
+// Returns:
+//   - `bool`: `true` if the value was updated, `false` if the key doesn't exist.
+func (pm *TPartitionMap[K, V]) PutIfPresent(aKey K, aValue V) bool {
+	if nil == pm {
+		return false
+	}
+
+	aKey = pm.normKey(aKey)
+	p, ok := pm.partition(aKey, false)
+	if !ok || !p.putIfPresent(aKey, aValue) {
+		return false
+	}
+	pm.notifyPut(aKey, aValue)
+
+	return true
+} // PutIfPresent()
+
+// `RangeEntries()` returns all key/value pairs whose keys lie within
+// the given (inclusive) bounds, i.e. `aLo <= key <= aHi`.
+//
+// Since the keys are not stored in order across the partitions, all
+// of them must be checked, hence this is an O(n) operation.
+// The returned pairs are sorted by their keys in ascending order.
+// If `aLo` is greater than `aHi` the result is empty.
+//
+// Parameters:
+//   - `aLo`: The lower bound of the keys.
+//   - `aHi`: The upper bound of the keys.
+//
+// Returns:
+//   - `[]TPair[K, V]`: The key/value pairs within the bounds.
+func (pm *TPartitionMap[K, V]) RangeEntries(aLo, aHi K) []TPair[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	result := []TPair[K, V]{}
+	aLo, aHi = pm.normKey(aLo), pm.normKey(aHi)
+	if 0 < cmp.Compare(aLo, aHi) {
+		return result
+	}
+
+	for _, p := range pm.partitions() {
+		p.rangeEach(aLo, aHi, func(aKey K, aValue V) {
+			result = append(result, TPair[K, V]{Key: aKey, Value: aValue})
+		})
+	}
+	slices.SortFunc(result, func(a, b TPair[K, V]) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
 
 	return result
-} // New()
+} // RangeEntries()
 
-// ---------------------------------------------------------------------------
-// `TPartitionMap` methods:
+// `RangeKeys()` returns all keys within the given (inclusive) bounds,
+// i.e. `aLo <= key <= aHi`.
+//
+// Since the keys are not stored in order across the partitions, all
+// of them must be checked, hence this is an O(n) operation.
+// The returned keys are sorted in ascending order.
+// If `aLo` is greater than `aHi` the result is empty.
+//
+// Parameters:
+//   - `aLo`: The lower bound of the keys.
+//   - `aHi`: The upper bound of the keys.
+//
+// Returns:
+//   - `[]K`: The keys within the bounds.
+func (pm *TPartitionMap[K, V]) RangeKeys(aLo, aHi K) []K {
+	if nil == pm {
+		return nil
+	}
 
-var (
-	// `crc32Table`: To avoid (re-)allocation with every call
-	// to `partitionIndex()` we create it here once.
-	gCrc32Table = crc32.MakeTable(crc32.Castagnoli)
-)
+	result := []K{}
+	aLo, aHi = pm.normKey(aLo), pm.normKey(aHi)
+	if 0 < cmp.Compare(aLo, aHi) {
+		return result
+	}
 
-// `partitionIndex()` computes the partition index for a given key.
-// It uses the CRC32 algorithm to generate a hash value for the key,
-// then takes the modulus of the hash value with the number of partitions
-// to obtain the partition index.
+	for _, p := range pm.partitions() {
+		p.rangeEach(aLo, aHi, func(aKey K, _ V) {
+			result = append(result, aKey)
+		})
+	}
+	slices.Sort(result)
+
+	return result
+} // RangeKeys()
+
+// `Rebalance()` moves all key/value pairs into the partitions their
+// keys belong to according to the map's current hash function.
+//
+// This is required after replacing the hash function by
+// `SetHasher()`, since existing entries would remain in the
+// partitions chosen by the previous one and couldn't be found
+// anymore. Entries keep their expiry times (see `NewWithTTL()`),
+// but their recency in an LRU map (see `NewLRU()`) is approximated.
+//
+// The whole operation is done under the map's write lock, hence it's
+// serialised with other whole-map operations like `SetAll()` or
+// `Snapshot()`. Like `Resize()` it moves the entries into a new list
+// of partitions published by a single atomic swap, so key based
+// methods like `Put()` or `Get()` may run concurrently.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Rebalance() *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	pm.Lock()
+	defer pm.Unlock()
+
+	layout := pm.relayout(func(aOld []*tPartition[K, V]) *tLayout[K, V] {
+		old := pm.layout.Load()
+
+		return pm.move(aOld, newLayout[K, V](len(old.tPartitionList), old.hasher, old.lruLimit))
+	})
+	for idx := range layout.tPartitionList {
+		pm.evict(layout.tPartitionList[idx].Load())
+	}
+
+	return pm
+} // Rebalance()
+
+// `Reset()` removes all key/value pairs from the partitioned map and
+// releases the memory held by its partitions.
+//
+// Other than `Clear()`, which empties the partitions but keeps them
+// (and their allocated storage) for a fast refill, this method drops
+// the partitions altogether so they can be garbage collected. New
+// partitions are created lazily when keys are stored again, which
+// makes refilling the map slower. Use it for a map that grew large
+// and is going to be little used for some time.
+//
+// The partitions are replaced by a single atomic swap of the map's
+// layout (see `Resize()`), so modifications running concurrently
+// either happen before the reset or affect the new, empty
+// partitions.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Reset() *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	pm.Lock()
+	pm.relayout(func(aOld []*tPartition[K, V]) *tLayout[K, V] {
+		old := pm.layout.Load()
+
+		return newLayout[K, V](len(old.tPartitionList), old.hasher, old.lruLimit)
+	})
+	pm.Unlock()
+	pm.notifyClear()
+
+	return pm
+} // Reset()
+
+// `Resize()` changes the number of partitions of the partitioned map.
+//
+// A new list of partitions is allocated and all key/value pairs are
+// moved into the partitions their keys belong to according to the
+// new number of partitions. Entries keep their expiry times (see
+// `NewWithTTL()`). In an LRU map (see `NewLRU()`) the entries are
+// redistributed across the partitions' shares of the unchanged size
+// limit, which might evict some of them.
+//
+// The given number is clamped to the range `1` to `65536` (like
+// `NewWithPartitions()` does). If it equals the current number of
+// partitions, nothing is done.
+//
+// The whole operation is done under the map's write lock, hence it's
+// serialised with other whole-map operations like `SetAll()` or
+// `Snapshot()`. Key based methods like `Put()` or `Get()` may run
+// concurrently: while the entries are moved they wait for their
+// partition, and the new list of partitions is then published by a
+// single atomic swap. A method which looked up its partition in the
+// old list is passed on to the new one, so no modification gets lost.
+// Iterations like `ForEach()` running meanwhile use either the old
+// or the new list of partitions, so they neither miss nor repeat
+// any entries because of the move.
+//
+// Example usage:
+//
+//	pm.Resize(1024) // more partitions for heavy write contention
 //
 // Parameters:
-//   - `aKey`: The key for which the partition index is to be computed.
+//   - `aCount`: The new number of partitions.
 //
 // Returns:
-//   - `uint8`: The partition index to use for the given key.
-func partitionIndex[K cmp.Ordered](aKey K) uint8 {
-	var (
-		uintKey uint64
-		key     []byte
-	)
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Resize(aCount int) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+	aCount = min(max(aCount, minPartitionsInMap), maxPartitionsInMap)
 
-	switch val := any(aKey).(type) {
-	case int: // negative values would turn into a modulo == 0
-		uintKey = uint64(val) //#nosec G115
-	case int8:
-		uintKey = uint64(val) //#nosec G115
-	case int16:
-		uintKey = uint64(val) //#nosec G115
-	case int32:
-		uintKey = uint64(val) //#nosec G115
-	case int64:
-		uintKey = uint64(val) //#nosec G115
-	case uint:
-		uintKey = uint64(val)
-	case uint8:
-		uintKey = uint64(val)
-	case uint16:
-		uintKey = uint64(val)
-	case uint32:
-		uintKey = uint64(val)
-	case uint64:
-		uintKey = val
-	case uintptr:
-		uintKey = uint64(val)
-	case float32:
-		key = []byte(strconv.FormatFloat(float64(val), 'f', -1, 32))
-	case float64:
-		key = []byte(strconv.FormatFloat(val, 'f', -1, 64))
-	case string:
-		key = []byte(val)
-	default:
-		key = fmt.Appendf(nil, "%v", aKey)
-	} // switch
+	pm.Lock()
+	pm.resize(aCount)
+	pm.sparse = 0 // see `CompactSparse()`
+	pm.Unlock()
+
+	return pm
+} // Resize()
 
-	if 0 < uintKey {
-		return uint8(uintKey % numberOfPartitionsInMap) //#nosec G115
+// `Select()` returns a new partitioned map holding only the key/value
+// pairs of the given keys.
+//
+// Keys not present in the current map are skipped, duplicate keys
+// are included only once. Like `GetMany()` the keys are grouped by
+// their partitions, so each partition is read-locked only once.
+// The returned map uses the same partition layout as the current one.
+//
+// Example usage:
+//
+//	response := users.Select(request.IDs)
+//
+// Parameters:
+//   - `aKeys`: The keys of the key/value pairs to select.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A new map with the selected pairs.
+func (pm *TPartitionMap[K, V]) Select(aKeys []K) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
 	}
 
-	// We use CRC32 for speed and adequate distribution.
-	// While it's not cryptographically secure, it's perfect for our
-	// partitioning needs.
-	// If two different keys hash to the same partition, they'll
-	// simply share a partition.
+	result := newEmptyLike[K, V, V](pm, len(pm.slots()))
+	if 0 == len(aKeys) {
+		return result
+	}
 
-	cs32 := crc32.Checksum(key, gCrc32Table)
-	return uint8(cs32 % numberOfPartitionsInMap) //#nosec G115
-} // partitionIndex()
+	layout, groups := pm.groupKeys(aKeys)
+	for idx, group := range groups {
+		if 0 == len(group) {
+			continue
+		}
+		if p, ok := pm.partitionAt(layout, idx, false); ok {
+			kv := make(tKeyMap[K, V], len(group))
+			p.getMany(group, kv)
+			if 0 < len(kv) {
+				result.slots()[idx].Store(wrapPartition(kv, &result.total))
+			}
+		}
+	}
 
-// `partition()` retrieves a partition from the partitioned map based
-// on the provided key.
+	return result
+} // Select()
+
+// `SetAll()` replaces the partitioned map's contents by the given
+// key/value pairs.
 //
-// If the partition already exists, it is returned along with a boolean
-// value indicating its existence.
+// Other than a `Clear()` followed by `PutAll()` the replacement is
+// atomic: the new contents are stored in a new list of partitions
+// which replaces the current one by a single atomic swap (see
+// `Resize()`), so readers see either the complete old or the complete
+// new contents but never a (partially) empty map. Likewise `Len()`
+// jumps from the old to the new number of pairs in a single step.
+// Modifications running concurrently either happen before the
+// replacement (and are discarded by it) or affect the new contents.
+// Readers and writers are blocked only while the prepared pairs are
+// handed over to the new partitions.
 //
-// If the partition doesn't exist yet and the create parameter is set to
-// `true`, a new partition is created and returned.
+// Like `Clear()` this method doesn't call the hooks (see `OnPut()`
+// and `OnDelete()`) for the replaced and stored pairs, except for
+// pairs evicted from an LRU map (see `NewLRU()`).
 //
-// If the partition doesn't exist and the create parameter is set to
-// `false`, the method returns `nil` and a boolean value of `false`.
+// Example usage:
+//
+//	pm.SetAll(loadDataset())
 //
 // Parameters:
-//   - `aKey`: The key used to identify the partition.
-//   - `aCreate`: A boolean value indicating whether a new partition for the given key should be created if it doesn't exist yet.
+//   - `aSource`: The key/value pairs to store.
 //
 // Returns:
-//   - `*tPartition[K, V]`: The partition associated with the provided key, or `nil` if the partition does not exist and `aCreate` is `false`.
-//   - `bool`: A boolean value indicating whether the partition was successfully retrieved.
-func (pm *TPartitionMap[K, V]) partition(aKey K, aCreate bool) (*tPartition[K, V], bool) {
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) SetAll(aSource map[K]V) *TPartitionMap[K, V] {
 	if nil == pm {
-		return nil, false
+		return nil
+	}
+
+	group := func(aLayout *tLayout[K, V]) []tKeyMap[K, V] {
+		result := make([]tKeyMap[K, V], len(aLayout.tPartitionList))
+		for k, v := range aSource {
+			k = pm.normKey(k)
+			idx := pm.indexIn(aLayout, k)
+			if nil == result[idx] {
+				result[idx] = make(tKeyMap[K, V])
+			}
+			result[idx][k] = v
+		}
+		return result
+	}
+
+	// Prepare the new contents before blocking anybody.
+	layout := pm.layout.Load()
+	groups := group(layout)
+
+	pm.Lock()
+	if current := pm.layout.Load(); current != layout {
+		// The layout was replaced meanwhile.
+		layout, groups = current, group(current)
+	}
+
+	// The partitions will own the groups, so the pairs to report
+	// must be collected before they are published.
+	var stored []TPair[K, V]
+	if pm.watching() {
+		stored = make([]TPair[K, V], 0, len(aSource))
+		for _, kv := range groups {
+			for k, v := range kv {
+				stored = append(stored, TPair[K, V]{Key: k, Value: v})
+			}
+		}
 	}
-	idx := partitionIndex(aKey)
 
-	pm.RLock()
-	p := (pm.tPartitionList)[idx]
-	pm.RUnlock()
+	layout = pm.relayout(func(aOld []*tPartition[K, V]) *tLayout[K, V] {
+		result := newLayout[K, V](len(aOld), layout.hasher, layout.lruLimit)
+		for idx, kv := range groups {
+			if nil == kv {
+				continue
+			}
+			p := pm.stage(result, idx)
+			if (nil != p.order) && (nil != aOld[idx]) {
+				// Keys present before keep their position.
+				p.order = aOld[idx].order
+			}
+			p.assign(kv)
+		}
 
-	if nil != p {
-		return p, true
+		return result
+	})
+	pm.Unlock()
+	pm.notifyClear()
+	for _, pair := range stored {
+		pm.broadcast(TEvent[K, V]{Kind: EventPut, Key: pair.Key, Value: pair.Value})
 	}
 
-	if !aCreate {
-		return nil, false
+	for idx := range layout.tPartitionList {
+		pm.evict(layout.tPartitionList[idx].Load())
 	}
 
-	// Here we do the lazy initialisation of the required `tPartition`:
-	p = newPartition[K, V]()
-	pm.Lock()
-	(pm.tPartitionList)[idx] = p
-	pm.Unlock()
-
-	return p, true
-} // partition()
+	return pm
+} // SetAll()
 
+// `SetHasher()` replaces the hash function used to assign keys to
+// partitions (see `NewWithHasher()`).
 //
-// CRUD interface
+// A `nil` hasher restores the package's default hashing.
+// Existing entries are not moved, so `Rebalance()` must be called
+// afterwards unless the map is empty.
 //
-// `C`: create == Put()
-// `R`: read == Get()
-// `U`: update == Put()
-// `D`: delete == Delete()
+// The hash function is published together with the (unchanged) list
+// of partitions by a single atomic swap, so key based methods running
+// concurrently consistently use either the old or the new one.
 //
-
-// `Clear()` removes all key/value pairs from the partitioned map.
+// Example usage:
+//
+//	pm.SetHasher(betterHash).Rebalance()
+//
+// Parameters:
+//   - `aHasher`: The function computing a key's hash value.
 //
 // Returns:
 //   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
-func (pm *TPartitionMap[K, V]) Clear() *TPartitionMap[K, V] {
+func (pm *TPartitionMap[K, V]) SetHasher(aHasher func(aKey K) uint64) *TPartitionMap[K, V] {
 	if nil == pm {
 		return nil
 	}
 
 	pm.Lock()
-	for _, p := range pm.tPartitionList {
-		p.clear()
+	old := pm.layout.Load()
+	layout := newLayout[K, V](len(old.tPartitionList), aHasher, old.lruLimit)
+	// The partitions are shared, but the old layout's empty slots are
+	// sealed so no partition can be created there anymore.
+	seal := pm.seal()
+	for idx := range old.tPartitionList {
+		slot := &old.tPartitionList[idx]
+		if !slot.CompareAndSwap(nil, seal) {
+			layout.tPartitionList[idx].Store(slot.Load())
+		}
 	}
+	pm.layout.Store(layout)
+	seal.Unlock()
 	pm.Unlock()
 
 	return pm
-} // Clear()
+} // SetHasher()
 
-// `Delete()` removes a key/value pair from the partitioned map.
+// `ShrinkToFit()` releases the memory held by partitions which once
+// stored far more entries than they do now.
 //
-// Parameters:
-//   - `aKey`: The key of the key/value pair to be deleted.
+// Go's maps don't shrink when entries are deleted, so after a burst
+// of insertions followed by deletions a partition keeps the memory
+// needed for its peak size. This method rebuilds each partition
+// holding no more than a quarter of its peak number of entries into
+// a fresh, right-sized map. Partitions which are still compact (or
+// never held more than a few entries) are skipped.
+//
+// Each partition is rebuilt under its write lock, which blocks the
+// accesses to that partition for the time needed to copy its
+// entries.
 //
 // Returns:
 //   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
-func (pm *TPartitionMap[K, V]) Delete(aKey K) *TPartitionMap[K, V] {
+func (pm *TPartitionMap[K, V]) ShrinkToFit() *TPartitionMap[K, V] {
 	if nil == pm {
 		return nil
 	}
 
-	if p, ok := pm.partition(aKey, false); ok {
-		p.del(aKey)
-	}
+	pm.eachPartition(func(aPartition *tPartition[K, V]) bool {
+		aPartition.shrink()
+		return true
+	})
 
 	return pm
-} // Delete()
+} // ShrinkToFit()
 
-// `ForEach()` executes the provided function for each key/value pair
-// in the partitioned map.
+// `Snapshot()` returns an independent copy of the partitioned map
+// reflecting its contents at a single point in time.
 //
-// Parameters:
-//   - `aFunc`: The function to execute for each key/value pair.
+// Unlike `Clone()`, which copies each partition at a slightly
+// different moment, all partitions are read-locked at the same time
+// while being copied. Hence the returned map is fully consistent:
+// if a goroutine performed two writes one after the other, the
+// snapshot never contains the second one without the first one.
+// Iterating the snapshot is consistent as well while the original
+// map keeps being modified.
+//
+// The price for this is that all writers are blocked while the
+// snapshot is taken.
 //
 // Returns:
-//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
-func (pm *TPartitionMap[K, V]) ForEach(aFunc func(aKey K, aValue V)) *TPartitionMap[K, V] {
+//   - `*TPartitionMap[K, V]`: A point-in-time copy of the partitioned map.
+func (pm *TPartitionMap[K, V]) Snapshot() *TPartitionMap[K, V] {
 	if nil == pm {
 		return nil
 	}
 
-	pm.RLock()
-	for _, p := range pm.tPartitionList {
-		p.forEach(aFunc)
+	// Only one goroutine at a time may hold several partition locks,
+	// otherwise concurrent snapshots could deadlock each other.
+	pm.Lock()
+	defer pm.Unlock()
+
+	// Partitions may be created concurrently while we're acquiring
+	// the locks, so we repeat until a pass finds no new partition.
+	list := pm.slots()
+	count := len(list)
+	locked := make([]*tPartition[K, V], count)
+	for found := true; found; {
+		found = false
+		for idx := range list {
+			if nil != locked[idx] {
+				continue
+			}
+			if p := list[idx].Load(); nil != p {
+				p.RLock()
+				locked[idx] = p
+				found = true
+			}
+		}
 	}
-	pm.RUnlock()
 
-	return pm
-} // ForEach()
+	result := newEmptyLike[K, V, V](pm, count)
+	for idx, p := range locked {
+		if nil != p {
+			result.slots()[idx].Store(wrapPartition(maps.Clone(p.kv), &result.total))
+			p.RUnlock()
+		}
+	}
 
-// `Get()` retrieves a key/value pair from the partitioned map.
+	return result
+} // Snapshot()
+
+// `String()` returns a string representation of the `TPartitionMap`.
 //
-// If the partitioned map contains a key/value pair with the specified key,
-// the function returns the associated value and a boolean value indicating
-// whether the key was found. If the key is not found, the function returns
-// the zero value of type V and a boolean value of false.
+// Each key/value pair is rendered as a `key: 'value'` line. The lines
+// are sorted by their keys in ascending order across all partitions,
+// so the output is deterministic.
+// An empty or `nil` map yields an empty string.
+//
+// Returns:
+//   - `string`: A string representation of the partitioned map.
+func (pm *TPartitionMap[K, V]) String() string {
+	return pm.StringFunc(func(aKey K, aValue V) string {
+		return fmt.Sprintf("%v: '%v'", aKey, aValue)
+	})
+} // String()
+
+// `StringFunc()` returns a string representation of the
+// `TPartitionMap` using the given function to render each
+// key/value pair.
+//
+// The rendered pairs are emitted one per line, sorted by their keys
+// in ascending order (see `Entries()`). The function is called
+// without holding any locks.
+// An empty or `nil` map yields an empty string.
+//
+// Example usage:
+//
+//	s := pm.StringFunc(func(aKey string, aValue int) string {
+//		return fmt.Sprintf("%s=%d", aKey, aValue)
+//	})
 //
 // Parameters:
-//   - `aKey`: The key of the key/value pair to be retrieved.
+//   - `aFormat`: The function rendering a single key/value pair (`nil` for the default format of `String()`).
 //
 // Returns:
-//   - `V`: The value associated with the key (if found).
-//   - `bool`: Indicating for whether the key was found.
-func (pm *TPartitionMap[K, V]) Get(aKey K) (V, bool) {
-	var zeroVal V
+//   - `string`: A string representation of the partitioned map.
+func (pm *TPartitionMap[K, V]) StringFunc(aFormat func(aKey K, aValue V) string) string {
 	if nil == pm {
-		return zeroVal, false
+		return ""
+	}
+	if nil == aFormat {
+		return pm.String()
 	}
 
-	if p, ok := pm.partition(aKey, false); ok {
-		return p.get(aKey)
+	var builder strings.Builder
+	for _, e := range pm.Entries() {
+		builder.WriteString(aFormat(e.Key, e.Value))
+		builder.WriteByte('\n')
 	}
 
-	return zeroVal, false
-} // Get()
+	return builder.String()
+} // StringFunc()
 
-// `GetOrDefault()` retrieves a value for the given key, or returns
-// the given default value if the key doesn't exist in the partitioned map.
+// `StringN()` returns a string representation of the `TPartitionMap`
+// rendering at most the given number of key/value pairs.
+//
+// The pairs are rendered like `String()` does, sorted by their keys
+// in ascending order. If the map holds more pairs, the output ends
+// with a `... (N more)` line stating the number of pairs omitted.
+// This makes even large maps safe to log.
+// An empty or `nil` map yields an empty string.
+//
+// Example usage:
+//
+//	log.Printf("cache contents:\n%s", cache.StringN(20))
 //
 // Parameters:
-//   - `aKey`: The key to look up.
-//   - `aDefault`: The default value to return if the key is not found.
+//   - `aMax`: The max. number of pairs to render.
 //
 // Returns:
-//   - `V`: The value associated with `aKey`, or `aDefault` if not found.
-func (pm *TPartitionMap[K, V]) GetOrDefault(aKey K, aDefault V) V {
-	if nil != pm {
-		if result, ok := pm.Get(aKey); ok {
-			return result
-		}
+//   - `string`: A string representation of the partitioned map.
+func (pm *TPartitionMap[K, V]) StringN(aMax int) string {
+	if nil == pm {
+		return ""
 	}
 
-	return aDefault
-} // GetOrDefault()
+	entries := pm.Entries()
+	aMax = min(max(aMax, 0), len(entries))
 
-// `Keys()` returns a slice of all keys in the partitioned map.
+	var builder strings.Builder
+	for _, e := range entries[:aMax] {
+		fmt.Fprintf(&builder, "%v: '%v'\n", e.Key, e.Value)
+	}
+	if more := len(entries) - aMax; 0 < more {
+		fmt.Fprintf(&builder, "... (%d more)\n", more)
+	}
+
+	return builder.String()
+} // StringN()
+
+// `Take()` returns up to the given number of key/value pairs from
+// the partitioned map without removing them.
 //
-// The partitioned map is divided into multiple partitions, each holding
-// a subset of the keys. This method retrieves the keys from all
-// partitions and returns them in a sorted slice.
+// The partitions are scanned one after the other (each under its
+// read lock) until enough pairs are collected. Hence the selection
+// is arbitrary: it's neither sorted nor random, and it may change
+// from one call to the next. If the map holds fewer pairs, all of
+// them are returned.
 //
-// The returned slice is a copy of the keys from all partitions, sorted
-// in ascending order.
+// Example usage:
+//
+//	sample := pm.Take(10)
+//
+// Parameters:
+//   - `aCount`: The max. number of pairs to return.
 //
 // Returns:
-//   - `[]K`: A slice of all the keys in the current partitioned map.
-func (pm *TPartitionMap[K, V]) Keys() []K {
+//   - `map[K]V`: The selected key/value pairs.
+func (pm *TPartitionMap[K, V]) Take(aCount int) map[K]V {
 	if nil == pm {
 		return nil
 	}
 
-	totalKeys := 0
-	pm.RLock()
-	for _, p := range pm.tPartitionList {
-		totalKeys += p.len()
-	}
-	pm.RUnlock()
-
-	if 0 == totalKeys {
-		// No point in wasting time and resources ...
-		return []K{}
-	}
-
-	result := make([]K, 0, totalKeys)
-
-	// Collect all keys
-	pm.RLock()
-	for _, p := range pm.tPartitionList {
-		if nil != p {
-			result = append(result, p.keys()...)
+	result := make(map[K]V, max(min(aCount, pm.Len()), 0))
+	for _, p := range pm.partitions() {
+		if len(result) >= aCount {
+			break
 		}
+		p.take(aCount-len(result), result)
 	}
-	pm.RUnlock()
-
-	slices.Sort(result)
 
 	return result
-} // Keys()
+} // Take()
 
-// `Len()` returns the total number of key/value pairs in the partitioned map.
+// `TakeAndDelete()` removes up to the given number of key/value
+// pairs from the partitioned map and returns them.
+//
+// Like `Take()` the selection is arbitrary. Each partition is
+// write-locked while its pairs are removed, so every pair is
+// returned to only one of several concurrent callers. The delete
+// hook (see `OnDelete()`) is called for each removed key.
+//
+// Example usage:
+//
+//	// process the pending jobs in batches
+//	for batch := jobs.TakeAndDelete(100); 0 < len(batch); batch = jobs.TakeAndDelete(100) {
+//		process(batch)
+//	}
+//
+// Parameters:
+//   - `aCount`: The max. number of pairs to remove.
 //
 // Returns:
-//   - `rLen`: The number of all key/value pairs in the partitioned map.
-func (pm *TPartitionMap[K, V]) Len() (rLen int) {
+//   - `map[K]V`: The removed key/value pairs.
+func (pm *TPartitionMap[K, V]) TakeAndDelete(aCount int) map[K]V {
 	if nil == pm {
-		return
-	}
-
-	pm.RLock()
-	for _, p := range pm.tPartitionList {
-		rLen += p.len()
+		return nil
 	}
-	pm.RUnlock()
 
-	return
-} // Len()
+	result := make(map[K]V, max(min(aCount, pm.Len()), 0))
+	pm.eachPartition(func(aPartition *tPartition[K, V]) bool {
+		if len(result) >= aCount {
+			return false
+		}
+		for _, key := range aPartition.takeAndDelete(aCount-len(result), result) {
+			pm.notifyDelete(key)
+		}
+		return true
+	})
 
-type (
-	// `TMetrics` provides statistics about the partition usage.
-	//
-	// `Parts` is the number of partitions that are actually in use.
-	// `Keys` is the total number of keys across all partitions.
-	// `Avg` is the average number of keys per partition.
-	// `PartKeys` is a map where the key is the partition index and the
-	// value is the number of keys in that partition.
-	TMetrics struct {
-		Parts    int
-		Keys     int
-		Avg      int
-		PartKeys map[int]int
-	}
-)
+	return result
+} // TakeAndDelete()
 
-// `PartitionStats()` returns statistics about the partition usage.
+// `ToMap()` returns a plain map holding all key/value pairs of the
+// partitioned map.
 //
-// This method returns information about how many partitions are actually
-// in use, how many keys are distributed across the partitions, and how
-// many key/value pairs are stored in each partition.
-// This can be useful for monitoring and optimising the distribution
-// of keys across partitions.
+// The returned map is independent of the partitioned map, i.e. later
+// modifications of either one don't affect the other.
+// Each partition is read-locked only while its contents are copied.
 //
 // Returns:
-//   - `*TMetrics`: A pointer to a `TMetrics` instance containing the statistics.
-func (pm *TPartitionMap[K, V]) PartitionStats() *TMetrics {
+//   - `map[K]V`: A snapshot of all key/value pairs.
+func (pm *TPartitionMap[K, V]) ToMap() map[K]V {
 	if nil == pm {
 		return nil
 	}
 
-	pLen := 0
-	result := &TMetrics{
-		PartKeys: make(map[int]int),
-	}
-
-	pm.RLock()
-	for idx, p := range pm.tPartitionList {
+	result := make(map[K]V, pm.Len())
+	for _, p := range pm.partitions() {
 		if nil != p {
-			result.Parts++
-			pLen = p.len()
-			result.Keys += pLen
-			result.PartKeys[idx] = pLen
+			p.RLock()
+			maps.Copy(result, p.kv)
+			p.RUnlock()
 		}
 	}
-	pm.RUnlock()
-
-	if (0 == result.Parts) || (0 == result.Keys) {
-		return result
-	}
-	result.Avg = result.Keys / result.Parts
 
 	return result
-} // PartitionStats()
+} // ToMap()
 
-// `Put()` stores a key/value pair into the partitioned map.
-// If the key already exists, it will be updated.
+// `Update()` atomically replaces the value associated with the given
+// key by the result of the given function.
+//
+// The function is called with the current value. If it returns an
+// error, the current value is left intact and the error is returned;
+// otherwise its result is stored. If the key is not present, the
+// function isn't called and `ErrKeyNotFound` is returned. A `nil`
+// function does nothing.
+// This allows for modifying e.g. a field of a struct value without
+// the race between a separate `Get()` and `Put()`.
+//
+// NOTE: The function is executed while holding the write lock of
+// the key's partition. It must therefore not call any methods of
+// the partitioned map, otherwise a deadlock may occur.
+//
+// Example usage:
+//
+//	err := accounts.Update(id, func(aOld TAccount) (TAccount, error) {
+//		if aOld.Balance < amount {
+//			return aOld, ErrInsufficientFunds
+//		}
+//		aOld.Balance -= amount
+//		return aOld, nil
+//	})
 //
 // Parameters:
-//   - `aKey`: The key to be put into the partitioned map.
-//   - `aValue`: The value associated with the key.
+//   - `aKey`: The key of the key/value pair to update.
+//   - `aFunc`: The function computing the new value.
 //
 // Returns:
-//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
-func (pm *TPartitionMap[K, V]) Put(aKey K, aValue V) *TPartitionMap[K, V] {
+//   - `error`: `ErrKeyNotFound`, the function's error, or `nil`.
+func (pm *TPartitionMap[K, V]) Update(aKey K, aFunc func(aOld V) (V, error)) error {
 	if nil == pm {
+		return ErrKeyNotFound
+	}
+	if nil == aFunc {
 		return nil
 	}
 
-	if p, ok := pm.partition(aKey, true); ok {
-		// Store the key/value pair in the partition
-		p.put(aKey, aValue)
+	aKey = pm.normKey(aKey)
+	p, _ := pm.partition(aKey, false)
+
+	val, err := p.update(aKey, aFunc)
+	if nil == err {
+		pm.notifyPut(aKey, val)
 	}
 
-	return pm
-} // Put()
+	return err
+} // Update()
 
-// `String()` returns a string representation of the `TPartitionMap`.
-// It iterates over all existing partitions and concatenates their
-// string representations.
+// `Upsert()` stores all key/value pairs of the given map into the
+// partitioned map, combining them with existing values.
+//
+// For each key already present, the given function is called with
+// the existing and the incoming value, and its result is stored;
+// all other pairs are inserted as they are. A `nil` function lets
+// the incoming values overwrite the existing ones, i.e. the method
+// then behaves like `PutAll()`.
+// Like `PutAll()` the pairs are grouped by their partition first,
+// then each affected partition is write-locked only once.
+//
+// NOTE: The function is executed while holding the write lock of
+// the key's partition. It must therefore not call any methods of
+// the partitioned map, otherwise a deadlock may occur.
+//
+// Example usage:
+//
+//	// add up the counts of overlapping batches
+//	counts.Upsert(batch, func(aExisting, aIncoming int) int {
+//		return aExisting + aIncoming
+//	})
 //
-// The keys in returned string are sorted in ascending order.
+// Parameters:
+//   - `aSource`: The key/value pairs to store.
+//   - `aMerge`: The function combining an existing and an incoming value.
 //
 // Returns:
-//   - `string`: A string representation of the partitioned map.
-func (pm *TPartitionMap[K, V]) String() string {
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) Upsert(aSource map[K]V, aMerge func(aExisting, aIncoming V) V) *TPartitionMap[K, V] {
+	if nil == aMerge {
+		return pm.PutAll(aSource)
+	}
 	if nil == pm {
-		return ""
+		return nil
 	}
 
-	var builder strings.Builder
-	pm.RLock()
-	for _, p := range pm.tPartitionList {
-		builder.WriteString(p.String())
+	layout := pm.layout.Load()
+	groups := make([][]TPair[K, V], len(layout.tPartitionList))
+	for k, v := range aSource {
+		k = pm.normKey(k)
+		idx := pm.indexIn(layout, k)
+		groups[idx] = append(groups[idx], TPair[K, V]{Key: k, Value: v})
 	}
-	pm.RUnlock()
 
-	return builder.String()
-} // String()
+	for idx, group := range groups {
+		if 0 == len(group) {
+			continue
+		}
+		if p, ok := pm.partitionAt(layout, idx, true); ok {
+			pm.evict(p.upsert(group, aMerge))
+			for _, pair := range group {
+				pm.notifyPut(pair.Key, pair.Value)
+			}
+		}
+	}
+
+	return pm
+} // Upsert()
 
 // `Values()` returns a slice of all values in the partitioned map.
 //
@@ -712,7 +4457,8 @@ func (pm *TPartitionMap[K, V]) String() string {
 // partitions and returns them in a slice.
 //
 // The order of values in the returned slice corresponds to the order
-// of keys returned by the `Keys()` method.
+// of the (sorted) keys. Since the values are taken from `Entries()`
+// that correspondence holds even under concurrent modifications.
 //
 // Returns:
 //   - `[]V`: A slice of all the values in the current partitioned map.
@@ -720,26 +4466,82 @@ func (pm *TPartitionMap[K, V]) Values() []V {
 	if nil == pm {
 		return nil
 	}
-	var (
-		ok  bool
-		p   *tPartition[K, V]
-		val V
-	)
 
-	// No locking, as `Keys()` and `partition()` are already thread-safe.
+	entries := pm.Entries()
+	result := make([]V, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Value)
+	}
 
-	keys := pm.Keys()
-	result := make([]V, 0, len(keys))
+	return result
+} // Values()
 
-	for _, key := range keys {
-		if p, ok = pm.partition(key, false); ok {
-			if val, ok = p.get(key); ok {
-				result = append(result, val)
-			}
+// `ValuesSortedFunc()` returns a slice of all values in the
+// partitioned map, sorted by the given comparison.
+//
+// The values are collected like `ValuesUnsorted()` does and then
+// sorted without holding any locks. The order of values which are
+// neither less nor greater than each other is unspecified. If
+// `aLess` is `nil`, the values are returned unsorted. For ordered
+// value types the package function `ValuesSorted()` is simpler.
+//
+// Example usage:
+//
+//	byAge := users.ValuesSortedFunc(func(a, b *TUser) bool {
+//		return a.Age < b.Age
+//	})
+//
+// Parameters:
+//   - `aLess`: The function reporting whether `a` sorts before `b`.
+//
+// Returns:
+//   - `[]V`: A slice of all values in the given order.
+func (pm *TPartitionMap[K, V]) ValuesSortedFunc(aLess func(a, b V) bool) []V {
+	result := pm.ValuesUnsorted()
+	if nil == aLess {
+		return result
+	}
+
+	slices.SortFunc(result, func(a, b V) int {
+		switch {
+		case aLess(a, b):
+			return -1
+		case aLess(b, a):
+			return 1
+		default:
+			return 0
 		}
-	} // for key
+	})
 
 	return result
-} // Values()
+} // ValuesSortedFunc()
+
+// `ValuesUnsorted()` returns a slice of all values in the partitioned
+// map.
+//
+// Other than `Values()` this method doesn't order the values by their
+// keys but collects them directly while walking the partitions, each
+// under its read lock. That saves considerable time for large maps
+// if the caller just needs the values. The order of the returned
+// values is unspecified.
+//
+// Returns:
+//   - `[]V`: A slice of all the values in the current partitioned map.
+func (pm *TPartitionMap[K, V]) ValuesUnsorted() []V {
+	if nil == pm {
+		return nil
+	}
+
+	result := make([]V, 0, pm.Len())
+	for _, p := range pm.partitions() {
+		if nil != p {
+			p.RLock()
+			result = slices.AppendSeq(result, maps.Values(p.kv))
+			p.RUnlock()
+		}
+	}
+
+	return result
+} // ValuesUnsorted()
 
 /* _EoF_ */
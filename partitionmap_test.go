@@ -7,12 +7,20 @@ Copyright © 2024, 2025  M.Watermann, 10247 Berlin, Germany
 package partitionmap
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
+	"hash/crc32"
+	"maps"
+	"math"
+	"math/rand/v2"
 	"reflect"
+	"runtime"
 	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -45,37 +53,37 @@ func Test_partitionIndex_TypeSpecific(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Convert the test key to the generic type
-			var idx uint8
+			var idx int
 
 			switch tc.wantType {
 			case "int":
-				idx = partitionIndex(tc.key.(int))
+				idx = partitionIndex(tc.key.(int), numberOfPartitionsInMap)
 			case "int8":
-				idx = partitionIndex(tc.key.(int8))
+				idx = partitionIndex(tc.key.(int8), numberOfPartitionsInMap)
 			case "int16":
-				idx = partitionIndex(tc.key.(int16))
+				idx = partitionIndex(tc.key.(int16), numberOfPartitionsInMap)
 			case "int32":
-				idx = partitionIndex(tc.key.(int32))
+				idx = partitionIndex(tc.key.(int32), numberOfPartitionsInMap)
 			case "int64":
-				idx = partitionIndex(tc.key.(int64))
+				idx = partitionIndex(tc.key.(int64), numberOfPartitionsInMap)
 			case "uint":
-				idx = partitionIndex(tc.key.(uint))
+				idx = partitionIndex(tc.key.(uint), numberOfPartitionsInMap)
 			case "uint8":
-				idx = partitionIndex(tc.key.(uint8))
+				idx = partitionIndex(tc.key.(uint8), numberOfPartitionsInMap)
 			case "uint16":
-				idx = partitionIndex(tc.key.(uint16))
+				idx = partitionIndex(tc.key.(uint16), numberOfPartitionsInMap)
 			case "uint32":
-				idx = partitionIndex(tc.key.(uint32))
+				idx = partitionIndex(tc.key.(uint32), numberOfPartitionsInMap)
 			case "uint64":
-				idx = partitionIndex(tc.key.(uint64))
+				idx = partitionIndex(tc.key.(uint64), numberOfPartitionsInMap)
 			case "uintptr":
-				idx = partitionIndex(tc.key.(uintptr))
+				idx = partitionIndex(tc.key.(uintptr), numberOfPartitionsInMap)
 			case "float32":
-				idx = partitionIndex(tc.key.(float32))
+				idx = partitionIndex(tc.key.(float32), numberOfPartitionsInMap)
 			case "float64":
-				idx = partitionIndex(tc.key.(float64))
+				idx = partitionIndex(tc.key.(float64), numberOfPartitionsInMap)
 			case "string":
-				idx = partitionIndex(tc.key.(string))
+				idx = partitionIndex(tc.key.(string), numberOfPartitionsInMap)
 			} // switch
 
 			// Verify index is within valid range
@@ -85,36 +93,36 @@ func Test_partitionIndex_TypeSpecific(t *testing.T) {
 			}
 
 			// Verify consistency - same key should always produce same index
-			var idx2 uint8
+			var idx2 int
 			switch tc.wantType {
 			case "int":
-				idx2 = partitionIndex(tc.key.(int))
+				idx2 = partitionIndex(tc.key.(int), numberOfPartitionsInMap)
 			case "int8":
-				idx2 = partitionIndex(tc.key.(int8))
+				idx2 = partitionIndex(tc.key.(int8), numberOfPartitionsInMap)
 			case "int16":
-				idx2 = partitionIndex(tc.key.(int16))
+				idx2 = partitionIndex(tc.key.(int16), numberOfPartitionsInMap)
 			case "int32":
-				idx2 = partitionIndex(tc.key.(int32))
+				idx2 = partitionIndex(tc.key.(int32), numberOfPartitionsInMap)
 			case "int64":
-				idx2 = partitionIndex(tc.key.(int64))
+				idx2 = partitionIndex(tc.key.(int64), numberOfPartitionsInMap)
 			case "uint":
-				idx2 = partitionIndex(tc.key.(uint))
+				idx2 = partitionIndex(tc.key.(uint), numberOfPartitionsInMap)
 			case "uint8":
-				idx2 = partitionIndex(tc.key.(uint8))
+				idx2 = partitionIndex(tc.key.(uint8), numberOfPartitionsInMap)
 			case "uint16":
-				idx2 = partitionIndex(tc.key.(uint16))
+				idx2 = partitionIndex(tc.key.(uint16), numberOfPartitionsInMap)
 			case "uint32":
-				idx2 = partitionIndex(tc.key.(uint32))
+				idx2 = partitionIndex(tc.key.(uint32), numberOfPartitionsInMap)
 			case "uint64":
-				idx2 = partitionIndex(tc.key.(uint64))
+				idx2 = partitionIndex(tc.key.(uint64), numberOfPartitionsInMap)
 			case "uintptr":
-				idx2 = partitionIndex(tc.key.(uintptr))
+				idx2 = partitionIndex(tc.key.(uintptr), numberOfPartitionsInMap)
 			case "float32":
-				idx2 = partitionIndex(tc.key.(float32))
+				idx2 = partitionIndex(tc.key.(float32), numberOfPartitionsInMap)
 			case "float64":
-				idx2 = partitionIndex(tc.key.(float64))
+				idx2 = partitionIndex(tc.key.(float64), numberOfPartitionsInMap)
 			case "string":
-				idx2 = partitionIndex(tc.key.(string))
+				idx2 = partitionIndex(tc.key.(string), numberOfPartitionsInMap)
 			} // switch
 
 			if idx != idx2 {
@@ -125,6 +133,696 @@ func Test_partitionIndex_TypeSpecific(t *testing.T) {
 	}
 } // Test_partitionIndex_TypeSpecific()
 
+func Test_partitionIndex_NegativeKeys(t *testing.T) {
+	pm := New[int, int]()
+	for i := -10000; i <= 10000; i++ {
+		pm.Put(i, i)
+	}
+
+	metrics := pm.PartitionStats()
+	if numberOfPartitionsInMap != metrics.Parts {
+		t.Errorf("PartitionStats() metrics.Parts = %d, want %d",
+			metrics.Parts, numberOfPartitionsInMap)
+	}
+
+	// Each partition should hold roughly the average number of keys.
+	for idx, count := range metrics.PartKeys {
+		if (count < metrics.Avg/2) || (count > metrics.Avg*2) {
+			t.Errorf("partition %d holds %d keys, average is %d",
+				idx, count, metrics.Avg)
+		}
+	}
+
+	// Only negative keys:
+	pm = New[int, int]()
+	for i := -1; i >= -10000; i-- {
+		pm.Put(i, i)
+	}
+	metrics = pm.PartitionStats()
+	for idx, count := range metrics.PartKeys {
+		if (count < metrics.Avg/2) || (count > metrics.Avg*2) {
+			t.Errorf("partition %d holds %d negative keys, average is %d",
+				idx, count, metrics.Avg)
+		}
+	}
+} // Test_partitionIndex_NegativeKeys()
+
+func Test_partitionIndex_ZeroKeys(t *testing.T) {
+	// Integer keys, including zero, must use the mixing path,
+	for _, key := range []int{0, 1, 127, 128, 129, 255} {
+		want := int(mixHash(uint64(key)) % numberOfPartitionsInMap)
+		if got := partitionIndex(key, numberOfPartitionsInMap); got != want {
+			t.Errorf("partitionIndex(%d) = %d, want %d",
+				key, got, want)
+		}
+	}
+	for _, key := range []uint{0, 1, 128, 200} {
+		want := int(mixHash(uint64(key)) % numberOfPartitionsInMap)
+		if got := partitionIndex(key, numberOfPartitionsInMap); got != want {
+			t.Errorf("partitionIndex(uint(%d)) = %d, want %d",
+				key, got, want)
+		}
+	}
+
+	// while string, float, and default keys use the CRC32 path.
+	for _, key := range []string{"", "0", "key"} {
+		want := int(crc32.Checksum([]byte(key), gCrc32Table) % numberOfPartitionsInMap)
+		if got := partitionIndex(key, numberOfPartitionsInMap); got != want {
+			t.Errorf("partitionIndex(%q) = %d, want %d",
+				key, got, want)
+		}
+	}
+	if got, want := partitionIndex(float64(0), numberOfPartitionsInMap), int(crc32.Checksum([]byte("0"), gCrc32Table)%numberOfPartitionsInMap); got != want {
+		t.Errorf("partitionIndex(0.0) = %d, want %d", got, want)
+	}
+
+	// Named types are handled by the default case.
+	type tName string
+	if got, want := partitionIndex(tName(""), numberOfPartitionsInMap), int(crc32.Checksum(nil, gCrc32Table)%numberOfPartitionsInMap); got != want {
+		t.Errorf("partitionIndex(tName(\"\")) = %d, want %d", got, want)
+	}
+} // Test_partitionIndex_ZeroKeys()
+
+func Test_partitionIndex_StridedKeys(t *testing.T) {
+	const numKeys = numberOfPartitionsInMap * 16
+
+	// IDs spaced by multiples of the number of partitions must
+	// still be spread across (nearly) all partitions.
+	for _, stride := range []int{1, 2, numberOfPartitionsInMap, numberOfPartitionsInMap * 8, 1 << 20} {
+		pm := New[uint64, int]()
+		for i := range numKeys {
+			pm.Put(uint64(i*stride), i) //#nosec G115
+		}
+
+		stats := pm.PartitionStats()
+		if got := stats.Parts; numberOfPartitionsInMap*9/10 > got {
+			t.Errorf("stride %d: PartitionStats().Parts = %d, want most of %d",
+				stride, got, numberOfPartitionsInMap)
+		}
+		if got := stats.MaxKeys; 3*stats.Avg < got {
+			t.Errorf("stride %d: PartitionStats().MaxKeys = %d, average is %d",
+				stride, got, stats.Avg)
+		}
+	}
+} // Test_partitionIndex_StridedKeys()
+
+func Test_partitionIndex_Count(t *testing.T) {
+	for _, count := range []int{1, 2, 7, 128, 1000, maxPartitionsInMap} {
+		for key := -1000; key <= 1000; key++ {
+			if idx := partitionIndex(key, count); (0 > idx) || (idx >= count) {
+				t.Errorf("partitionIndex(%d, %d) = %d, want [0..%d)",
+					key, count, idx, count)
+			}
+			skey := fmt.Sprintf("key-%d", key)
+			if idx := partitionIndex(skey, count); (0 > idx) || (idx >= count) {
+				t.Errorf("partitionIndex(%q, %d) = %d, want [0..%d)",
+					skey, count, idx, count)
+			}
+		}
+	}
+} // Test_partitionIndex_Count()
+
+// `checkPartitionIndex()` verifies that `PartitionIndex()` is in
+// range, consistent, and matches the placement of a map with the
+// given number of partitions.
+func checkPartitionIndex[K cmp.Ordered](t *testing.T, aKey K, aCount int) {
+	t.Helper()
+
+	idx := PartitionIndex(aKey, aCount)
+	if (0 > idx) || (idx >= aCount) {
+		t.Fatalf("PartitionIndex(%v, %d) = %d, want [0..%d)",
+			aKey, aCount, idx, aCount)
+	}
+	if idx2 := PartitionIndex(aKey, aCount); idx != idx2 {
+		t.Errorf("PartitionIndex(%v, %d) not consistent: %d and %d",
+			aKey, aCount, idx, idx2)
+	}
+	if want := partitionIndex(aKey, aCount); idx != want {
+		t.Errorf("PartitionIndex(%v, %d) = %d, want %d", aKey, aCount, idx, want)
+	}
+
+	pm := NewWithPartitions[K, bool](aCount).Put(aKey, true)
+	if p := pm.slots()[idx].Load(); (nil == p) || !p.has(aKey) {
+		t.Errorf("PartitionIndex(%v, %d) = %d: key not stored there",
+			aKey, aCount, idx)
+	}
+} // checkPartitionIndex()
+
+func Test_PartitionIndex_TypeSpecific(t *testing.T) {
+	type tName string
+
+	for _, count := range []int{1, 7, numberOfPartitionsInMap, 1000} {
+		t.Run(fmt.Sprint("Count ", count), func(t *testing.T) {
+			checkPartitionIndex(t, 42, count)
+			checkPartitionIndex(t, int8(-127), count)
+			checkPartitionIndex(t, int16(-32767), count)
+			checkPartitionIndex(t, int32(-2147483647), count)
+			checkPartitionIndex(t, int64(-9223372036854775807), count)
+			checkPartitionIndex(t, uint(42), count)
+			checkPartitionIndex(t, uint8(255), count)
+			checkPartitionIndex(t, uint16(65535), count)
+			checkPartitionIndex(t, uint32(4294967295), count)
+			checkPartitionIndex(t, uint64(18446744073709551615), count)
+			checkPartitionIndex(t, uintptr(128), count)
+			checkPartitionIndex(t, float32(3.14), count)
+			checkPartitionIndex(t, 3.14159, count)
+			checkPartitionIndex(t, "test key string", count)
+			checkPartitionIndex(t, tName("test key name"), count)
+		})
+	}
+
+	// Invalid numbers of partitions are clamped.
+	if got := PartitionIndex("key", 0); 0 != got {
+		t.Errorf("PartitionIndex(\"key\", 0) = %d, want 0", got)
+	}
+	if got, want := PartitionIndex(12345678, maxPartitionsInMap+1), int(mixHash(12345678)%maxPartitionsInMap); got != want {
+		t.Errorf("PartitionIndex(12345678, %d) = %d, want %d",
+			maxPartitionsInMap+1, got, want)
+	}
+} // Test_PartitionIndex_TypeSpecific()
+
+func Test_NewWithPartitions(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		wantCount int
+	}{
+		{"Default count", numberOfPartitionsInMap, numberOfPartitionsInMap},
+		{"Single partition", 1, 1},
+		{"Odd count", 7, 7},
+		{"Zero count", 0, minPartitionsInMap},
+		{"Negative count", -5, minPartitionsInMap},
+		{"Maximum count", maxPartitionsInMap, maxPartitionsInMap},
+		{"Too large count", maxPartitionsInMap + 1, maxPartitionsInMap},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pm := NewWithPartitions[int, int](tc.count)
+			if got := len(pm.slots()); got != tc.wantCount {
+				t.Errorf("NewWithPartitions(%d) partitions = %d, want %d",
+					tc.count, got, tc.wantCount)
+			}
+
+			for i := range 1000 {
+				pm.Put(i, i*2)
+			}
+			if 1000 != pm.Len() {
+				t.Errorf("Len() = %d, want %d", pm.Len(), 1000)
+			}
+			for i := range 1000 {
+				if v, ok := pm.Get(i); !ok || (v != i*2) {
+					t.Errorf("Get(%d) = %d, %v, want %d, true",
+						i, v, ok, i*2)
+				}
+			}
+
+			// With more partitions than keys some keys share a
+			// partition by chance.
+			metrics := pm.PartitionStats()
+			if want := min(tc.wantCount, 1000); (metrics.Parts > want) || (metrics.Parts < want*9/10) {
+				t.Errorf("PartitionStats() metrics.Parts = %d, want %d",
+					metrics.Parts, want)
+			}
+			for idx := range metrics.PartKeys {
+				if idx >= tc.wantCount {
+					t.Errorf("PartitionStats() invalid partition index: %d", idx)
+				}
+			}
+		})
+	}
+
+	if got := len(New[int, int]().slots()); numberOfPartitionsInMap != got {
+		t.Errorf("New() partitions = %d, want %d",
+			got, numberOfPartitionsInMap)
+	}
+} // Test_NewWithPartitions()
+
+func Test_NewWithCapacity(t *testing.T) {
+	const numEntries = 1 << 15
+
+	bulkLoad := func(aPM *TPartitionMap[int, int]) {
+		for i := range numEntries {
+			aPM.Put(i, i)
+		}
+	}
+
+	pm := NewWithCapacity[int, int](numEntries)
+	if numEntries != pm.capacity {
+		t.Errorf("NewWithCapacity() capacity = %d, want %d",
+			pm.capacity, numEntries)
+	}
+	bulkLoad(pm)
+	if numEntries != pm.Len() {
+		t.Errorf("Len() = %d, want %d", pm.Len(), numEntries)
+	}
+
+	if pm = NewWithCapacity[int, int](-1); 0 != pm.capacity {
+		t.Errorf("NewWithCapacity(-1) capacity = %d, want %d",
+			pm.capacity, 0)
+	}
+
+	withHint := testing.AllocsPerRun(3, func() {
+		bulkLoad(NewWithCapacity[int, int](numEntries))
+	})
+	withoutHint := testing.AllocsPerRun(3, func() {
+		bulkLoad(New[int, int]())
+	})
+	if withHint >= withoutHint {
+		t.Errorf("NewWithCapacity() allocations = %v, want < %v",
+			withHint, withoutHint)
+	}
+} // Test_NewWithCapacity()
+
+func Benchmark_NewWithCapacity(b *testing.B) {
+	const numEntries = 1 << 16
+
+	b.Run("New", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			pm := New[int, int]()
+			for i := range numEntries {
+				pm.Put(i, i)
+			}
+		}
+	})
+	b.Run("NewWithCapacity", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			pm := NewWithCapacity[int, int](numEntries)
+			for i := range numEntries {
+				pm.Put(i, i)
+			}
+		}
+	})
+} // Benchmark_NewWithCapacity()
+
+func Test_NewWithHasher(t *testing.T) {
+	// Sequential IDs being multiples of 128 all end up in the
+	// same partition with a plain modulo distribution.
+	const numKeys = numberOfPartitionsInMap * 4
+
+	tests := []struct {
+		name      string
+		hasher    func(int) uint64
+		wantParts int
+		wantMax   int
+	}{
+		{
+			name: "Modulo hashing",
+			hasher: func(aKey int) uint64 {
+				return uint64(aKey) //#nosec G115
+			},
+			wantParts: 1,
+			wantMax:   numKeys,
+		},
+		{
+			name: "Custom hashing",
+			hasher: func(aKey int) uint64 {
+				return uint64(aKey / numberOfPartitionsInMap)
+			},
+			wantParts: numberOfPartitionsInMap,
+			wantMax:   numKeys / numberOfPartitionsInMap,
+		},
+		{
+			name: "Constant hashing",
+			hasher: func(aKey int) uint64 {
+				return 42
+			},
+			wantParts: 1,
+			wantMax:   numKeys,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pm := NewWithHasher[int, int](tc.hasher)
+			for i := range numKeys {
+				pm.Put(i*numberOfPartitionsInMap, i)
+			}
+
+			stats := pm.PartitionStats()
+			if stats.Parts != tc.wantParts {
+				t.Errorf("PartitionStats().Parts = %d, want %d",
+					stats.Parts, tc.wantParts)
+			}
+			if stats.MaxKeys != tc.wantMax {
+				t.Errorf("PartitionStats().MaxKeys = %d, want %d",
+					stats.MaxKeys, tc.wantMax)
+			}
+
+			// All key based operations must use the same partitions.
+			for i := range numKeys {
+				key := i * numberOfPartitionsInMap
+				if v, ok := pm.Get(key); !ok || (i != v) {
+					t.Fatalf("Get(%d) = %d, %v, want %d, true", key, v, ok, i)
+				}
+			}
+			keys := pm.Keys()
+			if got := len(pm.GetMany(keys)); numKeys != got {
+				t.Errorf("GetMany() found %d keys, want %d", got, numKeys)
+			}
+			if got := pm.Clone().DeleteAll(keys); numKeys != got {
+				t.Errorf("Clone().DeleteAll() = %d, want %d", got, numKeys)
+			}
+			if got := pm.DeleteAll(keys); numKeys != got {
+				t.Errorf("DeleteAll() = %d, want %d", got, numKeys)
+			}
+		})
+	}
+} // Test_NewWithHasher()
+
+// `tUserID` is a named key type which the built-in hashing formats
+// by `fmt`.
+type tUserID string
+
+func Test_NewWithKeyBytes(t *testing.T) {
+	const numKeys = 1000
+
+	var calls atomic.Int64
+	encode := func(aKey tUserID) []byte {
+		calls.Add(1)
+		return []byte(aKey)
+	}
+
+	pm := NewWithKeyBytes[tUserID, int](encode)
+	for i := range numKeys {
+		pm.Put(tUserID(fmt.Sprint("user", i)), i)
+	}
+	if 0 == calls.Load() {
+		t.Fatal("NewWithKeyBytes() didn't use the given function")
+	}
+	for i := range numKeys {
+		key := tUserID(fmt.Sprint("user", i))
+		if v, ok := pm.Get(key); !ok || i != v {
+			t.Fatalf("Get(%q) = (%d, %v), want (%d, true)", key, v, ok, i)
+		}
+		// The named type is placed like its underlying string.
+		if got, want := pm.index(key), PartitionIndex(string(key), numberOfPartitionsInMap); got != want {
+			t.Errorf("index(%q) = %d, want %d", key, got, want)
+		}
+	}
+	if got := pm.PartitionStats().Parts; numberOfPartitionsInMap/2 > got {
+		t.Errorf("PartitionStats().Parts = %d, want most of %d", got, numberOfPartitionsInMap)
+	}
+
+	// Copies keep the serialisation (and hence the placement).
+	clone := pm.Clone()
+	if v, ok := clone.Get("user42"); !ok || 42 != v {
+		t.Errorf("Clone().Get(\"user42\") = (%d, %v), want (42, true)", v, ok)
+	}
+
+	// Built-in key types don't use the function.
+	calls.Store(0)
+	NewWithKeyBytes[string, int](func(aKey string) []byte {
+		calls.Add(1)
+		return []byte(aKey)
+	}).Put("key", 1)
+	if 0 != calls.Load() {
+		t.Error("NewWithKeyBytes() used the function for string keys")
+	}
+
+	// A `nil` function gives the default placement.
+	dpm, npm := New[tUserID, int](), NewWithKeyBytes[tUserID, int](nil)
+	for i := range 100 {
+		key := tUserID(fmt.Sprint("user", i))
+		if dpm.index(key) != npm.index(key) {
+			t.Fatalf("NewWithKeyBytes(nil).index(%q) differs from the default", key)
+		}
+	}
+} // Test_NewWithKeyBytes()
+
+func Benchmark_NewWithKeyBytes(b *testing.B) {
+	const numKeys = 1 << 10
+
+	keys := make([]tUserID, numKeys)
+	for i := range keys {
+		keys[i] = tUserID(fmt.Sprint("user", i))
+	}
+	run := func(aPM *TPartitionMap[tUserID, int]) func(*testing.B) {
+		for i, key := range keys {
+			aPM.Put(key, i)
+		}
+		return func(b *testing.B) {
+			b.ReportAllocs()
+			for i := range b.N {
+				_, _ = aPM.Get(keys[i%numKeys])
+			}
+		}
+	}
+
+	b.Run("fmt", run(New[tUserID, int]()))
+	b.Run("KeyBytes", run(NewWithKeyBytes[tUserID, int](func(aKey tUserID) []byte {
+		return []byte(aKey)
+	})))
+} // Benchmark_NewWithKeyBytes()
+
+func Test_NewWithSeed(t *testing.T) {
+	const numKeys = 1000
+
+	keys := make([]string, numKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%04d", i)
+	}
+	placement := func(aPM *TPartitionMap[string, int]) []int {
+		result := make([]int, len(keys))
+		for i, key := range keys {
+			result[i] = aPM.index(key)
+		}
+		return result
+	}
+
+	// The same seed gives the same placement.
+	seed1 := placement(NewWithSeed[string, int](1))
+	if again := placement(NewWithSeed[string, int](1)); !slices.Equal(seed1, again) {
+		t.Error("NewWithSeed(1) placed the keys differently on second use")
+	}
+
+	// Different seeds place keys differently.
+	seed2 := placement(NewWithSeed[string, int](2))
+	moved := 0
+	for i := range keys {
+		if seed1[i] != seed2[i] {
+			moved++
+		}
+	}
+	if numKeys/2 > moved {
+		t.Errorf("seeds 1 and 2 placed %d of %d keys differently, want most", moved, numKeys)
+	}
+
+	// Seed `0` gives the default placement.
+	if got, want := placement(NewWithSeed[string, int](0)), placement(New[string, int]()); !slices.Equal(got, want) {
+		t.Error("NewWithSeed(0) differs from the default placement")
+	}
+
+	// Integer keys are spread by the seed as well.
+	ipm := NewWithSeed[int, int](42)
+	for i := range numKeys {
+		ipm.Put(i*numberOfPartitionsInMap, i)
+	}
+	if got := ipm.PartitionStats().Parts; numberOfPartitionsInMap/2 > got {
+		t.Errorf("PartitionStats().Parts = %d, want most of %d", got, numberOfPartitionsInMap)
+	}
+
+	// The seeded map and its copies work as usual.
+	pm := NewWithSeed[string, int](7)
+	for i, key := range keys {
+		pm.Put(key, i)
+	}
+	clone := pm.Clone()
+	for i, key := range keys {
+		if v, ok := clone.Get(key); !ok || i != v {
+			t.Fatalf("Clone().Get(%q) = (%d, %v), want (%d, true)", key, v, ok, i)
+		}
+	}
+} // Test_NewWithSeed()
+
+func Test_NewWithSecureHash(t *testing.T) {
+	const numKeys = 1000
+
+	// Collect keys which all land in partition 0 under CRC32.
+	keys := make([]string, 0, numKeys)
+	for i := 0; numKeys > len(keys); i++ {
+		if key := fmt.Sprintf("user%d", i); 0 == partitionIndex(key, numberOfPartitionsInMap) {
+			keys = append(keys, key)
+		}
+	}
+
+	plain := New[string, int]()
+	secure := NewWithSecureHash[string, int]()
+	for i, key := range keys {
+		plain.Put(key, i)
+		secure.Put(key, i)
+	}
+
+	if got := plain.PartitionStats().Parts; 1 != got {
+		t.Fatalf("default hashing used %d partitions, want 1", got)
+	}
+	if got := secure.PartitionStats().Parts; numberOfPartitionsInMap*3/4 > got {
+		t.Errorf("secure hashing used %d partitions, want most of %d",
+			got, numberOfPartitionsInMap)
+	}
+	for i, key := range keys {
+		if v, ok := secure.Get(key); !ok || i != v {
+			t.Fatalf("Get(%q) = (%d, %v), want (%d, true)", key, v, ok, i)
+		}
+	}
+
+	// Copies use the same placement.
+	clone := secure.Clone()
+	for i, key := range keys {
+		if v, ok := clone.Get(key); !ok || i != v {
+			t.Fatalf("Clone().Get(%q) = (%d, %v), want (%d, true)", key, v, ok, i)
+		}
+	}
+
+	// Other key types are hashed as well, except for integers.
+	fpm := NewWithSecureHash[float64, int]()
+	for i := range numKeys {
+		fpm.Put(float64(i)+0.5, i)
+	}
+	if v, ok := fpm.Get(42.5); !ok || 42 != v {
+		t.Errorf("Get(42.5) = (%d, %v), want (42, true)", v, ok)
+	}
+	ipm := NewWithSecureHash[int, int]()
+	if got, want := ipm.index(300), partitionIndex(300, numberOfPartitionsInMap); got != want {
+		t.Errorf("index(300) = %d, want %d", got, want)
+	}
+} // Test_NewWithSecureHash()
+
+func Test_NewNormalized(t *testing.T) {
+	pm := NewNormalized[string, int](strings.ToLower).
+		Put("Alice", 1).
+		Put("BOB", 2).
+		Put("carol", 3)
+
+	// Lookups with different casing:
+	for key, want := range map[string]int{"alice": 1, "ALICE": 1, "Bob": 2, "CaRoL": 3} {
+		if got, ok := pm.Get(key); !ok || (got != want) {
+			t.Errorf("Get(%q) = %d, %v, want %d, true", key, got, ok, want)
+		}
+	}
+
+	// Updates with different casing refer to the same entry:
+	pm.Put("ALICE", 10)
+	if got := pm.Len(); 3 != got {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+	if got := pm.GetOrDefault("alice", -1); 10 != got {
+		t.Errorf("GetOrDefault() = %d, want 10", got)
+	}
+
+	// Keys are returned in their normalised form:
+	if got, want := pm.Keys(), []string{"alice", "bob", "carol"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	if got, want := pm.RangeKeys("A", "BZZ"), []string{"alice", "bob"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RangeKeys() = %v, want %v", got, want)
+	}
+	if got := pm.GetMany([]string{"BoB", "Dave"}); !reflect.DeepEqual(got, map[string]int{"bob": 2}) {
+		t.Errorf("GetMany() = %v, want %v", got, map[string]int{"bob": 2})
+	}
+
+	// Other key based operations:
+	if pm.PutIfAbsent("Carol", 30) {
+		t.Errorf("PutIfAbsent() = true, want false")
+	}
+	if got, loaded := pm.LoadOrStore("CAROL", 30); !loaded || (3 != got) {
+		t.Errorf("LoadOrStore() = %d, %v, want 3, true", got, loaded)
+	}
+	if got, _ := pm.Compute("Bob", func(aOld int, aFound bool) (int, bool) {
+		return aOld * 10, false
+	}); 20 != got {
+		t.Errorf("Compute() = %d, want 20", got)
+	}
+	pm.PutAll(map[string]int{"Dave": 4})
+	if got, ok := pm.GetAndDelete("DAVE"); !ok || (4 != got) {
+		t.Errorf("GetAndDelete() = %d, %v, want 4, true", got, ok)
+	}
+	pm.Delete("CAROL")
+	if got := pm.DeleteAll([]string{"BOB", "alice"}); 2 != got {
+		t.Errorf("DeleteAll() = %d, want 2", got)
+	}
+	if got := pm.Len(); 0 != got {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+
+	// Derived maps keep the normalisation:
+	if _, ok := pm.Put("Eve", 5).Clone().Get("EVE"); !ok {
+		t.Errorf("Clone().Get() didn't find normalised key")
+	}
+} // Test_NewNormalized()
+
+func Test_FromMap(t *testing.T) {
+	tests := []struct {
+		name string
+		src  map[string]int
+	}{
+		{
+			name: "Nil source",
+			src:  nil,
+		},
+		{
+			name: "Empty source",
+			src:  map[string]int{},
+		},
+		{
+			name: "Source with values",
+			src:  map[string]int{"key1": 100, "key2": 200, "key3": 300},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pm := FromMap(tc.src)
+			if nil == pm {
+				t.Fatalf("FromMap() = nil, want non-nil")
+			}
+			if len(tc.src) != pm.Len() {
+				t.Errorf("Len() = %d, want %d", pm.Len(), len(tc.src))
+			}
+			for k, want := range tc.src {
+				if got, ok := pm.Get(k); !ok || (got != want) {
+					t.Errorf("Get(%q) = %d, %v, want %d, true",
+						k, got, ok, want)
+				}
+			}
+			if nil == tc.src {
+				return
+			}
+
+			// Modifying the source must not affect the map.
+			tc.src["key4"] = 400
+			delete(tc.src, "key1")
+			if _, ok := pm.Get("key4"); ok {
+				t.Errorf("Put() to source affected the map")
+			}
+			if 0 < pm.Len() {
+				if _, ok := pm.Get("key1"); !ok {
+					t.Errorf("delete() from source affected the map")
+				}
+			}
+		})
+	}
+
+	// All keys must be found in their proper partitions.
+	src := make(map[int]int, 10000)
+	for i := range 10000 {
+		src[i-5000] = i
+	}
+	pm := FromMap(src)
+	if got := pm.ToMap(); !reflect.DeepEqual(got, src) {
+		t.Errorf("FromMap().ToMap() differs from source")
+	}
+	for k := range src {
+		pm.Delete(k)
+	}
+	if 0 != pm.Len() {
+		t.Errorf("After deleting all keys, Len() = %d, want %d",
+			pm.Len(), 0)
+	}
+} // Test_FromMap()
+
 func Test_TPartitionMap_partition(t *testing.T) {
 	type tArgs struct {
 		aKey    string
@@ -204,114 +902,230 @@ func Test_TPartitionMap_partition(t *testing.T) {
 	}
 } // Test_TPartitionMap_partition()
 
-func Test_TPartitionMap_Clear(t *testing.T) {
+func Test_TPartitionMap_AllMatch(t *testing.T) {
+	newMap := func() *TPartitionMap[int, int] {
+		pm := New[int, int]()
+		for i := range 1000 {
+			pm.Put(i, i)
+		}
+		return pm
+	}
+
 	tests := []struct {
 		name string
-		pm   *TPartitionMap[string, int]
+		pm   *TPartitionMap[int, int]
+		pred func(int, int) bool
+		want bool
 	}{
 		{
-			name: "Empty partition map",
-			pm:   New[string, int](),
+			name: "All match",
+			pm:   newMap(),
+			pred: func(aKey, aValue int) bool { return 0 <= aValue },
+			want: true,
 		},
 		{
-			name: "Partition map with values",
-			pm: New[string, int]().
-				Put("key1", 100).
-				Put("key2", 200).
-				Put("key3", 300),
+			name: "Some match",
+			pm:   newMap(),
+			pred: func(aKey, aValue int) bool { return 500 > aValue },
+			want: false,
+		},
+		{
+			name: "None match",
+			pm:   newMap(),
+			pred: func(aKey, aValue int) bool { return 0 > aValue },
+			want: false,
+		},
+		{
+			name: "Nil predicate",
+			pm:   newMap(),
+			pred: nil,
+			want: true,
+		},
+		{
+			name: "Empty partition map",
+			pm:   New[int, int](),
+			pred: func(aKey, aValue int) bool { return false },
+			want: true,
 		},
 		{
 			name: "Nil partition map",
 			pm:   nil,
+			pred: func(aKey, aValue int) bool { return false },
+			want: true,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			// Store original map for nil check
-			originalPM := tc.pm
-
-			// Call Clear method
-			got := tc.pm.Clear()
-
-			// Check if result is the same instance as input
-			if got != originalPM {
-				t.Errorf("Clear() returned different instance, got %v, want %v",
-					got, originalPM)
-			}
-
-			// For nil case, verify no changes
-			if nil == tc.pm {
-				return
+			if got := tc.pm.AllMatch(tc.pred); got != tc.want {
+				t.Errorf("AllMatch() = %v, want %v", got, tc.want)
 			}
+		})
+	}
 
-			// Verify all key/value pairs were removed
-			if 0 != tc.pm.Len() {
-				t.Errorf("After Clear(), expected length 0, got %d",
-					tc.pm.Len())
-			}
+	// The check must stop at the first non-matching pair.
+	calls := 0
+	newMap().AllMatch(func(aKey, aValue int) bool {
+		calls++
+		return false
+	})
+	if 1 != calls {
+		t.Errorf("AllMatch() called predicate %d times, want %d",
+			calls, 1)
+	}
+} // Test_TPartitionMap_AllMatch()
 
-			// Verify Keys() returns empty slice
-			keys := tc.pm.Keys()
-			if 0 != len(keys) {
-				t.Errorf("After Clear(), expected empty keys slice, got %v",
-					keys)
-			}
-		})
+func Test_TPartitionMap_AnyMatch(t *testing.T) {
+	newMap := func() *TPartitionMap[int, int] {
+		pm := New[int, int]()
+		for i := range 1000 {
+			pm.Put(i, i)
+		}
+		return pm
 	}
-} // Test_TPartitionMap_Clear()
 
-func Test_TPartitionMap_Delete(t *testing.T) {
 	tests := []struct {
 		name string
-		pm   *TPartitionMap[string, int]
-		key  string
+		pm   *TPartitionMap[int, int]
+		pred func(int, int) bool
+		want bool
 	}{
 		{
-			name: "Delete existing key",
-			pm: func() *TPartitionMap[string, int] {
-				return New[string, int]().Put("testKey", 42)
-			}(),
-			key: "testKey",
+			name: "All match",
+			pm:   newMap(),
+			pred: func(aKey, aValue int) bool { return 0 <= aValue },
+			want: true,
 		},
 		{
-			name: "Delete non-existent key",
-			pm:   New[string, int](),
-			key:  "nonExistentKey",
+			name: "One matches",
+			pm:   newMap(),
+			pred: func(aKey, aValue int) bool { return 777 == aValue },
+			want: true,
+		},
+		{
+			name: "None match",
+			pm:   newMap(),
+			pred: func(aKey, aValue int) bool { return 0 > aValue },
+			want: false,
+		},
+		{
+			name: "Nil predicate",
+			pm:   newMap(),
+			pred: nil,
+			want: false,
+		},
+		{
+			name: "Empty partition map",
+			pm:   New[int, int](),
+			pred: func(aKey, aValue int) bool { return true },
+			want: false,
 		},
 		{
 			name: "Nil partition map",
 			pm:   nil,
-			key:  "anyKey",
+			pred: func(aKey, aValue int) bool { return true },
+			want: false,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			// Store original map for nil check
-			originalPM := tc.pm
+			if got := tc.pm.AnyMatch(tc.pred); got != tc.want {
+				t.Errorf("AnyMatch() = %v, want %v", got, tc.want)
+			}
+		})
+	}
 
-			// Call Delete method
-			got := tc.pm.Delete(tc.key)
+	// The check must stop at the first matching pair.
+	calls := 0
+	newMap().AnyMatch(func(aKey, aValue int) bool {
+		calls++
+		return true
+	})
+	if 1 != calls {
+		t.Errorf("AnyMatch() called predicate %d times, want %d",
+			calls, 1)
+	}
+} // Test_TPartitionMap_AnyMatch()
 
-			// Check if result is the same instance as input
-			if got != originalPM {
-				t.Errorf("Delete() returned different instance, got %v, want %v",
-					got, originalPM)
-			}
+func Test_TPartitionMap_ApproxLen(t *testing.T) {
+	var npm *TPartitionMap[int, int]
+	if got := npm.ApproxLen(); 0 != got {
+		t.Errorf("ApproxLen() on nil map = %d, want 0", got)
+	}
 
-			// Check if key exists after deletion
-			if tc.pm != nil {
-				if _, exists := tc.pm.Get(tc.key); exists {
-					t.Errorf("After Delete(), key existence = %q, want %v",
-						tc.key, false)
+	pm := New[int, int]()
+	if got := pm.ApproxLen(); 0 != got {
+		t.Errorf("ApproxLen() on empty map = %d, want 0", got)
+	}
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				pm.Put(g*1000+i, i)
+				if 0 == i%3 {
+					pm.Delete(g*1000 + i)
 				}
 			}
+		}()
+	}
+	wg.Wait()
+
+	// Without concurrent writers the estimate is exact.
+	if got, want := pm.ApproxLen(), pm.Len(); want != got {
+		t.Errorf("ApproxLen() = %d, want %d", got, want)
+	}
+	pm.Clear()
+	if got := pm.ApproxLen(); 0 != got {
+		t.Errorf("ApproxLen() after Clear() = %d, want 0", got)
+	}
+} // Test_TPartitionMap_ApproxLen()
+
+func Test_TPartitionMap_BusiestPartition(t *testing.T) {
+	// A modulo hasher places the integer keys by their value
+	// modulo the number of partitions.
+	modulo := func(aKey int) uint64 {
+		return uint64(aKey) //#nosec G115
+	}
+	skewed := NewWithPartitions[int, int](8).SetHasher(modulo)
+	for i := range 40 {
+		skewed.Put(i, i) // 5 keys per partition
+	}
+	skewed.Put(43, 43).Put(51, 51) // partition 3 holds 7 keys
+
+	tied := NewWithPartitions[int, int](8).SetHasher(modulo).
+		Put(6, 6).Put(14, 14).Put(2, 2).Put(10, 10).Put(1, 1)
+
+	tests := []struct {
+		name     string
+		pm       *TPartitionMap[int, int]
+		wantIdx  int
+		wantKeys []int
+	}{
+		{"Skewed map", skewed, 3, []int{3, 11, 19, 27, 35, 43, 51}},
+		{"Tie broken by lowest index", tied, 2, []int{2, 10}},
+		{"Empty map", New[int, int](), -1, nil},
+		{"Cleared map", NewWithPartitions[int, int](8).Put(1, 1).Clear(), -1, nil},
+		{"Nil map", nil, -1, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			idx, keys := tc.pm.BusiestPartition()
+			if tc.wantIdx != idx {
+				t.Errorf("BusiestPartition() index = %d, want %d", idx, tc.wantIdx)
+			}
+			if !slices.Equal(keys, tc.wantKeys) {
+				t.Errorf("BusiestPartition() keys = %v, want %v", keys, tc.wantKeys)
+			}
 		})
 	}
-} // Test_TPartitionMap_Delete()
+} // Test_TPartitionMap_BusiestPartition()
 
-func Test_TPartitionMap_ForEach(t *testing.T) {
+func Test_TPartitionMap_Clear(t *testing.T) {
 	tests := []struct {
 		name string
 		pm   *TPartitionMap[string, int]
@@ -335,246 +1149,564 @@ func Test_TPartitionMap_ForEach(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			// Create a map to store visited key-value pairs
-			visited := make(map[string]int)
+			// Store original map for nil check
+			originalPM := tc.pm
 
-			// Call ForEach to collect all key-value pairs
-			got := tc.pm.ForEach(func(key string, value int) {
-				visited[key] = value
-			})
+			// Call Clear method
+			got := tc.pm.Clear()
 
-			// Verify the result is the same instance as input
-			if got != tc.pm {
-				t.Errorf("ForEach() returned different instance, got %v, want %v",
-					got, tc.pm)
+			// Check if result is the same instance as input
+			if got != originalPM {
+				t.Errorf("Clear() returned different instance, got %v, want %v",
+					got, originalPM)
 			}
 
-			// For nil case, verify no function calls occurred
-			if tc.pm == nil {
-				if 0 < len(visited) {
-					t.Errorf("ForEach() should not call function for nil partition map")
-				}
+			// For nil case, verify no changes
+			if nil == tc.pm {
 				return
 			}
 
-			// For non-nil case, verify all key-value pairs were visited
-			keys := tc.pm.Keys()
-			if len(visited) != len(keys) {
-				t.Errorf("Expected %d key-value pairs to be visited, got %d",
-					len(keys), len(visited))
+			// Verify all key/value pairs were removed
+			if 0 != tc.pm.Len() {
+				t.Errorf("After Clear(), expected length 0, got %d",
+					tc.pm.Len())
 			}
 
-			// Verify each key-value pair was visited correctly
-			for _, key := range keys {
-				expectedValue, _ := tc.pm.Get(key)
-				if value, ok := visited[key]; !ok || value != expectedValue {
-					t.Errorf("Expected key %q with value %d, got %v, exists: %v",
-						key, expectedValue, value, ok)
-				}
+			// Verify Keys() returns empty slice
+			keys := tc.pm.Keys()
+			if 0 != len(keys) {
+				t.Errorf("After Clear(), expected empty keys slice, got %v",
+					keys)
 			}
 		})
 	}
-} // Test_TPartitionMap_ForEach()
+} // Test_TPartitionMap_Clear()
 
-func Test_TPartitionMap_Get(t *testing.T) {
-	tests := []struct {
-		name      string
-		pm        *TPartitionMap[string, int]
-		key       string
-		wantValue int
-		wantFound bool
-	}{
-		{
-			name:      "Get existing key",
-			pm:        New[string, int]().Put("testKey", 42),
-			key:       "testKey",
-			wantValue: 42,
-			wantFound: true,
-		},
-		{
-			name:      "Get non-existent key",
-			pm:        New[string, int](),
-			key:       "nonExistentKey",
-			wantValue: 0,
-			wantFound: false,
-		},
-		{
-			name:      "Nil partition map",
-			pm:        nil,
-			key:       "anyKey",
-			wantValue: 0,
-			wantFound: false,
-		},
+func Test_TPartitionMap_Clear_Concurrent(t *testing.T) {
+	const numKeys = 1000
+
+	pm := New[int, int]()
+	for i := range numKeys {
+		pm.Put(i, i)
 	}
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			gotValue, gotFound := tc.pm.Get(tc.key)
-			if gotValue != tc.wantValue {
-				t.Errorf("Get() value = %v, want %v",
-					gotValue, tc.wantValue)
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for g := range 4 {
+		wg.Add(2)
+		go func() { // reader
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if v, ok := pm.Get(i % numKeys); ok && (v != i%numKeys) {
+					t.Errorf("Get(%d) = %d during Clear()", i%numKeys, v)
+					return
+				}
+				_ = pm.Len()
 			}
-			if gotFound != tc.wantFound {
-				t.Errorf("Get() found = %v, want %v",
-					gotFound, tc.wantFound)
+		}()
+		go func() { // writer
+			defer wg.Done()
+			for i := g; ; i += 4 {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				pm.Put(i%numKeys, i%numKeys)
 			}
-		})
+		}()
 	}
-} // Test_TPartitionMap_Get()
+	for range 200 {
+		pm.Clear()
+	}
+	close(done)
+	wg.Wait()
 
-func Test_TPartitionMap_GetOrDefault(t *testing.T) {
-	tests := []struct {
-		name     string
-		pm       *TPartitionMap[string, int]
-		key      string
-		defValue int
-		want     int
-	}{
-		{
-			name:     "Get existing key",
-			pm:       New[string, int]().Put("testKey", 42),
-			key:      "testKey",
-			defValue: -1,
-			want:     42,
-		},
-		{
-			name:     "Get non-existent key",
-			pm:       New[string, int](),
-			key:      "nonExistentKey",
-			defValue: 99,
-			want:     99,
-		},
-		{
-			name:     "Nil partition map",
-			pm:       nil,
-			key:      "anyKey",
-			defValue: 123,
-			want:     123,
-		},
+	recount(t, pm)
+	if pm.Clear(); 0 != pm.Len() {
+		t.Errorf("Len() = %d after Clear(), want 0", pm.Len())
 	}
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			got := tc.pm.GetOrDefault(tc.key, tc.defValue)
-			if got != tc.want {
-				t.Errorf("GetOrDefault() = '%v', want '%v'",
-					got, tc.want)
-			}
-		})
+	// While one partition is locked, `Clear()` waits for it but
+	// doesn't block accesses to the other partitions.
+	pm.Put(1, 1).Put(2, 2)
+	busy := pm.slots()[pm.index(1)].Load()
+	other := pm.index(2)
+	if pm.index(1) == other {
+		t.Fatal("keys 1 and 2 share a partition")
 	}
-} // Test_TPartitionMap_GetOrDefault()
+	busy.Lock()
+	cleared := make(chan struct{})
+	go func() {
+		pm.Clear()
+		close(cleared)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	got := make(chan bool)
+	go func() {
+		_, ok := pm.Get(2)
+		got <- ok
+	}()
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatal("Get() blocked by Clear()")
+	}
+	busy.Unlock()
+	<-cleared
+	if 0 != pm.Len() {
+		t.Errorf("Len() = %d after Clear(), want 0", pm.Len())
+	}
+} // Test_TPartitionMap_Clear_Concurrent()
 
-func Test_TPartitionMap_Keys(t *testing.T) {
+func Test_TPartitionMap_Clone(t *testing.T) {
 	tests := []struct {
 		name string
 		pm   *TPartitionMap[string, int]
-		want []string
 	}{
 		{
 			name: "Empty partition map",
 			pm:   New[string, int](),
-			want: []string{},
 		},
 		{
-			name: "Partition map with keys",
+			name: "Partition map with values",
 			pm: New[string, int]().
 				Put("key1", 100).
 				Put("key2", 200).
 				Put("key3", 300),
-			want: []string{"key1", "key2", "key3"},
+		},
+		{
+			name: "Custom partition count",
+			pm: NewWithPartitions[string, int](3).
+				Put("key1", 100).
+				Put("key2", 200),
 		},
 		{
 			name: "Nil partition map",
 			pm:   nil,
-			want: nil,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := tc.pm.Keys()
-			if !reflect.DeepEqual(got, tc.want) {
-				t.Errorf("Keys() = %v, want %v",
-					got, tc.want)
+			got := tc.pm.Clone()
+			if nil == tc.pm {
+				if nil != got {
+					t.Errorf("Clone() = %v, want nil", got)
+				}
+				return
+			}
+
+			if got == tc.pm {
+				t.Errorf("Clone() returned the same instance")
+			}
+			if len(got.slots()) != len(tc.pm.slots()) {
+				t.Errorf("Clone() partitions = %d, want %d",
+					len(got.slots()), len(tc.pm.slots()))
+			}
+			if !reflect.DeepEqual(got.Entries(), tc.pm.Entries()) {
+				t.Errorf("Clone() Entries() = %v, want %v",
+					got.Entries(), tc.pm.Entries())
+			}
+
+			// Modifications must not affect each other.
+			origLen := tc.pm.Len()
+			got.Put("cloneOnly", 1).Delete("key1")
+			if _, ok := tc.pm.Get("cloneOnly"); ok {
+				t.Errorf("Put() to clone affected the original")
+			}
+			if origLen != tc.pm.Len() {
+				t.Errorf("original Len() = %d, want %d",
+					tc.pm.Len(), origLen)
+			}
+			tc.pm.Put("origOnly", 2)
+			if _, ok := got.Get("origOnly"); ok {
+				t.Errorf("Put() to original affected the clone")
 			}
 		})
 	}
-} // Test_TPartitionMap_Keys()
+} // Test_TPartitionMap_Clone()
+
+func Test_TPartitionMap_CloneFunc(t *testing.T) {
+	pm := New[string, []int]()
+	for i := range 100 {
+		pm.Put(fmt.Sprint("key", i), []int{i, i * 2})
+	}
+
+	deep := pm.CloneFunc(slices.Clone[[]int])
+	shallow := pm.CloneFunc(nil)
+	if !reflect.DeepEqual(pm.ToMap(), deep.ToMap()) {
+		t.Fatal("CloneFunc() copy differs from the original")
+	}
+	if len(deep.slots()) != len(pm.slots()) {
+		t.Errorf("CloneFunc() has %d partitions, want %d",
+			len(deep.slots()), len(pm.slots()))
+	}
+	recount(t, deep)
+
+	// Mutating the deep copy's slices doesn't affect the original.
+	deep.ForEach(func(aKey string, aValue []int) {
+		aValue[0] = -1
+	})
+	for i := range 100 {
+		key := fmt.Sprint("key", i)
+		if got, _ := pm.Get(key); i != got[0] {
+			t.Fatalf("original Get(%q)[0] = %d after mutating the copy, want %d",
+				key, got[0], i)
+		}
+		if got, _ := deep.Get(key); -1 != got[0] {
+			t.Fatalf("copy Get(%q)[0] = %d, want -1", key, got[0])
+		}
+	}
+
+	// A `nil` function gives a shallow copy sharing the slices.
+	shallow.ForEach(func(aKey string, aValue []int) {
+		aValue[1] = -2
+	})
+	if got, _ := pm.Get("key7"); -2 != got[1] {
+		t.Errorf("CloneFunc(nil) didn't share the slices: got %v", got)
+	}
+
+	var npm *TPartitionMap[string, []int]
+	if nil != npm.CloneFunc(slices.Clone[[]int]) {
+		t.Error("CloneFunc() on nil map should return nil")
+	}
+} // Test_TPartitionMap_CloneFunc()
+
+func Test_TPartitionMap_CompactSparse(t *testing.T) {
+	const threshold = 300
+
+	pm := NewWithPartitions[int, int](128)
+	check := func(aStep string, aWantCount int) {
+		t.Helper()
+		if got := len(pm.slots()); aWantCount != got {
+			t.Errorf("%s: %d partitions, want %d", aStep, got, aWantCount)
+		}
+		for i := range pm.Len() {
+			if got, ok := pm.Get(i); !ok || got != i {
+				t.Fatalf("%s: Get(%d) = (%d, %v), want (%d, true)",
+					aStep, i, got, ok, i)
+			}
+		}
+		recount(t, pm)
+	}
+	fill := func(aTo int) {
+		for i := pm.Len(); i < aTo; i++ {
+			pm.Put(i, i)
+		}
+	}
+	shrink := func(aTo int) {
+		for i := pm.Len() - 1; i >= aTo; i-- {
+			pm.Delete(i)
+		}
+	}
+
+	fill(threshold)
+	if got := pm.CompactSparse(threshold); got != pm {
+		t.Fatal("CompactSparse() returned different instance")
+	}
+	check("At threshold", 128)
+
+	shrink(threshold - 1)
+	pm.CompactSparse(threshold)
+	check("Below threshold", 1)
+
+	// Growing within the hysteresis band keeps the map compacted.
+	fill(2*threshold - 1)
+	pm.CompactSparse(threshold)
+	check("Within hysteresis", 1)
+
+	fill(2 * threshold)
+	pm.CompactSparse(threshold)
+	check("Above hysteresis", 128)
+
+	// Shrinking within the hysteresis band keeps the map expanded.
+	shrink(threshold)
+	pm.CompactSparse(threshold)
+	check("Shrunk to threshold", 128)
+
+	shrink(10)
+	pm.CompactSparse(threshold).CompactSparse(threshold)
+	check("Shrunk again", 1)
+
+	pm.CompactSparse(0)
+	check("Zero threshold", 128)
+
+	// An explicit `Resize()` discards the remembered layout.
+	pm.CompactSparse(threshold).Resize(4)
+	fill(4 * threshold)
+	pm.CompactSparse(threshold)
+	check("After Resize()", 4)
+
+	single := NewWithPartitions[int, int](1).Put(1, 1)
+	single.CompactSparse(threshold)
+	if 0 != single.sparse {
+		t.Error("CompactSparse() compacted a single-partition map")
+	}
+
+	var npm *TPartitionMap[int, int]
+	if nil != npm.CompactSparse(threshold) {
+		t.Error("CompactSparse() on nil map should return nil")
+	}
+} // Test_TPartitionMap_CompactSparse()
+
+func Test_TPartitionMap_CompactSparse_Concurrent(t *testing.T) {
+	const (
+		numWriters = 4
+		numKeys    = 200
+		threshold  = 300
+	)
+
+	// The writers let the map's size swing across both thresholds
+	// while the map is compacted and expanded again and again.
+	pm := NewWithPartitions[int, int](64)
+	var (
+		done atomic.Bool
+		wg   sync.WaitGroup
+	)
+	for g := range numWriters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; (5 > round) || !done.Load(); round++ {
+				for i := range numKeys {
+					key := g*numKeys + i
+					pm.Put(key, round)
+					if v, ok := pm.Get(key); !ok || round != v {
+						t.Errorf("Get(%d) = (%d, %v), want (%d, true)", key, v, ok, round)
+						return
+					}
+				}
+				for i := range numKeys {
+					if _, ok := pm.GetAndDelete(g*numKeys + i); !ok {
+						t.Errorf("GetAndDelete(%d) found nothing", g*numKeys+i)
+						return
+					}
+				}
+			}
+		}()
+	}
+	for range 50 {
+		pm.CompactSparse(threshold)
+	}
+	done.Store(true)
+	wg.Wait()
+
+	if got := pm.Len(); 0 != got {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+	recount(t, pm)
+} // Test_TPartitionMap_CompactSparse_Concurrent()
+
+func Test_TPartitionMap_Compute(t *testing.T) {
+	increment := func(aOld int, aFound bool) (int, bool) {
+		return aOld + 1, false
+	}
+	remove := func(aOld int, aFound bool) (int, bool) {
+		return 0, true
+	}
+
+	tests := []struct {
+		name      string
+		pm        *TPartitionMap[string, int]
+		key       string
+		fn        func(int, bool) (int, bool)
+		wantValue int
+		wantOk    bool
+	}{
+		{
+			name:      "Increment new key",
+			pm:        New[string, int](),
+			key:       "counter",
+			fn:        increment,
+			wantValue: 1,
+			wantOk:    true,
+		},
+		{
+			name:      "Increment existing key",
+			pm:        New[string, int]().Put("counter", 41),
+			key:       "counter",
+			fn:        increment,
+			wantValue: 42,
+			wantOk:    true,
+		},
+		{
+			name:      "Delete existing key",
+			pm:        New[string, int]().Put("counter", 41),
+			key:       "counter",
+			fn:        remove,
+			wantValue: 0,
+			wantOk:    false,
+		},
+		{
+			name:      "Delete non-existent key",
+			pm:        New[string, int](),
+			key:       "counter",
+			fn:        remove,
+			wantValue: 0,
+			wantOk:    false,
+		},
+		{
+			name:      "Nil function",
+			pm:        New[string, int]().Put("counter", 41),
+			key:       "counter",
+			fn:        nil,
+			wantValue: 0,
+			wantOk:    false,
+		},
+		{
+			name:      "Nil partition map",
+			pm:        nil,
+			key:       "counter",
+			fn:        increment,
+			wantValue: 0,
+			wantOk:    false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotValue, gotOk := tc.pm.Compute(tc.key, tc.fn)
+			if gotValue != tc.wantValue {
+				t.Errorf("Compute() value = %v, want %v",
+					gotValue, tc.wantValue)
+			}
+			if gotOk != tc.wantOk {
+				t.Errorf("Compute() ok = %v, want %v",
+					gotOk, tc.wantOk)
+			}
+
+			if (nil == tc.pm) || (nil == tc.fn) {
+				return
+			}
+			if _, exists := tc.pm.Get(tc.key); exists != tc.wantOk {
+				t.Errorf("After Compute(), key existence = %v, want %v",
+					exists, tc.wantOk)
+			}
+		})
+	}
+} // Test_TPartitionMap_Compute()
+
+func Test_TPartitionMap_Compute_Concurrent(t *testing.T) {
+	const (
+		numGoroutines = 1 << 6
+		numIncrements = 1 << 8
+	)
+
+	pm := New[string, []int]()
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+	for i := range numGoroutines {
+		go func(aID int) {
+			defer wg.Done()
+			for range numIncrements {
+				pm.Compute("list", func(aOld []int, aFound bool) ([]int, bool) {
+					return append(aOld, aID), false
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got, _ := pm.Get("list")
+	if want := numGoroutines * numIncrements; len(got) != want {
+		t.Errorf("Compute() appended %d values, want %d",
+			len(got), want)
+	}
+} // Test_TPartitionMap_Compute_Concurrent()
+
+func Test_TPartitionMap_Count(t *testing.T) {
+	isEven := func(aKey int, aValue string) bool {
+		return 0 == aKey%2
+	}
+	newMap := func() *TPartitionMap[int, string] {
+		pm := New[int, string]()
+		for i := range 1000 {
+			pm.Put(i, fmt.Sprint(i))
+		}
+		return pm
+	}
 
-func Test_TPartitionMap_Len(t *testing.T) {
 	tests := []struct {
 		name string
-		pm   *TPartitionMap[string, int]
+		pm   *TPartitionMap[int, string]
+		pred func(int, string) bool
 		want int
 	}{
 		{
-			name: "Empty partition map",
-			pm:   New[string, int](),
+			name: "Count subset",
+			pm:   newMap(),
+			pred: isEven,
+			want: 500,
+		},
+		{
+			name: "Count all",
+			pm:   newMap(),
+			pred: func(int, string) bool { return true },
+			want: 1000,
+		},
+		{
+			name: "Count none",
+			pm:   newMap(),
+			pred: func(int, string) bool { return false },
 			want: 0,
 		},
 		{
-			name: "Partition map with one key",
-			pm:   New[string, int]().Put("key1", 100),
-			want: 1,
+			name: "Nil predicate counts all",
+			pm:   newMap(),
+			pred: nil,
+			want: 1000,
 		},
 		{
-			name: "Partition map with multiple keys",
-			pm: New[string, int]().
-				Put("key1", 100).
-				Put("key2", 200).
-				Put("key3", 300),
-			want: 3,
+			name: "Empty partition map",
+			pm:   New[int, string](),
+			pred: isEven,
+			want: 0,
 		},
 		{
 			name: "Nil partition map",
 			pm:   nil,
+			pred: isEven,
 			want: 0,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := tc.pm.Len()
-			if got != tc.want {
-				t.Errorf("Len() = %v, want %v",
-					got, tc.want)
+			if got := tc.pm.Count(tc.pred); got != tc.want {
+				t.Errorf("Count() = %d, want %d", got, tc.want)
 			}
 		})
 	}
-} // Test_TPartitionMap_Len()
+} // Test_TPartitionMap_Count()
 
-func Test_TPartitionMap_Put(t *testing.T) {
+func Test_TPartitionMap_Delete(t *testing.T) {
 	tests := []struct {
-		name      string
-		pm        *TPartitionMap[string, int]
-		key       string
-		value     int
-		wantValue int
-		wantFound bool
+		name string
+		pm   *TPartitionMap[string, int]
+		key  string
 	}{
 		{
-			name:      "Add new key-value pair",
-			pm:        New[string, int](),
-			key:       "newKey",
-			value:     42,
-			wantValue: 42,
-			wantFound: true,
+			name: "Delete existing key",
+			pm: func() *TPartitionMap[string, int] {
+				return New[string, int]().Put("testKey", 42)
+			}(),
+			key: "testKey",
 		},
 		{
-			name:      "Update existing key",
-			pm:        New[string, int]().Put("existingKey", 100),
-			key:       "existingKey",
-			value:     200,
-			wantValue: 200,
-			wantFound: true,
+			name: "Delete non-existent key",
+			pm:   New[string, int](),
+			key:  "nonExistentKey",
 		},
 		{
-			name:      "Nil partition map",
-			pm:        nil,
-			key:       "anyKey",
-			value:     42,
-			wantValue: 0,
-			wantFound: false,
+			name: "Nil partition map",
+			pm:   nil,
+			key:  "anyKey",
 		},
 	}
 
@@ -583,281 +1715,3350 @@ func Test_TPartitionMap_Put(t *testing.T) {
 			// Store original map for nil check
 			originalPM := tc.pm
 
-			// Call Put method
-			result := tc.pm.Put(tc.key, tc.value)
+			// Call Delete method
+			got := tc.pm.Delete(tc.key)
 
 			// Check if result is the same instance as input
-			if result != originalPM {
-				t.Errorf("Put() returned different instance, got %v, want %v",
-					result, originalPM)
+			if got != originalPM {
+				t.Errorf("Delete() returned different instance, got %v, want %v",
+					got, originalPM)
 			}
 
-			// Check if value was stored correctly (except for nil case)
-			if nil != tc.pm {
-				gotValue, gotFound := tc.pm.Get(tc.key)
-				if gotValue != tc.wantValue {
-					t.Errorf("After Put(), value = %v, want %v",
-						gotValue, tc.wantValue)
-				}
-				if gotFound != tc.wantFound {
-					t.Errorf("After Put(), found = %v, want %v",
-						gotFound, tc.wantFound)
+			// Check if key exists after deletion
+			if tc.pm != nil {
+				if _, exists := tc.pm.Get(tc.key); exists {
+					t.Errorf("After Delete(), key existence = %q, want %v",
+						tc.key, false)
 				}
 			}
 		})
 	}
-} // Test_TPartitionMap_Put()
+} // Test_TPartitionMap_Delete()
 
-func Test_TPartitionMap_StressTest_Int64Keys(t *testing.T) {
-	// This test is designed to:
-	//
-	// 1. Run multiple goroutines concurrently to test thread safety with int64 keys.
-	// 2. Exercise all `TPartitionMap` methods with int64 keys and string values.
-	// 3. Perform a mix of operations to simulate real-world usage patterns.
-	// 4. Verify the map remains functional after stress testing.
-	// 5. Report performance metrics (operations per second).
-	//
-	// The test can be skipped in short mode with `go test -short` for
-	// quicker test runs.
-	if testing.Short() {
-		t.Skip("Skipping int64 keys stress test in short mode")
+func Test_TPartitionMap_DeleteAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		pm       *TPartitionMap[string, int]
+		keys     []string
+		want     int
+		wantKeys []string
+	}{
+		{
+			name:     "All keys present",
+			pm:       New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			keys:     []string{"a", "c"},
+			want:     2,
+			wantKeys: []string{"b"},
+		},
+		{
+			name:     "Some keys absent",
+			pm:       New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			keys:     []string{"a", "x", "c", "y"},
+			want:     2,
+			wantKeys: []string{"b"},
+		},
+		{
+			name:     "Duplicate keys",
+			pm:       New[string, int]().Put("a", 1).Put("b", 2),
+			keys:     []string{"a", "a", "a"},
+			want:     1,
+			wantKeys: []string{"b"},
+		},
+		{
+			name:     "No keys",
+			pm:       New[string, int]().Put("a", 1),
+			keys:     nil,
+			want:     0,
+			wantKeys: []string{"a"},
+		},
+		{
+			name:     "Empty partition map",
+			pm:       New[string, int](),
+			keys:     []string{"a", "b"},
+			want:     0,
+			wantKeys: []string{},
+		},
+		{
+			name:     "Nil partition map",
+			pm:       nil,
+			keys:     []string{"a"},
+			want:     0,
+			wantKeys: nil,
+		},
 	}
 
-	const (
-		numOperations = 1 << 16
-		numGoroutines = 1 << 8
-	)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pm.DeleteAll(tc.keys); got != tc.want {
+				t.Errorf("DeleteAll() = %d, want %d", got, tc.want)
+			}
+			if got := tc.pm.Keys(); !reflect.DeepEqual(got, tc.wantKeys) {
+				t.Errorf("After DeleteAll(), Keys() = %v, want %v",
+					got, tc.wantKeys)
+			}
+		})
+	}
+} // Test_TPartitionMap_DeleteAll()
 
-	pm := New[int64, string]()
-	var wg sync.WaitGroup
-	wg.Add(numGoroutines)
+func Benchmark_TPartitionMap_DeleteAll(b *testing.B) {
+	const numEntries = 100_000
 
-	// Create a channel to coordinate operations
-	ops := make(chan int, numOperations)
-	for i := range numOperations {
-		ops <- i
+	source := make(map[int]int, numEntries)
+	keys := make([]int, 0, numEntries)
+	for i := range numEntries {
+		source[i] = i
+		keys = append(keys, i)
 	}
-	close(ops)
 
-	// Create a function to run operations concurrently
-	runOperations := func(id int) {
+	b.Run("Delete", func(b *testing.B) {
+		for range b.N {
+			b.StopTimer()
+			pm := New[int, int]().PutAll(source)
+			b.StartTimer()
+			for _, k := range keys {
+				pm.Delete(k)
+			}
+		}
+	})
+	b.Run("DeleteAll", func(b *testing.B) {
+		for range b.N {
+			b.StopTimer()
+			pm := New[int, int]().PutAll(source)
+			b.StartTimer()
+			pm.DeleteAll(keys)
+		}
+	})
+} // Benchmark_TPartitionMap_DeleteAll()
+
+func Test_TPartitionMap_DeleteIf(t *testing.T) {
+	isEven := func(aKey int, aValue string) bool {
+		return 0 == aKey%2
+	}
+	newMap := func() *TPartitionMap[int, string] {
+		pm := New[int, string]()
+		for i := range 1000 {
+			pm.Put(i, fmt.Sprint(i))
+		}
+		return pm
+	}
+
+	tests := []struct {
+		name      string
+		pm        *TPartitionMap[int, string]
+		pred      func(int, string) bool
+		wantCount int
+		wantLen   int
+	}{
+		{
+			name:      "Delete subset",
+			pm:        newMap(),
+			pred:      isEven,
+			wantCount: 500,
+			wantLen:   500,
+		},
+		{
+			name:      "Delete everything",
+			pm:        newMap(),
+			pred:      func(int, string) bool { return true },
+			wantCount: 1000,
+			wantLen:   0,
+		},
+		{
+			name:      "Delete nothing",
+			pm:        newMap(),
+			pred:      func(int, string) bool { return false },
+			wantCount: 0,
+			wantLen:   1000,
+		},
+		{
+			name:      "Nil predicate",
+			pm:        newMap(),
+			pred:      nil,
+			wantCount: 0,
+			wantLen:   1000,
+		},
+		{
+			name:      "Empty partition map",
+			pm:        New[int, string](),
+			pred:      isEven,
+			wantCount: 0,
+			wantLen:   0,
+		},
+		{
+			name:      "Nil partition map",
+			pm:        nil,
+			pred:      isEven,
+			wantCount: 0,
+			wantLen:   0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pm.DeleteIf(tc.pred); got != tc.wantCount {
+				t.Errorf("DeleteIf() = %d, want %d",
+					got, tc.wantCount)
+			}
+			if got := tc.pm.Len(); got != tc.wantLen {
+				t.Errorf("After DeleteIf(), Len() = %d, want %d",
+					got, tc.wantLen)
+			}
+			if nil == tc.pred {
+				return
+			}
+			for k, v := range tc.pm.All() {
+				if tc.pred(k, v) {
+					t.Errorf("After DeleteIf(), key %d still present", k)
+				}
+			}
+		})
+	}
+} // Test_TPartitionMap_DeleteIf()
+
+func Test_TPartitionMap_Drain(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want map[string]int
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: map[string]int{},
+		},
+		{
+			name: "Partition map with values",
+			pm:   New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			want: map[string]int{"a": 1, "b": 2, "c": 3},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.Drain()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Drain() = %v, want %v", got, tc.want)
+			}
+			if 0 != tc.pm.Len() {
+				t.Errorf("After Drain(), Len() = %d, want 0", tc.pm.Len())
+			}
+		})
+	}
+
+	// The map stays usable after draining.
+	pm := New[string, int]().Put("a", 1)
+	pm.Drain()
+	if v, ok := pm.Put("a", 2).Get("a"); !ok || (2 != v) {
+		t.Errorf("After Drain(), Get() = %d, %v, want 2, true", v, ok)
+	}
+} // Test_TPartitionMap_Drain()
+
+func Test_TPartitionMap_Drain_Concurrent(t *testing.T) {
+	const (
+		numWriters = 1 << 3
+		numKeys    = 1 << 12
+	)
+
+	pm := New[int, int]()
+	var wg sync.WaitGroup
+	wg.Add(numWriters)
+	for w := range numWriters {
+		go func() {
+			defer wg.Done()
+			for i := range numKeys {
+				pm.Put(w*numKeys+i, i)
+			}
+		}()
+	}
+
+	// Drain repeatedly while the writers are running.
+	drained := make(map[int]int)
+	for range 1 << 4 {
+		for k, v := range pm.Drain() {
+			if _, dup := drained[k]; dup {
+				t.Fatalf("Drain() returned key %d twice", k)
+			}
+			drained[k] = v
+		}
+	}
+	wg.Wait()
+
+	for k := range drained {
+		if _, ok := pm.Get(k); ok {
+			t.Errorf("Drain() returned key %d which is still present", k)
+		}
+	}
+	if got := len(drained) + pm.Len(); numWriters*numKeys != got {
+		t.Errorf("Drain() lost entries: got %d, want %d",
+			got, numWriters*numKeys)
+	}
+} // Test_TPartitionMap_Drain_Concurrent()
+
+func Test_TPartitionMap_Entries(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []TPair[string, int]
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: []TPair[string, int]{},
+		},
+		{
+			name: "Partition map with values",
+			pm: New[string, int]().
+				Put("key3", 300).
+				Put("key1", 100).
+				Put("key2", 200),
+			want: []TPair[string, int]{
+				{Key: "key1", Value: 100},
+				{Key: "key2", Value: 200},
+				{Key: "key3", Value: 300},
+			},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.Entries()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Entries() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_Entries()
+
+func Test_TPartitionMap_Entries_Concurrent(t *testing.T) {
+	const numKeys = 1 << 12
+
+	pm := New[int, int]()
+	for i := range numKeys {
+		pm.Put(i, i)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range numKeys {
+			if 0 == i%2 {
+				pm.Delete(i)
+			} else {
+				pm.Put(i, -i)
+			}
+		}
+	}()
+
+	for range 8 {
+		keys := make([]int, 0, numKeys)
+		for _, e := range pm.Entries() {
+			if (e.Value != e.Key) && (e.Value != -e.Key) {
+				t.Errorf("Entries() paired key %d with value %d",
+					e.Key, e.Value)
+			}
+			keys = append(keys, e.Key)
+		}
+		if !slices.IsSorted(keys) {
+			t.Errorf("Entries() not sorted by key")
+		}
+		if vals := pm.Values(); len(vals) > numKeys {
+			t.Errorf("Values() returned %d values, want <= %d",
+				len(vals), numKeys)
+		}
+	}
+	wg.Wait()
+} // Test_TPartitionMap_Entries_Concurrent()
+
+func Test_TPartitionMap_EstimatedBytes(t *testing.T) {
+	var nilPM *TPartitionMap[string, string]
+	if got := nilPM.EstimatedBytes(); 0 != got {
+		t.Errorf("EstimatedBytes() of nil map = %d, want 0", got)
+	}
+
+	pm := New[string, string]()
+	last := pm.EstimatedBytes()
+	if 0 >= last {
+		t.Errorf("EstimatedBytes() of empty map = %d, want > 0", last)
+	}
+
+	// The estimate must grow with every entry added.
+	for i := range 1000 {
+		pm.Put(fmt.Sprintf("key-%d", i), strings.Repeat("x", i%10))
+		got := pm.EstimatedBytes()
+		if got <= last {
+			t.Fatalf("EstimatedBytes() after %d entries = %d, want > %d",
+				i+1, got, last)
+		}
+		last = got
+	}
+
+	// Longer strings need more memory.
+	pm.Put("key-0", strings.Repeat("x", 1000))
+	if got := pm.EstimatedBytes(); got < last+1000 {
+		t.Errorf("EstimatedBytes() = %d, want >= %d", got, last+1000)
+	}
+
+	// Fixed size types:
+	ipm := New[int64, int64]().Put(1, 1)
+	one := ipm.EstimatedBytes()
+	ipm.Put(2, 2)
+	if got := ipm.EstimatedBytes() - one; 16 > got {
+		t.Errorf("EstimatedBytes() per entry = %d, want >= 16", got)
+	}
+} // Test_TPartitionMap_EstimatedBytes()
+
+func Test_TPartitionMap_Filter(t *testing.T) {
+	isEven := func(aKey int, aValue string) bool {
+		return 0 == aKey%2
+	}
+	newMap := func() *TPartitionMap[int, string] {
+		pm := New[int, string]()
+		for i := range 1000 {
+			pm.Put(i, fmt.Sprint(i))
+		}
+		return pm
+	}
+
+	tests := []struct {
+		name    string
+		pm      *TPartitionMap[int, string]
+		pred    func(int, string) bool
+		wantLen int
+		wantNil bool
+	}{
+		{
+			name:    "Filter subset",
+			pm:      newMap(),
+			pred:    isEven,
+			wantLen: 500,
+		},
+		{
+			name:    "Filter everything",
+			pm:      newMap(),
+			pred:    func(int, string) bool { return true },
+			wantLen: 1000,
+		},
+		{
+			name:    "Filter nothing",
+			pm:      newMap(),
+			pred:    func(int, string) bool { return false },
+			wantLen: 0,
+		},
+		{
+			name:    "Nil predicate",
+			pm:      newMap(),
+			pred:    nil,
+			wantLen: 0,
+		},
+		{
+			name:    "Nil partition map",
+			pm:      nil,
+			pred:    isEven,
+			wantNil: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srcLen := tc.pm.Len()
+			got := tc.pm.Filter(tc.pred)
+			if tc.wantNil {
+				if nil != got {
+					t.Errorf("Filter() = %v, want nil", got)
+				}
+				return
+			}
+
+			if got.Len() != tc.wantLen {
+				t.Errorf("Filter() Len() = %d, want %d",
+					got.Len(), tc.wantLen)
+			}
+			if srcLen != tc.pm.Len() {
+				t.Errorf("Filter() modified the source map")
+			}
+			for k, v := range got.All() {
+				if !tc.pred(k, v) {
+					t.Errorf("Filter() kept non-matching key %d", k)
+				}
+				if sv, ok := tc.pm.Get(k); !ok || (sv != v) {
+					t.Errorf("Filter() key %d = %q, source has %q",
+						k, v, sv)
+				}
+				// Keys must be found in their proper partitions.
+				if gv, ok := got.Get(k); !ok || (gv != v) {
+					t.Errorf("Filter().Get(%d) = %q, %v, want %q",
+						k, gv, ok, v)
+				}
+			}
+
+			got.Put(-1, "new")
+			if _, ok := tc.pm.Get(-1); ok {
+				t.Errorf("Filter() result shares storage with source")
+			}
+		})
+	}
+} // Test_TPartitionMap_Filter()
+
+func Test_TPartitionMap_ForEach(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+		},
+		{
+			name: "Partition map with values",
+			pm: New[string, int]().
+				Put("key1", 100).
+				Put("key2", 200).
+				Put("key3", 300),
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a map to store visited key-value pairs
+			visited := make(map[string]int)
+
+			// Call ForEach to collect all key-value pairs
+			got := tc.pm.ForEach(func(key string, value int) {
+				visited[key] = value
+			})
+
+			// Verify the result is the same instance as input
+			if got != tc.pm {
+				t.Errorf("ForEach() returned different instance, got %v, want %v",
+					got, tc.pm)
+			}
+
+			// For nil case, verify no function calls occurred
+			if tc.pm == nil {
+				if 0 < len(visited) {
+					t.Errorf("ForEach() should not call function for nil partition map")
+				}
+				return
+			}
+
+			// For non-nil case, verify all key-value pairs were visited
+			keys := tc.pm.Keys()
+			if len(visited) != len(keys) {
+				t.Errorf("Expected %d key-value pairs to be visited, got %d",
+					len(keys), len(visited))
+			}
+
+			// Verify each key-value pair was visited correctly
+			for _, key := range keys {
+				expectedValue, _ := tc.pm.Get(key)
+				if value, ok := visited[key]; !ok || value != expectedValue {
+					t.Errorf("Expected key %q with value %d, got %v, exists: %v",
+						key, expectedValue, value, ok)
+				}
+			}
+		})
+	}
+} // Test_TPartitionMap_ForEach()
+
+func Test_TPartitionMap_Iterate_ConcurrentPut(t *testing.T) {
+	// Iterations must neither block nor disturb writers creating
+	// new partitions (run with `-race`).
+	const numKeys = 1 << 12
+
+	pm := NewWithPartitions[int, int](1 << 10)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			cnt := 0
+			pm.ForEach(func(aKey, aValue int) {
+				cnt++
+			})
+			if keys := pm.Keys(); !slices.IsSorted(keys) {
+				t.Errorf("Keys() not sorted while concurrently modified")
+			}
+			_ = pm.Values()
+			_ = pm.Len()
+		}
+	}()
+
+	for i := range numKeys {
+		pm.Put(i, i)
+	}
+	close(done)
+	wg.Wait()
+
+	if got := pm.Len(); numKeys != got {
+		t.Errorf("Len() = %d, want %d", got, numKeys)
+	}
+} // Test_TPartitionMap_Iterate_ConcurrentPut()
+
+func Benchmark_TPartitionMap_Iterate_ConcurrentPut(b *testing.B) {
+	pm := NewWithPartitions[int, int](1 << 10)
+	for i := range 1 << 14 {
+		pm.Put(i*2, i)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// Keep writing to (possibly new) partitions.
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+				pm.Put(i*2+1, i)
+			}
+		}
+	}()
+
+	b.ResetTimer()
+	for range b.N {
+		pm.ForEach(func(aKey, aValue int) {})
+		_ = pm.Len()
+	}
+} // Benchmark_TPartitionMap_Iterate_ConcurrentPut()
+
+func Test_TPartitionMap_Generation(t *testing.T) {
+	pm := New[string, int]()
+	gen := pm.Generation()
+
+	steps := []struct {
+		name    string
+		op      func()
+		advance bool
+	}{
+		{"Put (insert)", func() { pm.Put("key", 1) }, true},
+		{"Put (update)", func() { pm.Put("key", 2) }, true},
+		{"Get", func() { pm.Get("key") }, false},
+		{"Keys", func() { pm.Keys() }, false},
+		{"Delete (absent)", func() { pm.Delete("missing") }, false},
+		{"Delete", func() { pm.Delete("key") }, true},
+		{"Delete (again)", func() { pm.Delete("key") }, false},
+		{"PutIfAbsent", func() { pm.PutIfAbsent("key", 3) }, true},
+		{"PutIfAbsent (present)", func() { pm.PutIfAbsent("key", 4) }, false},
+		{"Clear", func() { pm.Clear() }, true},
+	}
+
+	for _, step := range steps {
+		step.op()
+		got := pm.Generation()
+		if step.advance && (got <= gen) {
+			t.Errorf("%s: Generation() = %d, want > %d", step.name, got, gen)
+		} else if !step.advance && (got != gen) {
+			t.Errorf("%s: Generation() = %d, want %d", step.name, got, gen)
+		}
+		gen = got
+	}
+
+	var npm *TPartitionMap[string, int]
+	if got := npm.Generation(); 0 != got {
+		t.Errorf("Generation() on nil map = %d, want 0", got)
+	}
+} // Test_TPartitionMap_Generation()
+
+func Test_TPartitionMap_Get(t *testing.T) {
+	tests := []struct {
+		name      string
+		pm        *TPartitionMap[string, int]
+		key       string
+		wantValue int
+		wantFound bool
+	}{
+		{
+			name:      "Get existing key",
+			pm:        New[string, int]().Put("testKey", 42),
+			key:       "testKey",
+			wantValue: 42,
+			wantFound: true,
+		},
+		{
+			name:      "Get non-existent key",
+			pm:        New[string, int](),
+			key:       "nonExistentKey",
+			wantValue: 0,
+			wantFound: false,
+		},
+		{
+			name:      "Nil partition map",
+			pm:        nil,
+			key:       "anyKey",
+			wantValue: 0,
+			wantFound: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotValue, gotFound := tc.pm.Get(tc.key)
+			if gotValue != tc.wantValue {
+				t.Errorf("Get() value = %v, want %v",
+					gotValue, tc.wantValue)
+			}
+			if gotFound != tc.wantFound {
+				t.Errorf("Get() found = %v, want %v",
+					gotFound, tc.wantFound)
+			}
+		})
+	}
+} // Test_TPartitionMap_Get()
+
+func Test_TPartitionMap_GetAndDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		pm        *TPartitionMap[string, int]
+		key       string
+		wantValue int
+		wantFound bool
+	}{
+		{
+			name:      "Existing key",
+			pm:        New[string, int]().Put("testKey", 42),
+			key:       "testKey",
+			wantValue: 42,
+			wantFound: true,
+		},
+		{
+			name:      "Non-existent key",
+			pm:        New[string, int]().Put("testKey", 42),
+			key:       "nonExistentKey",
+			wantValue: 0,
+			wantFound: false,
+		},
+		{
+			name:      "Nil partition map",
+			pm:        nil,
+			key:       "anyKey",
+			wantValue: 0,
+			wantFound: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotValue, gotFound := tc.pm.GetAndDelete(tc.key)
+			if gotValue != tc.wantValue {
+				t.Errorf("GetAndDelete() value = %v, want %v",
+					gotValue, tc.wantValue)
+			}
+			if gotFound != tc.wantFound {
+				t.Errorf("GetAndDelete() found = %v, want %v",
+					gotFound, tc.wantFound)
+			}
+			if _, exists := tc.pm.Get(tc.key); exists {
+				t.Errorf("After GetAndDelete(), key %q still exists",
+					tc.key)
+			}
+		})
+	}
+} // Test_TPartitionMap_GetAndDelete()
+
+func Test_TPartitionMap_GetAndDelete_Concurrent(t *testing.T) {
+	const numGoroutines = 1 << 7
+
+	pm := New[string, int]().Put("job", 42)
+	var (
+		found int
+		mtx   sync.Mutex
+		wg    sync.WaitGroup
+	)
+	wg.Add(numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			if _, ok := pm.GetAndDelete("job"); ok {
+				mtx.Lock()
+				found++
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if 1 != found {
+		t.Errorf("GetAndDelete() succeeded %d times, want %d", found, 1)
+	}
+} // Test_TPartitionMap_GetAndDelete_Concurrent()
+
+func Test_TPartitionMap_GetMany(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		keys []string
+		want map[string]int
+	}{
+		{
+			name: "All keys present",
+			pm:   New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			keys: []string{"a", "c"},
+			want: map[string]int{"a": 1, "c": 3},
+		},
+		{
+			name: "Present and absent keys",
+			pm:   New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			keys: []string{"x", "b", "y", "c"},
+			want: map[string]int{"b": 2, "c": 3},
+		},
+		{
+			name: "Zero value present",
+			pm:   New[string, int]().Put("zero", 0),
+			keys: []string{"zero", "none"},
+			want: map[string]int{"zero": 0},
+		},
+		{
+			name: "Empty keys",
+			pm:   New[string, int]().Put("a", 1),
+			keys: []string{},
+			want: map[string]int{},
+		},
+		{
+			name: "Nil keys",
+			pm:   New[string, int]().Put("a", 1),
+			keys: nil,
+			want: map[string]int{},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			keys: []string{"a"},
+			want: map[string]int{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pm.GetMany(tc.keys); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("GetMany() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_GetMany()
+
+func Test_TPartitionMap_GetManyPartitioned(t *testing.T) {
+	newMap := func() *TPartitionMap[string, int] {
+		return New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3)
+	}
+
+	tests := []struct {
+		name        string
+		pm          *TPartitionMap[string, int]
+		keys        []string
+		wantFound   map[string]int
+		wantMissing []string
+	}{
+		{
+			name:      "All hits",
+			pm:        newMap(),
+			keys:      []string{"c", "a"},
+			wantFound: map[string]int{"a": 1, "c": 3},
+		},
+		{
+			name:        "All misses",
+			pm:          newMap(),
+			keys:        []string{"z", "x", "y"},
+			wantFound:   map[string]int{},
+			wantMissing: []string{"x", "y", "z"},
+		},
+		{
+			name:        "Mixed with duplicates",
+			pm:          newMap(),
+			keys:        []string{"y", "b", "x", "c", "y", "b"},
+			wantFound:   map[string]int{"b": 2, "c": 3},
+			wantMissing: []string{"x", "y"},
+		},
+		{
+			name:      "Nil keys",
+			pm:        newMap(),
+			keys:      nil,
+			wantFound: map[string]int{},
+		},
+		{
+			name:        "Nil partition map",
+			pm:          nil,
+			keys:        []string{"b", "a"},
+			wantFound:   map[string]int{},
+			wantMissing: []string{"a", "b"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			found, missing := tc.pm.GetManyPartitioned(tc.keys)
+			if !reflect.DeepEqual(found, tc.wantFound) {
+				t.Errorf("GetManyPartitioned() found = %v, want %v",
+					found, tc.wantFound)
+			}
+			if !slices.Equal(missing, tc.wantMissing) {
+				t.Errorf("GetManyPartitioned() missing = %v, want %v",
+					missing, tc.wantMissing)
+			}
+		})
+	}
+
+	// Missing keys are reported normalised.
+	npm := NewNormalized[string, int](strings.ToLower).Put("a", 1)
+	if _, missing := npm.GetManyPartitioned([]string{"A", "B", "b"}); !slices.Equal(missing, []string{"b"}) {
+		t.Errorf("GetManyPartitioned() missing = %v, want [b]", missing)
+	}
+} // Test_TPartitionMap_GetManyPartitioned()
+
+func Test_TPartitionMap_GetOrCompute(t *testing.T) {
+	pm := New[string, int]().Put("existing", 1)
+	calls := 0
+	compute := func(aKey string) int {
+		calls++
+		return len(aKey)
+	}
+
+	if got := pm.GetOrCompute("existing", compute); 1 != got {
+		t.Errorf("GetOrCompute(existing) = %d, want 1", got)
+	}
+	if got := pm.GetOrCompute("missing", compute); 7 != got {
+		t.Errorf("GetOrCompute(missing) = %d, want 7", got)
+	}
+	if got := pm.GetOrCompute("missing", compute); 7 != got {
+		t.Errorf("GetOrCompute(missing) again = %d, want 7", got)
+	}
+	if 1 != calls {
+		t.Errorf("compute called %d times, want 1", calls)
+	}
+	if v, ok := pm.Get("missing"); !ok || 7 != v {
+		t.Errorf("Get(missing) = (%d, %v), want (7, true)", v, ok)
+	}
+
+	// A panicking computation stores nothing.
+	func() {
+		defer func() { _ = recover() }()
+		pm.GetOrCompute("panic", func(string) int { panic("oops") })
+	}()
+	if _, ok := pm.Get("panic"); ok {
+		t.Error("Get(panic) = true, want false")
+	}
+	if got := pm.GetOrCompute("panic", compute); 5 != got {
+		t.Errorf("GetOrCompute(panic) = %d, want 5", got)
+	}
+
+	var npm *TPartitionMap[string, int]
+	if got := npm.GetOrCompute("key", compute); 0 != got {
+		t.Errorf("GetOrCompute() on nil map = %d, want 0", got)
+	}
+	if got := pm.GetOrCompute("nil", nil); 0 != got {
+		t.Errorf("GetOrCompute() with nil func = %d, want 0", got)
+	}
+} // Test_TPartitionMap_GetOrCompute()
+
+func Test_TPartitionMap_GetOrCompute_Concurrent(t *testing.T) {
+	const numGoroutines = 1 << 7
+
+	pm := New[string, int]()
+	var (
+		calls atomic.Int32
+		start = make(chan struct{})
+		wg    sync.WaitGroup
+	)
+	compute := func(aKey string) int {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond) // widen the race window
+		return 42
+	}
+
+	wg.Add(numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			<-start
+			if got := pm.GetOrCompute("contended", compute); 42 != got {
+				t.Errorf("GetOrCompute() = %d, want 42", got)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if got := calls.Load(); 1 != got {
+		t.Errorf("compute called %d times, want 1", got)
+	}
+} // Test_TPartitionMap_GetOrCompute_Concurrent()
+
+func Benchmark_TPartitionMap_GetMany(b *testing.B) {
+	const numEntries = 100_000
+
+	pm := New[int, int]()
+	keys := make([]int, 0, numEntries/10)
+	for i := range numEntries {
+		pm.Put(i, i)
+		if 0 == i%10 {
+			keys = append(keys, i, -i-1) // present and absent
+		}
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		for range b.N {
+			result := make(map[int]int, len(keys))
+			for _, k := range keys {
+				if v, ok := pm.Get(k); ok {
+					result[k] = v
+				}
+			}
+		}
+	})
+	b.Run("GetMany", func(b *testing.B) {
+		for range b.N {
+			_ = pm.GetMany(keys)
+		}
+	})
+} // Benchmark_TPartitionMap_GetMany()
+
+func Test_TPartitionMap_GetOrDefault(t *testing.T) {
+	tests := []struct {
+		name     string
+		pm       *TPartitionMap[string, int]
+		key      string
+		defValue int
+		want     int
+	}{
+		{
+			name:     "Get existing key",
+			pm:       New[string, int]().Put("testKey", 42),
+			key:      "testKey",
+			defValue: -1,
+			want:     42,
+		},
+		{
+			name:     "Get non-existent key",
+			pm:       New[string, int](),
+			key:      "nonExistentKey",
+			defValue: 99,
+			want:     99,
+		},
+		{
+			name:     "Nil partition map",
+			pm:       nil,
+			key:      "anyKey",
+			defValue: 123,
+			want:     123,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.GetOrDefault(tc.key, tc.defValue)
+			if got != tc.want {
+				t.Errorf("GetOrDefault() = '%v', want '%v'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_GetOrDefault()
+
+func Test_TPartitionMap_GetWithPartition(t *testing.T) {
+	list := []*TPartitionMap[string, int]{
+		New[string, int](),
+		NewWithPartitions[string, int](7),
+		NewWithSeed[string, int](42),
+	}
+
+	for _, pm := range list {
+		for i := range 100 {
+			pm.Put(fmt.Sprint("key", i), i)
+		}
+		for _, key := range []string{"key0", "key42", "key99", "nokey"} {
+			val, idx, found := pm.GetWithPartition(key)
+			if want := pm.index(key); want != idx {
+				t.Errorf("GetWithPartition(%q) index = %d, want %d", key, idx, want)
+			}
+			wantVal, wantFound := pm.Get(key)
+			if (wantVal != val) || (wantFound != found) {
+				t.Errorf("GetWithPartition(%q) = (%d, %v), want (%d, %v)",
+					key, val, found, wantVal, wantFound)
+			}
+			if found && !pm.slots()[idx].Load().has(key) {
+				t.Errorf("GetWithPartition(%q): key not in partition %d", key, idx)
+			}
+		}
+	}
+
+	var npm *TPartitionMap[string, int]
+	if val, idx, found := npm.GetWithPartition("key0"); (0 != val) || (-1 != idx) || found {
+		t.Errorf("GetWithPartition() on nil map = (%d, %d, %v), want (0, -1, false)",
+			val, idx, found)
+	}
+} // Test_TPartitionMap_GetWithPartition()
+
+func Test_TPartitionMap_Has(t *testing.T) {
+	pm := New[string, int]().Put("key", 0)
+	if !pm.Has("key") {
+		t.Error("Has(key) = false, want true")
+	}
+	if pm.Has("missing") {
+		t.Error("Has(missing) = true, want false")
+	}
+
+	ttl, clock := newFakeTTL(time.Minute)
+	ttl.Put("key", 1)
+	clock.Advance(time.Minute)
+	if ttl.Has("key") {
+		t.Error("Has() of expired key = true, want false")
+	}
+
+	var npm *TPartitionMap[string, int]
+	if npm.Has("key") {
+		t.Error("Has() on nil map = true, want false")
+	}
+} // Test_TPartitionMap_Has()
+
+func Test_TPartitionMap_Intersection(t *testing.T) {
+	tests := []struct {
+		name  string
+		pm    *TPartitionMap[string, int]
+		other *TPartitionMap[string, int]
+		want  map[string]int
+	}{
+		{
+			name:  "Overlapping",
+			pm:    New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+			other: New[string, int]().Put("b", 20).Put("c", 30).Put("d", 40),
+			want:  map[string]int{"b": 2, "c": 3},
+		},
+		{
+			name:  "Disjoint",
+			pm:    New[string, int]().Put("a", 1).Put("b", 2),
+			other: New[string, int]().Put("c", 3).Put("d", 4),
+			want:  map[string]int{},
+		},
+		{
+			name:  "Identical",
+			pm:    New[string, int]().Put("a", 1).Put("b", 2),
+			other: NewWithPartitions[string, int](3).Put("b", 0).Put("a", 0),
+			want:  map[string]int{"a": 1, "b": 2},
+		},
+		{
+			name:  "Nil other",
+			pm:    New[string, int]().Put("a", 1),
+			other: nil,
+			want:  map[string]int{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.Intersection(tc.other)
+			if nil == got {
+				t.Fatal("Intersection() returned nil")
+			}
+			if gm := got.ToMap(); !maps.Equal(gm, tc.want) {
+				t.Errorf("Intersection() = %v, want %v", gm, tc.want)
+			}
+			if len(got.slots()) != len(tc.pm.slots()) {
+				t.Errorf("Intersection() has %d partitions, want %d",
+					len(got.slots()), len(tc.pm.slots()))
+			}
+			recount(t, got)
+		})
+	}
+
+	var npm *TPartitionMap[string, int]
+	if nil != npm.Intersection(New[string, int]()) {
+		t.Error("Intersection() on nil map should return nil")
+	}
+} // Test_TPartitionMap_Intersection()
+func Test_TPartitionMap_Keys(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []string
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: []string{},
+		},
+		{
+			name: "Partition map with keys",
+			pm: New[string, int]().
+				Put("key1", 100).
+				Put("key2", 200).
+				Put("key3", 300),
+			want: []string{"key1", "key2", "key3"},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.Keys()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Keys() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_Keys()
+
+func Test_TPartitionMap_KeysDesc(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []string
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: []string{},
+		},
+		{
+			name: "Partition map with keys",
+			pm: New[string, int]().
+				Put("key2", 200).
+				Put("key3", 300).
+				Put("key1", 100),
+			want: []string{"key3", "key2", "key1"},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.KeysDesc()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("KeysDesc() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+
+	// Integer keys spread across all partitions.
+	ipm := New[int, int]()
+	for _, i := range rand.Perm(1000) {
+		ipm.Put(i-500, i)
+	}
+	got := ipm.KeysDesc()
+	if 1000 != len(got) {
+		t.Fatalf("KeysDesc() returned %d keys, want 1000", len(got))
+	}
+	for idx, key := range got {
+		if want := 499 - idx; want != key {
+			t.Fatalf("KeysDesc()[%d] = %d, want %d", idx, key, want)
+		}
+	}
+} // Test_TPartitionMap_KeysDesc()
+
+func Test_TPartitionMap_KeysUnsorted(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []string
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: []string{},
+		},
+		{
+			name: "Partition map with keys",
+			pm: New[string, int]().
+				Put("key3", 300).
+				Put("key1", 100).
+				Put("key2", 200),
+			want: []string{"key1", "key2", "key3"},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.KeysUnsorted()
+			if nil != got {
+				slices.Sort(got)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("KeysUnsorted() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_KeysUnsorted()
+
+func Benchmark_TPartitionMap_KeysUnsorted(b *testing.B) {
+	pm := New[string, int]()
+	for i := range 1 << 18 {
+		pm.Put(fmt.Sprintf("key-%d", i), i)
+	}
+
+	b.Run("Keys", func(b *testing.B) {
+		for range b.N {
+			_ = pm.Keys()
+		}
+	})
+	b.Run("KeysUnsorted", func(b *testing.B) {
+		for range b.N {
+			_ = pm.KeysUnsorted()
+		}
+	})
+} // Benchmark_TPartitionMap_KeysUnsorted()
+
+func Test_TPartitionMap_Len(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want int
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: 0,
+		},
+		{
+			name: "Partition map with one key",
+			pm:   New[string, int]().Put("key1", 100),
+			want: 1,
+		},
+		{
+			name: "Partition map with multiple keys",
+			pm: New[string, int]().
+				Put("key1", 100).
+				Put("key2", 200).
+				Put("key3", 300),
+			want: 3,
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.Len()
+			if got != tc.want {
+				t.Errorf("Len() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_Len()
+
+// `recount()` checks the live lengths of all partitions of the
+// given map against their actual number of entries.
+func recount[K cmp.Ordered, V any](t *testing.T, aPM *TPartitionMap[K, V]) {
+	t.Helper()
+
+	total := 0
+	for idx, p := range aPM.partitions() {
+		if nil == p {
+			continue
+		}
+		p.RLock()
+		want := len(p.kv)
+		p.RUnlock()
+		if got := p.len(); want != got {
+			t.Errorf("partition %d: len() = %d, want %d", idx, got, want)
+		}
+		total += want
+	}
+	if got := aPM.Len(); total != got {
+		t.Errorf("Len() = %d, want %d", got, total)
+	}
+	if got := aPM.PartitionStats().Keys; total != got {
+		t.Errorf("PartitionStats().Keys = %d, want %d", got, total)
+	}
+} // recount()
+
+func Test_TPartitionMap_Len_Live(t *testing.T) {
+	const (
+		numOps  = 1 << 14
+		keyMax  = 1 << 9
+		maxSize = 1 << 8
+	)
+	rnd := rand.New(rand.NewPCG(42, 4711))
+
+	for _, pm := range []*TPartitionMap[int, int]{
+		New[int, int](),
+		NewLRU[int, int](maxSize),
+		NewWithTTL[int, int](time.Hour),
+	} {
+		for i := range numOps {
+			key := rnd.IntN(keyMax)
+			switch rnd.IntN(12) {
+			case 0:
+				pm.Delete(key)
+			case 1:
+				pm.GetAndDelete(key)
+			case 2:
+				pm.DeleteAll([]int{key, key + 1, key + 2})
+			case 3:
+				pm.PutAll(map[int]int{key: i, key + 1: i})
+			case 4:
+				pm.Compute(key, func(aOld int, aFound bool) (int, bool) {
+					return aOld + 1, aFound && (0 == aOld%2)
+				})
+			case 5:
+				pm.LoadOrStore(key, i)
+			case 6:
+				pm.Pop()
+			case 7:
+				pm.DeleteIf(func(aKey, aValue int) bool {
+					return aKey == key
+				})
+			case 8:
+				if 0 == rnd.IntN(64) {
+					pm.Clear()
+				}
+			case 9:
+				pm.GetOrCompute(key, func(int) int { return i })
+			default:
+				pm.Put(key, i)
+			}
+		}
+		recount(t, pm)
+		recount(t, pm.Clone())
+		recount(t, pm.Filter(func(aKey, aValue int) bool {
+			return 0 == aKey%2
+		}))
+		pm.Drain()
+		recount(t, pm)
+	}
+	recount(t, FromMap(map[int]int{1: 1, 2: 2, 3: 3}))
+} // Test_TPartitionMap_Len_Live()
+
+func Test_TPartitionMap_Len_Concurrent(t *testing.T) {
+	const (
+		numGoroutines = 1 << 5
+		numOps        = 1 << 12
+		keyMax        = 1 << 10
+	)
+
+	pm := New[int, int]()
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+	for g := range numGoroutines {
+		go func() {
+			defer wg.Done()
+			rnd := rand.New(rand.NewPCG(uint64(g), 42))
+			for i := range numOps {
+				key := rnd.IntN(keyMax)
+				switch rnd.IntN(6) {
+				case 0:
+					pm.Delete(key)
+				case 1:
+					pm.PutIfAbsent(key, i)
+				case 2:
+					pm.GetAndDelete(key)
+				case 3:
+					pm.Compute(key, func(aOld int, aFound bool) (int, bool) {
+						return aOld + 1, aFound
+					})
+				default:
+					pm.Put(key, i) // inserts as well as updates
+				}
+				if 0 > pm.Len() {
+					t.Errorf("Len() = %d, want >= 0", pm.Len())
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	recount(t, pm)
+	if got, want := pm.Len(), len(pm.ToMap()); want != got {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+} // Test_TPartitionMap_Len_Concurrent()
+
+func Test_TPartitionMap_Len_LockFree(t *testing.T) {
+	const numKeys = 1000
+
+	pm := New[int, int]()
+	for i := range numKeys {
+		pm.Put(i, i)
+	}
+
+	// `Len()` must neither wait for the map's lock nor for any
+	// partition's lock.
+	pm.Lock()
+	list := pm.partitions()
+	for _, p := range list {
+		p.Lock()
+	}
+	got := make(chan int, 1)
+	go func() {
+		got <- pm.Len()
+	}()
+	select {
+	case n := <-got:
+		if numKeys != n {
+			t.Errorf("Len() = %d, want %d", n, numKeys)
+		}
+	case <-time.After(time.Second):
+		t.Error("Len() blocked by the locks")
+	}
+	for _, p := range list {
+		p.Unlock()
+	}
+	pm.Unlock()
+} // Test_TPartitionMap_Len_LockFree()
+
+func Test_TPartitionMap_LoadOrStore(t *testing.T) {
+	tests := []struct {
+		name       string
+		pm         *TPartitionMap[string, int]
+		key        string
+		value      int
+		wantValue  int
+		wantLoaded bool
+	}{
+		{
+			name:       "Store new key",
+			pm:         New[string, int](),
+			key:        "newKey",
+			value:      42,
+			wantValue:  42,
+			wantLoaded: false,
+		},
+		{
+			name:       "Load existing key",
+			pm:         New[string, int]().Put("existingKey", 100),
+			key:        "existingKey",
+			value:      200,
+			wantValue:  100,
+			wantLoaded: true,
+		},
+		{
+			name:       "Nil partition map",
+			pm:         nil,
+			key:        "anyKey",
+			value:      42,
+			wantValue:  0,
+			wantLoaded: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotValue, gotLoaded := tc.pm.LoadOrStore(tc.key, tc.value)
+			if gotValue != tc.wantValue {
+				t.Errorf("LoadOrStore() value = %v, want %v",
+					gotValue, tc.wantValue)
+			}
+			if gotLoaded != tc.wantLoaded {
+				t.Errorf("LoadOrStore() loaded = %v, want %v",
+					gotLoaded, tc.wantLoaded)
+			}
+
+			if nil != tc.pm {
+				if got, _ := tc.pm.Get(tc.key); got != tc.wantValue {
+					t.Errorf("After LoadOrStore(), Get() = %v, want %v",
+						got, tc.wantValue)
+				}
+			}
+		})
+	}
+} // Test_TPartitionMap_LoadOrStore()
+
+func Test_TPartitionMap_LoadOrStore_Concurrent(t *testing.T) {
+	const numGoroutines = 1 << 6
+
+	pm := New[string, int]()
+	var (
+		stored int
+		mtx    sync.Mutex
+		wg     sync.WaitGroup
+	)
+	wg.Add(numGoroutines)
+	for i := range numGoroutines {
+		go func(aID int) {
+			defer wg.Done()
+			if _, loaded := pm.LoadOrStore("key", aID); !loaded {
+				mtx.Lock()
+				stored++
+				mtx.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if 1 != stored {
+		t.Errorf("LoadOrStore() stored %d times, want %d", stored, 1)
+	}
+} // Test_TPartitionMap_LoadOrStore_Concurrent()
+
+func Test_TPartitionMap_Merge(t *testing.T) {
+	sum := func(aExisting, aIncoming int) int {
+		return aExisting + aIncoming
+	}
+	keepExisting := func(aExisting, aIncoming int) int {
+		return aExisting
+	}
+
+	tests := []struct {
+		name       string
+		pm         *TPartitionMap[string, int]
+		other      *TPartitionMap[string, int]
+		onConflict func(int, int) int
+		want       map[string]int
+	}{
+		{
+			name:       "Disjoint keys",
+			pm:         New[string, int]().Put("a", 1).Put("b", 2),
+			other:      New[string, int]().Put("c", 3).Put("d", 4),
+			onConflict: sum,
+			want:       map[string]int{"a": 1, "b": 2, "c": 3, "d": 4},
+		},
+		{
+			name:       "Overlapping keys, summing resolver",
+			pm:         New[string, int]().Put("a", 1).Put("b", 2),
+			other:      New[string, int]().Put("b", 20).Put("c", 30),
+			onConflict: sum,
+			want:       map[string]int{"a": 1, "b": 22, "c": 30},
+		},
+		{
+			name:       "Overlapping keys, keep existing",
+			pm:         New[string, int]().Put("a", 1).Put("b", 2),
+			other:      New[string, int]().Put("b", 20).Put("c", 30),
+			onConflict: keepExisting,
+			want:       map[string]int{"a": 1, "b": 2, "c": 30},
+		},
+		{
+			name:       "Overlapping keys, nil resolver",
+			pm:         New[string, int]().Put("a", 1).Put("b", 2),
+			other:      New[string, int]().Put("b", 20).Put("c", 30),
+			onConflict: nil,
+			want:       map[string]int{"a": 1, "b": 20, "c": 30},
+		},
+		{
+			name:       "Different partition counts",
+			pm:         NewWithPartitions[string, int](3).Put("a", 1),
+			other:      NewWithPartitions[string, int](7).Put("b", 2).Put("c", 3),
+			onConflict: nil,
+			want:       map[string]int{"a": 1, "b": 2, "c": 3},
+		},
+		{
+			name:       "Nil other map",
+			pm:         New[string, int]().Put("a", 1),
+			other:      nil,
+			onConflict: sum,
+			want:       map[string]int{"a": 1},
+		},
+		{
+			name:       "Nil partition map",
+			pm:         nil,
+			other:      New[string, int]().Put("a", 1),
+			onConflict: sum,
+			want:       nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var otherBefore map[string]int
+			if nil != tc.other {
+				otherBefore = tc.other.ToMap()
+			}
+
+			got := tc.pm.Merge(tc.other, tc.onConflict)
+			if got != tc.pm {
+				t.Errorf("Merge() returned different instance")
+			}
+			if !reflect.DeepEqual(tc.pm.ToMap(), tc.want) {
+				t.Errorf("Merge() = %v, want %v",
+					tc.pm.ToMap(), tc.want)
+			}
+			if nil != tc.other {
+				if !reflect.DeepEqual(tc.other.ToMap(), otherBefore) {
+					t.Errorf("Merge() modified the other map")
+				}
+			}
+		})
+	}
+} // Test_TPartitionMap_Merge()
+
+func Test_TPartitionMap_Merge_Self(t *testing.T) {
+	pm := New[string, int]().Put("a", 1).Put("b", 2)
+
+	pm.Merge(pm, func(aExisting, aIncoming int) int {
+		return aExisting + aIncoming
+	})
+
+	want := map[string]int{"a": 2, "b": 4}
+	if got := pm.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge(self) = %v, want %v", got, want)
+	}
+} // Test_TPartitionMap_Merge_Self()
+
+func Test_TPartitionMap_MinKey_MaxKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		pm      *TPartitionMap[string, int]
+		wantMin string
+		wantMax string
+		wantOk  bool
+	}{
+		{
+			name: "Several keys",
+			pm: New[string, int]().
+				Put("mango", 1).
+				Put("apple", 2).
+				Put("zucchini", 3).
+				Put("kiwi", 4),
+			wantMin: "apple",
+			wantMax: "zucchini",
+			wantOk:  true,
+		},
+		{
+			name:    "Single key",
+			pm:      New[string, int]().Put("only", 1),
+			wantMin: "only",
+			wantMax: "only",
+			wantOk:  true,
+		},
+		{
+			name:    "Empty partition map",
+			pm:      New[string, int](),
+			wantMin: "",
+			wantMax: "",
+			wantOk:  false,
+		},
+		{
+			name:    "Nil partition map",
+			pm:      nil,
+			wantMin: "",
+			wantMax: "",
+			wantOk:  false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotMin, okMin := tc.pm.MinKey()
+			if (gotMin != tc.wantMin) || (okMin != tc.wantOk) {
+				t.Errorf("MinKey() = %q, %v, want %q, %v",
+					gotMin, okMin, tc.wantMin, tc.wantOk)
+			}
+			gotMax, okMax := tc.pm.MaxKey()
+			if (gotMax != tc.wantMax) || (okMax != tc.wantOk) {
+				t.Errorf("MaxKey() = %q, %v, want %q, %v",
+					gotMax, okMax, tc.wantMax, tc.wantOk)
+			}
+		})
+	}
+
+	// Integer keys spread over all partitions:
+	pm := New[int, int]()
+	for i := -5000; i <= 5000; i += 3 {
+		pm.Put(i, i)
+	}
+	keys := pm.Keys()
+	if got, _ := pm.MinKey(); got != keys[0] {
+		t.Errorf("MinKey() = %d, want %d", got, keys[0])
+	}
+	if got, _ := pm.MaxKey(); got != keys[len(keys)-1] {
+		t.Errorf("MaxKey() = %d, want %d", got, keys[len(keys)-1])
+	}
+} // Test_TPartitionMap_MinKey_MaxKey()
+
+func Test_TPartitionMap_Pop(t *testing.T) {
+	src := map[string]int{"a": 1, "b": 2, "c": 3}
+	pm := FromMap(src)
+
+	popped := make(map[string]int)
+	for range len(src) {
+		k, v, ok := pm.Pop()
+		if !ok {
+			t.Fatalf("Pop() = %q, %d, false, want true", k, v)
+		}
+		popped[k] = v
+	}
+	if !reflect.DeepEqual(popped, src) {
+		t.Errorf("Pop() returned %v, want %v", popped, src)
+	}
+
+	if k, v, ok := pm.Pop(); ok || ("" != k) || (0 != v) {
+		t.Errorf("Pop() on empty map = %q, %d, %v, want \"\", 0, false", k, v, ok)
+	}
+
+	var nilPM *TPartitionMap[string, int]
+	if _, _, ok := nilPM.Pop(); ok {
+		t.Errorf("Pop() on nil map = true, want false")
+	}
+} // Test_TPartitionMap_Pop()
+
+func Test_TPartitionMap_Pop_Concurrent(t *testing.T) {
+	const (
+		numGoroutines = 1 << 4
+		numEntries    = 1 << 14
+	)
+
+	pm := New[int, int]()
+	for i := range numEntries {
+		pm.Put(i, i)
+	}
+
+	var (
+		mtx    sync.Mutex
+		popped = make(map[int]int, numEntries)
+		wg     sync.WaitGroup
+	)
+	wg.Add(numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			for k, v, ok := pm.Pop(); ok; k, v, ok = pm.Pop() {
+				mtx.Lock()
+				if _, dup := popped[k]; dup {
+					t.Errorf("Pop() returned key %d twice", k)
+				}
+				popped[k] = v
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(popped); numEntries != got {
+		t.Errorf("Pop() returned %d entries, want %d", got, numEntries)
+	}
+	if got := pm.Len(); 0 != got {
+		t.Errorf("After Pop(), Len() = %d, want 0", got)
+	}
+} // Test_TPartitionMap_Pop_Concurrent()
+
+func Test_TPartitionMap_Put(t *testing.T) {
+	tests := []struct {
+		name      string
+		pm        *TPartitionMap[string, int]
+		key       string
+		value     int
+		wantValue int
+		wantFound bool
+	}{
+		{
+			name:      "Add new key-value pair",
+			pm:        New[string, int](),
+			key:       "newKey",
+			value:     42,
+			wantValue: 42,
+			wantFound: true,
+		},
+		{
+			name:      "Update existing key",
+			pm:        New[string, int]().Put("existingKey", 100),
+			key:       "existingKey",
+			value:     200,
+			wantValue: 200,
+			wantFound: true,
+		},
+		{
+			name:      "Nil partition map",
+			pm:        nil,
+			key:       "anyKey",
+			value:     42,
+			wantValue: 0,
+			wantFound: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Store original map for nil check
+			originalPM := tc.pm
+
+			// Call Put method
+			result := tc.pm.Put(tc.key, tc.value)
+
+			// Check if result is the same instance as input
+			if result != originalPM {
+				t.Errorf("Put() returned different instance, got %v, want %v",
+					result, originalPM)
+			}
+
+			// Check if value was stored correctly (except for nil case)
+			if nil != tc.pm {
+				gotValue, gotFound := tc.pm.Get(tc.key)
+				if gotValue != tc.wantValue {
+					t.Errorf("After Put(), value = %v, want %v",
+						gotValue, tc.wantValue)
+				}
+				if gotFound != tc.wantFound {
+					t.Errorf("After Put(), found = %v, want %v",
+						gotFound, tc.wantFound)
+				}
+			}
+		})
+	}
+} // Test_TPartitionMap_Put()
+
+func Benchmark_TPartitionMap_Put_Contention(b *testing.B) {
+	// Each iteration fills a fresh map from several goroutines so
+	// that all partitions get created concurrently.
+	const numKeys = 1 << 12
+
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			for range b.N {
+				pm := New[int, int]()
+				var wg sync.WaitGroup
+				wg.Add(workers)
+				for w := range workers {
+					go func(aStart int) {
+						defer wg.Done()
+						for i := aStart; i < numKeys; i += workers {
+							pm.Put(i, i)
+						}
+					}(w)
+				}
+				wg.Wait()
+			}
+		})
+	}
+} // Benchmark_TPartitionMap_Put_Contention()
+
+func Test_TPartitionMap_PutAll(t *testing.T) {
+	tests := []struct {
+		name   string
+		pm     *TPartitionMap[string, int]
+		source map[string]int
+		want   map[string]int
+	}{
+		{
+			name:   "Empty source",
+			pm:     New[string, int]().Put("a", 1),
+			source: map[string]int{},
+			want:   map[string]int{"a": 1},
+		},
+		{
+			name:   "Nil source",
+			pm:     New[string, int]().Put("a", 1),
+			source: nil,
+			want:   map[string]int{"a": 1},
+		},
+		{
+			name:   "New and existing keys",
+			pm:     New[string, int]().Put("a", 1).Put("b", 2),
+			source: map[string]int{"b": 20, "c": 30, "d": 40},
+			want:   map[string]int{"a": 1, "b": 20, "c": 30, "d": 40},
+		},
+		{
+			name:   "Nil partition map",
+			pm:     nil,
+			source: map[string]int{"a": 1},
+			want:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.PutAll(tc.source)
+			if got != tc.pm {
+				t.Errorf("PutAll() = %p, want %p", got, tc.pm)
+			}
+			if !reflect.DeepEqual(tc.pm.ToMap(), tc.want) {
+				t.Errorf("After PutAll(), ToMap() = %v, want %v",
+					tc.pm.ToMap(), tc.want)
+			}
+		})
+	}
+
+	// Many keys spread across all partitions:
+	source := make(map[int]int, 1<<12)
+	for i := range 1 << 12 {
+		source[i-(1<<11)] = i
+	}
+	pm := NewWithPartitions[int, int](13).PutAll(source)
+	if !reflect.DeepEqual(pm.ToMap(), source) {
+		t.Errorf("PutAll() with many keys: ToMap() differs from source")
+	}
+} // Test_TPartitionMap_PutAll()
+
+func Benchmark_TPartitionMap_PutAll(b *testing.B) {
+	const numEntries = 100_000
+
+	source := make(map[int]int, numEntries)
+	for i := range numEntries {
+		source[i] = i
+	}
+
+	b.Run("Put", func(b *testing.B) {
+		for range b.N {
+			pm := New[int, int]()
+			for k, v := range source {
+				pm.Put(k, v)
+			}
+		}
+	})
+	b.Run("PutAll", func(b *testing.B) {
+		for range b.N {
+			New[int, int]().PutAll(source)
+		}
+	})
+} // Benchmark_TPartitionMap_PutAll()
+
+func Test_TPartitionMap_PutIfAbsent(t *testing.T) {
+	tests := []struct {
+		name      string
+		pm        *TPartitionMap[string, int]
+		key       string
+		value     int
+		want      bool
+		wantValue int
+	}{
+		{
+			name:      "Insert new key",
+			pm:        New[string, int](),
+			key:       "newKey",
+			value:     42,
+			want:      true,
+			wantValue: 42,
+		},
+		{
+			name:      "Keep existing key",
+			pm:        New[string, int]().Put("existingKey", 100),
+			key:       "existingKey",
+			value:     200,
+			want:      false,
+			wantValue: 100,
+		},
+		{
+			name:      "Nil partition map",
+			pm:        nil,
+			key:       "anyKey",
+			value:     42,
+			want:      false,
+			wantValue: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pm.PutIfAbsent(tc.key, tc.value); got != tc.want {
+				t.Errorf("PutIfAbsent() = %v, want %v",
+					got, tc.want)
+			}
+			if got, _ := tc.pm.Get(tc.key); got != tc.wantValue {
+				t.Errorf("After PutIfAbsent(), Get() = %v, want %v",
+					got, tc.wantValue)
+			}
+		})
+	}
+} // Test_TPartitionMap_PutIfAbsent()
+
+func Test_TPartitionMap_PutIfAbsent_Concurrent(t *testing.T) {
+	const (
+		numGoroutines = 1 << 7
+		numKeys       = 1 << 4
+	)
+
+	pm := New[int, int]()
+	var (
+		inserted [numKeys]int
+		mtx      sync.Mutex
+		wg       sync.WaitGroup
+	)
+	wg.Add(numGoroutines)
+	for i := range numGoroutines {
+		go func(aID int) {
+			defer wg.Done()
+			for key := range numKeys {
+				if pm.PutIfAbsent(key, aID) {
+					mtx.Lock()
+					inserted[key]++
+					mtx.Unlock()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for key, count := range inserted {
+		if 1 != count {
+			t.Errorf("PutIfAbsent(%d) inserted %d times, want %d",
+				key, count, 1)
+		}
+	}
+	if numKeys != pm.Len() {
+		t.Errorf("Len() = %d, want %d", pm.Len(), numKeys)
+	}
+} // Test_TPartitionMap_PutIfAbsent_Concurrent()
+
+func Test_TPartitionMap_PutIfPresent(t *testing.T) {
+	pm := New[string, int]().Put("a", 1)
+	var puts int
+	pm.OnPut(func(string, int) { puts++ })
+
+	if !pm.PutIfPresent("a", 11) {
+		t.Error("PutIfPresent(a) = false, want true")
+	}
+	if v, _ := pm.Get("a"); 11 != v {
+		t.Errorf("Get(a) after PutIfPresent() = %d, want 11", v)
+	}
+
+	if pm.PutIfPresent("b", 2) {
+		t.Error("PutIfPresent(b) = true, want false")
+	}
+	if pm.Has("b") {
+		t.Error("PutIfPresent() created an absent key")
+	}
+	if 1 != puts {
+		t.Errorf("OnPut() called %d times, want 1", puts)
+	}
+
+	// No partition is created for absent keys.
+	empty := New[string, int]()
+	for i := range 100 {
+		if empty.PutIfPresent(fmt.Sprint(i), i) {
+			t.Fatalf("PutIfPresent(%d) on empty map = true, want false", i)
+		}
+	}
+	if got := empty.PartitionStats().Parts; 0 != got {
+		t.Errorf("PartitionStats().Parts = %d, want 0", got)
+	}
+
+	// Expired entries count as absent.
+	tpm, clock := newFakeTTL(time.Minute)
+	tpm.Put("x", 1)
+	clock.Advance(time.Minute)
+	if tpm.PutIfPresent("x", 2) {
+		t.Error("PutIfPresent() on expired key = true, want false")
+	}
+
+	var npm *TPartitionMap[string, int]
+	if npm.PutIfPresent("a", 1) {
+		t.Error("PutIfPresent() on nil map = true, want false")
+	}
+} // Test_TPartitionMap_PutIfPresent()
+
+func Test_TPartitionMap_StressTest_Int64Keys(t *testing.T) {
+	// This test is designed to:
+	//
+	// 1. Run multiple goroutines concurrently to test thread safety with int64 keys.
+	// 2. Exercise all `TPartitionMap` methods with int64 keys and string values.
+	// 3. Perform a mix of operations to simulate real-world usage patterns.
+	// 4. Verify the map remains functional after stress testing.
+	// 5. Report performance metrics (operations per second).
+	//
+	// The test can be skipped in short mode with `go test -short` for
+	// quicker test runs.
+	if testing.Short() {
+		t.Skip("Skipping int64 keys stress test in short mode")
+	}
+
+	const (
+		numOperations = 1 << 16
+		numGoroutines = 1 << 8
+	)
+
+	pm := New[int64, string]()
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+
+	// Create a channel to coordinate operations
+	ops := make(chan int, numOperations)
+	for i := range numOperations {
+		ops <- i
+	}
+	close(ops)
+
+	// Create a function to run operations concurrently
+	runOperations := func(id int) {
+		defer wg.Done()
+		for op := range ops {
+			// Use int64 keys
+			key := int64(id*numOperations + op)
+			value := fmt.Sprintf("value-%d-%d", id, op)
+
+			// Mix of operations to stress test all capabilities
+			switch op % 8 {
+			case 0: // Put
+				pm.Put(key, value)
+			case 1: // Get
+				pm.Get(key)
+			case 2: // Delete
+				pm.Delete(key)
+			case 3: // ForEach (limited scope)
+				pm.ForEach(func(k int64, v string) {
+					// Just access the values
+					_ = fmt.Sprintf("%d:%s", k, v)
+				})
+			case 4: // Keys
+				keys := pm.Keys()
+				if 100 < len(keys) {
+					// Limit memory usage in test
+					keys = keys[:100]
+					_ = keys
+				}
+			case 5: // Len
+				_ = pm.Len()
+			case 6: // String
+				s := pm.String()
+				if 0 < len(s) {
+					// Limit memory usage in test
+					s = ""
+					_ = s
+				}
+			case 7: // Values
+				values := pm.Values()
+				if 100 < len(values) {
+					// Limit memory usage in test
+					values = values[:100]
+					_ = values
+				}
+			} // switch op % 8
+
+			// Occasionally perform more expensive operations
+			if 0 == op%1024 {
+				// Get length
+				_ = pm.Len()
+			}
+
+			// Very occasionally clear the map
+			if (0 == op%512) && (1 == id) {
+				pm.Clear()
+			}
+		}
+	}
+
+	// Start goroutines
+	start := time.Now()
+	for i := range numGoroutines {
+		go runOperations(i)
+	}
+
+	// Wait for all operations to complete
+	wg.Wait()
+	duration := time.Since(start)
+
+	// Report results
+	t.Logf("Int64 keys stress test completed: %d operations across %d goroutines in %v",
+		numOperations, numGoroutines, duration)
+	t.Logf("Operations per second: %.2f", float64(numOperations)/duration.Seconds())
+	t.Logf("Final map size: %d entries", pm.Len())
+
+	// Verify the map is still functional after stress
+	testKey := int64(9223372036854775807) // max int64
+	testValue := "max-int64-test-value"
+	pm.Put(testKey, testValue)
+	val, exists := pm.Get(testKey)
+	if !exists || (val != testValue) {
+		t.Errorf("Map integrity check failed after int64 keys stress test")
+	}
+
+	metrics := pm.PartitionStats()
+	t.Logf("StressTest_Int64Keys(): partitions = %d, keys = %d, average = %d, metrics.PartKeys = %v",
+		metrics.Parts, metrics.Keys, metrics.Avg, metrics.PartKeys)
+} // Test_TPartitionMap_StressTest_Int64Keys()
+
+func Test_TPartitionMap_StressTest_StringKeys(t *testing.T) {
+	// This test is designed to:
+	//
+	// 1. Run multiple goroutines concurrently to test thread safety.
+	// 2. Exercise all `TPartitionMap` methods: `Put()`, `Get()`, `Delete()`,
+	// `ForEach()`, `Keys()`, `Len()`, `String()`, `Clear()`.
+	// 3. Perform a mix of operations to simulate real-world usage patterns.
+	// 4. Verify the map remains functional after stress testing.
+	// 5. Report performance metrics (operations per second).
+	//
+	// The test can be skipped in short mode with `go test -short` for
+	// quicker test runs.
+	if testing.Short() {
+		t.Skip("Skipping stress test in short mode")
+	}
+
+	const (
+		numOperations = 1 << 16
+		numGoroutines = 1 << 8
+	)
+
+	pm := New[string, string]()
+	var wg sync.WaitGroup
+	wg.Add(numGoroutines)
+
+	// Create a channel to coordinate operations
+	ops := make(chan int, numOperations)
+	for i := range numOperations {
+		ops <- i
+	}
+	close(ops)
+
+	// Create a function to run operations concurrently
+	runOperations := func(id int) {
 		defer wg.Done()
 		for op := range ops {
-			// Use int64 keys
-			key := int64(id*numOperations + op)
+			key := fmt.Sprintf("key-%d-%d", id, op)
 			value := fmt.Sprintf("value-%d-%d", id, op)
 
-			// Mix of operations to stress test all capabilities
-			switch op % 8 {
-			case 0: // Put
-				pm.Put(key, value)
-			case 1: // Get
-				pm.Get(key)
-			case 2: // Delete
-				pm.Delete(key)
-			case 3: // ForEach (limited scope)
-				pm.ForEach(func(k int64, v string) {
-					// Just access the values
-					_ = fmt.Sprintf("%d:%s", k, v)
-				})
-			case 4: // Keys
-				keys := pm.Keys()
-				if 100 < len(keys) {
-					// Limit memory usage in test
-					keys = keys[:100]
-					_ = keys
+			// Mix of operations to stress test all capabilities
+			switch op % 8 {
+			case 0: // Put
+				pm.Put(key, value)
+			case 1: // Get
+				pm.Get(key)
+			case 2: // Delete
+				pm.Delete(key)
+			case 3: // ForEach (limited scope)
+				pm.ForEach(func(k string, v string) {
+					// Just access the values
+					_ = k + v
+				})
+			case 4: // Keys
+				keys := pm.Keys()
+				if 100 < len(keys) {
+					// Limit memory usage in test
+					keys = keys[:100]
+					_ = keys
+				}
+			case 5: // Len
+				_ = pm.Len()
+			case 6: // String
+				s := pm.String()
+				if 0 < len(s) {
+					// Limit memory usage in test
+					s = ""
+					_ = s
+				}
+			case 7: // Values
+				values := pm.Values()
+				if 100 < len(values) {
+					// Limit memory usage in test
+					values = values[:100]
+					_ = values
+				}
+			} // switch op % 8
+
+			// Occasionally perform more expensive operations
+			if 0 == op%1024 {
+				// Get length
+				_ = pm.Len()
+			}
+
+			// Very occasionally clear the map
+			if (0 == op%512) && (1 == id) {
+				pm.Clear()
+			}
+		}
+	}
+
+	// Start goroutines
+	start := time.Now()
+	for i := range numGoroutines {
+		go runOperations(i)
+	}
+
+	// Wait for all operations to complete
+	wg.Wait()
+	duration := time.Since(start)
+
+	// Report results
+	t.Logf("Stress test completed: %d operations across %d goroutines in %v",
+		numOperations, numGoroutines, duration)
+	t.Logf("Operations per second: %.2f", float64(numOperations)/duration.Seconds())
+	t.Logf("Final map size: %d entries", pm.Len())
+
+	// Verify the map is still functional after stress
+	testKey := "final-test-key"
+	testValue := "final-test-value"
+	pm.Put(testKey, testValue)
+	val, exists := pm.Get(testKey)
+	if !exists || (val != testValue) {
+		t.Errorf("Map integrity check failed after stress test")
+	}
+
+	metrics := pm.PartitionStats()
+	t.Logf("StressTest_StringKeys(): partitions = %d, keys = %d, average = %d, metrics.PartKeys = %v",
+		metrics.Parts, metrics.Keys, metrics.Avg, metrics.PartKeys)
+} // Test_TPartitionMap_StressTest_StringKeys()
+
+func Test_TPartitionMap_RangeKeys(t *testing.T) {
+	newMap := func() *TPartitionMap[int, string] {
+		pm := New[int, string]()
+		for i := 0; i < 1000; i += 10 {
+			pm.Put(i, fmt.Sprint(i))
+		}
+		return pm
+	}
+
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[int, string]
+		lo   int
+		hi   int
+		want []int
+	}{
+		{
+			name: "Inclusive bounds",
+			pm:   newMap(),
+			lo:   100,
+			hi:   150,
+			want: []int{100, 110, 120, 130, 140, 150},
+		},
+		{
+			name: "Bounds between keys",
+			pm:   newMap(),
+			lo:   101,
+			hi:   129,
+			want: []int{110, 120},
+		},
+		{
+			name: "Single key",
+			pm:   newMap(),
+			lo:   500,
+			hi:   500,
+			want: []int{500},
+		},
+		{
+			name: "Empty range",
+			pm:   newMap(),
+			lo:   501,
+			hi:   509,
+			want: []int{},
+		},
+		{
+			name: "Lo greater than hi",
+			pm:   newMap(),
+			lo:   200,
+			hi:   100,
+			want: []int{},
+		},
+		{
+			name: "Empty partition map",
+			pm:   New[int, string](),
+			lo:   0,
+			hi:   100,
+			want: []int{},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			lo:   0,
+			hi:   100,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.RangeKeys(tc.lo, tc.hi)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("RangeKeys() = %v, want %v", got, tc.want)
+			}
+
+			entries := tc.pm.RangeEntries(tc.lo, tc.hi)
+			if len(entries) != len(tc.want) {
+				t.Errorf("RangeEntries() returned %d pairs, want %d",
+					len(entries), len(tc.want))
+				return
+			}
+			for idx, e := range entries {
+				if (e.Key != tc.want[idx]) || (e.Value != fmt.Sprint(e.Key)) {
+					t.Errorf("RangeEntries()[%d] = %v, want key %d",
+						idx, e, tc.want[idx])
+				}
+			}
+		})
+	}
+} // Test_TPartitionMap_RangeKeys()
+
+func Test_TPartitionMap_Rebalance(t *testing.T) {
+	// Sequential IDs being multiples of 128 all end up in the
+	// same partition with a plain modulo distribution.
+	const numKeys = numberOfPartitionsInMap * 4
+
+	pm := NewWithHasher[int, int](func(aKey int) uint64 {
+		return uint64(aKey) //#nosec G115
+	})
+	for i := range numKeys {
+		pm.Put(i*numberOfPartitionsInMap, i)
+	}
+	if got := pm.PartitionStats().Parts; 1 != got {
+		t.Fatalf("PartitionStats().Parts = %d, want 1", got)
+	}
+
+	gen := pm.Generation()
+	pm.SetHasher(func(aKey int) uint64 {
+		return uint64(aKey / numberOfPartitionsInMap)
+	})
+	if got := pm.Rebalance(); got != pm {
+		t.Errorf("Rebalance() returned different instance")
+	}
+
+	stats := pm.PartitionStats()
+	if numberOfPartitionsInMap != stats.Parts {
+		t.Errorf("PartitionStats().Parts = %d, want %d",
+			stats.Parts, numberOfPartitionsInMap)
+	}
+	if want := numKeys / numberOfPartitionsInMap; want != stats.MaxKeys {
+		t.Errorf("PartitionStats().MaxKeys = %d, want %d",
+			stats.MaxKeys, want)
+	}
+	if numKeys != stats.Keys {
+		t.Errorf("PartitionStats().Keys = %d, want %d", stats.Keys, numKeys)
+	}
+	if got := pm.Generation(); gen != got {
+		t.Errorf("Generation() = %d, want %d", got, gen)
+	}
+
+	// All key based operations must find the moved entries.
+	for i := range numKeys {
+		key := i * numberOfPartitionsInMap
+		if v, ok := pm.Get(key); !ok || (i != v) {
+			t.Fatalf("Get(%d) = %d, %v, want %d, true", key, v, ok, i)
+		}
+	}
+	if got := pm.DeleteAll(pm.Keys()); numKeys != got {
+		t.Errorf("DeleteAll() = %d, want %d", got, numKeys)
+	}
+
+	var npm *TPartitionMap[int, int]
+	if nil != npm.Rebalance() || nil != npm.SetHasher(nil) {
+		t.Error("Rebalance()/SetHasher() on nil map should return nil")
+	}
+} // Test_TPartitionMap_Rebalance()
+
+func Test_TPartitionMap_Rebalance_Concurrent(t *testing.T) {
+	const (
+		numWriters = 8
+		numKeys    = 1000
+	)
+
+	// Every hasher installed distributes the keys the same way, so
+	// the entries are found whether or not they were moved yet.
+	hasher := func() func(int) uint64 {
+		return func(aKey int) uint64 {
+			return uint64(aKey) * 31 //#nosec G115
+		}
+	}
+	pm := NewWithHasher[int, int](hasher())
+	var (
+		wg   sync.WaitGroup
+		done atomic.Bool
+	)
+	for g := range numWriters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; (numKeys > i) || !done.Load(); i++ {
+				key := g*numKeys + i%numKeys
+				pm.Put(key, i)
+				if v, ok := pm.Get(key); !ok || i != v {
+					t.Errorf("Get(%d) = (%d, %v), want (%d, true)", key, v, ok, i)
+					return
+				}
+			}
+		}()
+	}
+	for range 20 {
+		pm.SetHasher(hasher()).Rebalance()
+	}
+	done.Store(true)
+	wg.Wait()
+
+	if want := numWriters * numKeys; want != pm.Len() {
+		t.Errorf("Len() = %d, want %d", pm.Len(), want)
+	}
+	recount(t, pm)
+} // Test_TPartitionMap_Rebalance_Concurrent()
+
+func Test_TPartitionMap_Reset(t *testing.T) {
+	pm := New[int, int]()
+	for i := range 1000 {
+		pm.Put(i, i)
+	}
+
+	gen := pm.Generation()
+	if got := pm.Reset(); got != pm {
+		t.Errorf("Reset() returned different instance")
+	}
+	if got := pm.Len(); 0 != got {
+		t.Errorf("Len() after Reset() = %d, want 0", got)
+	}
+	if got := pm.PartitionStats().Parts; 0 != got {
+		t.Errorf("PartitionStats().Parts after Reset() = %d, want 0", got)
+	}
+	if got := pm.Generation(); gen == got {
+		t.Error("Reset() didn't advance the generation")
+	}
+	if _, ok := pm.Get(1); ok {
+		t.Error("Get(1) after Reset() = true, want false")
+	}
+
+	// The map is still usable afterwards.
+	pm.Put(1, 11).Put(2, 22)
+	if v, ok := pm.Get(1); !ok || 11 != v {
+		t.Errorf("Get(1) = (%d, %v), want (11, true)", v, ok)
+	}
+	if got := pm.Len(); 2 != got {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+	recount(t, pm)
+
+	var npm *TPartitionMap[int, int]
+	if nil != npm.Reset() {
+		t.Error("Reset() on nil map should return nil")
+	}
+} // Test_TPartitionMap_Reset()
+
+func Test_TPartitionMap_Resize(t *testing.T) {
+	const numKeys = 1000
+
+	pm := New[string, int]()
+	for i := range numKeys {
+		pm.Put(fmt.Sprintf("key%04d", i), i)
+	}
+	want := pm.ToMap()
+
+	for _, count := range []int{16, numberOfPartitionsInMap, 1, maxPartitionsInMap + 1} {
+		if got := pm.Resize(count); got != pm {
+			t.Fatalf("Resize(%d) returned different instance", count)
+		}
+		wantCount := min(count, maxPartitionsInMap)
+		if got := len(pm.slots()); wantCount != got {
+			t.Errorf("Resize(%d): %d partitions, want %d", count, got, wantCount)
+		}
+		if got := pm.PartitionStats().Parts; got > wantCount {
+			t.Errorf("Resize(%d): %d partitions in use", count, got)
+		}
+		if got := pm.ToMap(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Resize(%d) lost entries: got %d, want %d",
+				count, len(got), len(want))
+		}
+		for k, v := range want {
+			if got, ok := pm.Get(k); !ok || got != v {
+				t.Fatalf("Resize(%d): Get(%q) = (%d, %v), want (%d, true)",
+					count, k, got, ok, v)
+			}
+		}
+	}
+
+	// Resizing to the current number of partitions is a no-op.
+	layout := pm.layout.Load()
+	pm.Resize(len(layout.tPartitionList))
+	if layout != pm.layout.Load() {
+		t.Error("Resize() to the current size replaced the partitions")
+	}
+
+	var npm *TPartitionMap[string, int]
+	if nil != npm.Resize(16) {
+		t.Error("Resize() on nil map should return nil")
+	}
+} // Test_TPartitionMap_Resize()
+
+func Test_TPartitionMap_Resize_Concurrent(t *testing.T) {
+	const (
+		numWriters = 8
+		numKeys    = 1000
+	)
+
+	pm := NewOrdered[int, int]()
+	var (
+		wg    sync.WaitGroup
+		done  atomic.Bool
+		calls [numWriters]int
+	)
+	for g := range numWriters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Keep writing until all resizes are done.
+			for i := 0; (numKeys > i) || !done.Load(); i++ {
+				key := g*numKeys + i%numKeys
+				pm.Put(key, i)
+				// Not idempotent: a repeated increment would be counted twice.
+				Increment(pm, -1-g, 1)
+				calls[g]++
+				if v, ok := pm.Get(key); !ok || i != v {
+					t.Errorf("Get(%d) = (%d, %v), want (%d, true)", key, v, ok, i)
+					return
+				}
+			}
+		}()
+	}
+	for range 4 {
+		for _, count := range []int{1, 7, 64, numberOfPartitionsInMap, 300} {
+			pm.Resize(count)
+		}
+	}
+	done.Store(true)
+	wg.Wait()
+
+	for g := range numWriters {
+		for i := range numKeys {
+			if v, ok := pm.Get(g*numKeys + i); !ok || i != v%numKeys {
+				t.Fatalf("Get(%d) = (%d, %v), want (%d, true)", g*numKeys+i, v, ok, i)
+			}
+		}
+		if v, _ := pm.Get(-1 - g); calls[g] != v {
+			t.Errorf("counter %d = %d, want %d", g, v, calls[g])
+		}
+	}
+	if want := numWriters*numKeys + numWriters; want != pm.Len() {
+		t.Errorf("Len() = %d, want %d", pm.Len(), want)
+	}
+	recount(t, pm)
+	if got := len(pm.OrderedKeys()); pm.Len() != got {
+		t.Errorf("OrderedKeys() returned %d keys, want %d", got, pm.Len())
+	}
+} // Test_TPartitionMap_Resize_Concurrent()
+
+func Test_TPartitionMap_Select(t *testing.T) {
+	pm := New[string, int]()
+	for i := range 100 {
+		pm.Put(fmt.Sprintf("key%02d", i), i)
+	}
+
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		keys []string
+		want map[string]int
+	}{
+		{
+			name: "All keys present",
+			pm:   pm,
+			keys: []string{"key01", "key42", "key99"},
+			want: map[string]int{"key01": 1, "key42": 42, "key99": 99},
+		},
+		{
+			name: "Some keys missing",
+			pm:   pm,
+			keys: []string{"key07", "nokey", "key08", "key100"},
+			want: map[string]int{"key07": 7, "key08": 8},
+		},
+		{
+			name: "Duplicate keys",
+			pm:   pm,
+			keys: []string{"key13", "key13", "key31", "key13"},
+			want: map[string]int{"key13": 13, "key31": 31},
+		},
+		{
+			name: "No keys present",
+			pm:   pm,
+			keys: []string{"a", "b"},
+			want: map[string]int{},
+		},
+		{
+			name: "Nil keys",
+			pm:   pm,
+			keys: nil,
+			want: map[string]int{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.Select(tc.keys)
+			if got == tc.pm {
+				t.Fatal("Select() returned the same instance")
+			}
+			if !reflect.DeepEqual(got.ToMap(), tc.want) {
+				t.Errorf("Select() = %v, want %v", got.ToMap(), tc.want)
+			}
+			if got.Len() != len(tc.want) {
+				t.Errorf("Select() Len() = %d, want %d", got.Len(), len(tc.want))
+			}
+			if 100 != tc.pm.Len() {
+				t.Errorf("Select() modified the source map: Len() = %d", tc.pm.Len())
+			}
+		})
+	}
+
+	// The selection is independent of the source map.
+	sel := pm.Select([]string{"key05"})
+	sel.Put("key05", 555)
+	if v, _ := pm.Get("key05"); 5 != v {
+		t.Errorf("Get(key05) after modifying selection = %d, want 5", v)
+	}
+
+	var npm *TPartitionMap[string, int]
+	if nil != npm.Select([]string{"a"}) {
+		t.Error("Select() on nil map should return nil")
+	}
+} // Test_TPartitionMap_Select()
+
+func Test_TPartitionMap_SetAll(t *testing.T) {
+	pm := New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3)
+	gen := pm.Generation()
+
+	want := map[string]int{"b": 22, "x": 7, "y": 8, "z": 9}
+	if got := pm.SetAll(want); got != pm {
+		t.Fatal("SetAll() returned different instance")
+	}
+	if got := pm.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("SetAll() = %v, want %v", got, want)
+	}
+	if got := pm.Len(); len(want) != got {
+		t.Errorf("Len() = %d, want %d", got, len(want))
+	}
+	recount(t, pm)
+	if pm.Generation() == gen {
+		t.Error("SetAll() didn't advance the generation")
+	}
+
+	// The source map is copied, not shared.
+	want["w"] = 0
+	if pm.Has("w") {
+		t.Error("SetAll() shares the source map")
+	}
+
+	pm.SetAll(nil)
+	if got := pm.Len(); 0 != got {
+		t.Errorf("Len() after SetAll(nil) = %d, want 0", got)
+	}
+
+	// LRU maps keep their size limit.
+	lru := NewLRU[int, int](8)
+	src := make(map[int]int, 100)
+	for i := range 100 {
+		src[i] = i
+	}
+	if got := lru.SetAll(src).Len(); 8 < got {
+		t.Errorf("LRU Len() after SetAll() = %d, want <= 8", got)
+	}
+
+	var npm *TPartitionMap[string, int]
+	if nil != npm.SetAll(want) {
+		t.Error("SetAll() on nil map should return nil")
+	}
+} // Test_TPartitionMap_SetAll()
+
+func Test_TPartitionMap_SetAll_Concurrent(t *testing.T) {
+	const (
+		oldSize = 1000
+		newSize = 300
+	)
+
+	oldData := make(map[int]int, oldSize)
+	for i := range oldSize {
+		oldData[i] = i
+	}
+	newData := make(map[int]int, newSize)
+	for i := range newSize {
+		newData[i+oldSize] = i
+	}
+
+	pm := New[int, int]().SetAll(oldData)
+	var (
+		done atomic.Bool
+		wg   sync.WaitGroup
+	)
+	wg.Add(4)
+	for range 4 {
+		go func() {
+			defer wg.Done()
+			for !done.Load() {
+				if n := pm.Len(); (oldSize != n) && (newSize != n) {
+					t.Errorf("Len() = %d, want %d or %d", n, oldSize, newSize)
+					return
 				}
-			case 5: // Len
-				_ = pm.Len()
-			case 6: // String
-				s := pm.String()
-				if 0 < len(s) {
-					// Limit memory usage in test
-					s = ""
-					_ = s
+			}
+		}()
+	}
+
+	for i := range 100 {
+		if 0 == i%2 {
+			pm.SetAll(newData)
+		} else {
+			pm.SetAll(oldData)
+		}
+	}
+	done.Store(true)
+	wg.Wait()
+
+	if got := pm.ToMap(); !reflect.DeepEqual(got, oldData) {
+		t.Errorf("SetAll() final contents: %d pairs, want %d", len(got), oldSize)
+	}
+} // Test_TPartitionMap_SetAll_Concurrent()
+
+func Test_TPartitionMap_SetAll_ConcurrentPuts(t *testing.T) {
+	const (
+		numWriters = 4
+		numKeys    = 16000 // per writer
+		numSource  = 16
+	)
+
+	source := make(map[int]int, numSource)
+	for i := range numSource {
+		source[-1-i] = i
+	}
+
+	// Most partitions are created by the writers, not by `SetAll()`.
+	pm := NewWithPartitions[int, int](4096).SetAll(source)
+	var (
+		done atomic.Bool
+		wg   sync.WaitGroup
+	)
+	for g := range numWriters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; !done.Load(); i++ {
+				pm.Put(g*numKeys+i%numKeys, i)
+				// The replaced pairs must never be missing.
+				if key := -1 - i%numSource; !pm.Has(key) {
+					t.Errorf("Has(%d) = false during SetAll()", key)
+					return
 				}
-			case 7: // Values
-				values := pm.Values()
-				if 100 < len(values) {
-					// Limit memory usage in test
-					values = values[:100]
-					_ = values
+			}
+		}()
+	}
+
+	for range 100 {
+		pm.SetAll(source)
+	}
+	done.Store(true)
+	wg.Wait()
+
+	// The replacement must be complete and the running total correct.
+	got := pm.ToMap()
+	for k, v := range source {
+		if w, ok := got[k]; !ok || v != w {
+			t.Fatalf("Get(%d) = (%d, %v), want (%d, true)", k, w, ok, v)
+		}
+	}
+	for k := range got {
+		if (0 <= k) && (numWriters*numKeys <= k) {
+			t.Errorf("unexpected key %d", k)
+		}
+	}
+	if len(got) != pm.Len() {
+		t.Errorf("Len() = %d, want %d", pm.Len(), len(got))
+	}
+	recount(t, pm)
+} // Test_TPartitionMap_SetAll_ConcurrentPuts()
+
+func Test_TPartitionMap_ShrinkToFit(t *testing.T) {
+	const numKeys = 1 << 14
+
+	pm := NewWithTTL[int, int](time.Hour)
+	for i := range numKeys {
+		pm.Put(i, i)
+	}
+	pm.DeleteIf(func(aKey, aValue int) bool {
+		return 0 != aKey%16
+	})
+	want := pm.ToMap()
+
+	if got := pm.ShrinkToFit(); got != pm {
+		t.Errorf("ShrinkToFit() returned different instance")
+	}
+	if got := pm.ToMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ShrinkToFit() lost entries: got %d, want %d",
+			len(got), len(want))
+	}
+	for _, p := range pm.partitions() {
+		if nil == p {
+			continue
+		}
+		if p.peak != len(p.kv) {
+			t.Errorf("peak = %d, want %d", p.peak, len(p.kv))
+		}
+		if len(p.deadlines) != len(p.kv) {
+			t.Errorf("len(deadlines) = %d, want %d",
+				len(p.deadlines), len(p.kv))
+		}
+	}
+
+	// A compact map stays unchanged.
+	p, _ := pm.partition(0, false)
+	kv := p.kv
+	pm.ShrinkToFit()
+	if reflect.ValueOf(kv).Pointer() != reflect.ValueOf(p.kv).Pointer() {
+		t.Error("ShrinkToFit() rebuilt a compact partition")
+	}
+
+	var npm *TPartitionMap[int, int]
+	if nil != npm.ShrinkToFit() {
+		t.Error("ShrinkToFit() on nil map should return nil")
+	}
+} // Test_TPartitionMap_ShrinkToFit()
+
+func Test_TPartitionMap_Snapshot(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+		},
+		{
+			name: "Partition map with values",
+			pm:   New[string, int]().Put("a", 1).Put("b", 2).Put("c", 3),
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.Snapshot()
+			if nil == tc.pm {
+				if nil != got {
+					t.Errorf("Snapshot() = %v, want nil", got)
 				}
-			} // switch op % 8
+				return
+			}
+			if !Equal(got, tc.pm) {
+				t.Errorf("Snapshot() = %v, want %v", got.ToMap(), tc.pm.ToMap())
+			}
 
-			// Occasionally perform more expensive operations
-			if 0 == op%1024 {
-				// Get length
-				_ = pm.Len()
+			// The snapshot is independent of the original.
+			tc.pm.Put("x", 99)
+			if _, ok := got.Get("x"); ok {
+				t.Errorf("Snapshot() changed with original")
 			}
+		})
+	}
+} // Test_TPartitionMap_Snapshot()
 
-			// Very occasionally clear the map
-			if (0 == op%512) && (1 == id) {
-				pm.Clear()
+func Test_TPartitionMap_Snapshot_Concurrent(t *testing.T) {
+	// A single writer inserts ascending keys, so each consistent
+	// snapshot must contain exactly the keys `0` to `Len()-1`.
+	const numKeys = 1 << 14
+
+	pm := New[int, int]()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range numKeys {
+			pm.Put(i, i)
+		}
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		snap := pm.Snapshot()
+		size := snap.Len()
+		for i := range size {
+			if _, ok := snap.Get(i); !ok {
+				t.Fatalf("Snapshot() of %d keys misses key %d", size, i)
 			}
 		}
+
+		// The snapshot doesn't change while the original does.
+		runtime.Gosched()
+		if got := snap.Len(); got != size {
+			t.Fatalf("Snapshot().Len() = %d, want %d", got, size)
+		}
 	}
+} // Test_TPartitionMap_Snapshot_Concurrent()
 
-	// Start goroutines
-	start := time.Now()
-	for i := range numGoroutines {
-		go runOperations(i)
+func Test_TPartitionMap_String(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[int, string]
+		want string
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[int, string](),
+			want: "",
+		},
+		{
+			name: "Partition map with values",
+			pm: New[int, string]().
+				Put(300, "c").
+				Put(-1, "minus one").
+				Put(2, "b").
+				Put(129, "a"), // same partition as `1`
+			want: "-1: 'minus one'\n2: 'b'\n129: 'a'\n300: 'c'\n",
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: "",
+		},
 	}
 
-	// Wait for all operations to complete
-	wg.Wait()
-	duration := time.Since(start)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pm.String(); got != tc.want {
+				t.Errorf("String() = %q, want %q", got, tc.want)
+			}
+		})
+	}
 
-	// Report results
-	t.Logf("Int64 keys stress test completed: %d operations across %d goroutines in %v",
-		numOperations, numGoroutines, duration)
-	t.Logf("Operations per second: %.2f", float64(numOperations)/duration.Seconds())
-	t.Logf("Final map size: %d entries", pm.Len())
+	// The output is deterministic for many keys across all partitions.
+	pm := New[int, int]()
+	for i := range 1000 {
+		pm.Put(1000-i, i)
+	}
+	got := pm.String()
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if 1000 != len(lines) {
+		t.Fatalf("String() has %d lines, want 1000", len(lines))
+	}
+	if "1: '999'" != lines[0] || "1000: '0'" != lines[999] {
+		t.Errorf("String() lines = %q ... %q", lines[0], lines[999])
+	}
+	if again := pm.String(); again != got {
+		t.Errorf("String() isn't deterministic")
+	}
+} // Test_TPartitionMap_String()
 
-	// Verify the map is still functional after stress
-	testKey := int64(9223372036854775807) // max int64
-	testValue := "max-int64-test-value"
-	pm.Put(testKey, testValue)
-	val, exists := pm.Get(testKey)
-	if !exists || (val != testValue) {
-		t.Errorf("Map integrity check failed after int64 keys stress test")
+func Test_TPartitionMap_StringFunc(t *testing.T) {
+	asAssignment := func(aKey string, aValue int) string {
+		return fmt.Sprintf("%s=%d", aKey, aValue)
 	}
 
-	metrics := pm.PartitionStats()
-	t.Logf("StressTest_Int64Keys(): partitions = %d, keys = %d, average = %d, metrics.PartKeys = %v",
-		metrics.Parts, metrics.Keys, metrics.Avg, metrics.PartKeys)
-} // Test_TPartitionMap_StressTest_Int64Keys()
+	tests := []struct {
+		name   string
+		pm     *TPartitionMap[string, int]
+		format func(string, int) string
+		want   string
+	}{
+		{
+			name:   "Custom format",
+			pm:     New[string, int]().Put("b", 2).Put("a", 1).Put("c", 3),
+			format: asAssignment,
+			want:   "a=1\nb=2\nc=3\n",
+		},
+		{
+			name:   "Default format",
+			pm:     New[string, int]().Put("b", 2).Put("a", 1),
+			format: nil,
+			want:   "a: '1'\nb: '2'\n",
+		},
+		{
+			name:   "Empty partition map",
+			pm:     New[string, int](),
+			format: asAssignment,
+			want:   "",
+		},
+		{
+			name:   "Nil partition map",
+			pm:     nil,
+			format: asAssignment,
+			want:   "",
+		},
+	}
 
-func Test_TPartitionMap_StressTest_StringKeys(t *testing.T) {
-	// This test is designed to:
-	//
-	// 1. Run multiple goroutines concurrently to test thread safety.
-	// 2. Exercise all `TPartitionMap` methods: `Put()`, `Get()`, `Delete()`,
-	// `ForEach()`, `Keys()`, `Len()`, `String()`, `Clear()`.
-	// 3. Perform a mix of operations to simulate real-world usage patterns.
-	// 4. Verify the map remains functional after stress testing.
-	// 5. Report performance metrics (operations per second).
-	//
-	// The test can be skipped in short mode with `go test -short` for
-	// quicker test runs.
-	if testing.Short() {
-		t.Skip("Skipping stress test in short mode")
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pm.StringFunc(tc.format); got != tc.want {
+				t.Errorf("StringFunc() = %q, want %q", got, tc.want)
+			}
+		})
 	}
+} // Test_TPartitionMap_StringFunc()
 
-	const (
-		numOperations = 1 << 16
-		numGoroutines = 1 << 8
-	)
+func Test_TPartitionMap_StringN(t *testing.T) {
+	pm := New[int, string]().Put(3, "c").Put(1, "a").Put(2, "b")
 
-	pm := New[string, string]()
-	var wg sync.WaitGroup
-	wg.Add(numGoroutines)
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[int, string]
+		max  int
+		want string
+	}{
+		{
+			name: "Under the cap",
+			pm:   pm,
+			max:  10,
+			want: "1: 'a'\n2: 'b'\n3: 'c'\n",
+		},
+		{
+			name: "At the cap",
+			pm:   pm,
+			max:  3,
+			want: "1: 'a'\n2: 'b'\n3: 'c'\n",
+		},
+		{
+			name: "Over the cap",
+			pm:   pm,
+			max:  2,
+			want: "1: 'a'\n2: 'b'\n... (1 more)\n",
+		},
+		{
+			name: "Zero cap",
+			pm:   pm,
+			max:  0,
+			want: "... (3 more)\n",
+		},
+		{
+			name: "Empty partition map",
+			pm:   New[int, string](),
+			max:  2,
+			want: "",
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			max:  2,
+			want: "",
+		},
+	}
 
-	// Create a channel to coordinate operations
-	ops := make(chan int, numOperations)
-	for i := range numOperations {
-		ops <- i
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.pm.StringN(tc.max); got != tc.want {
+				t.Errorf("StringN(%d) = %q, want %q", tc.max, got, tc.want)
+			}
+		})
+	}
+
+	// A generous cap yields the same output as `String()`.
+	big := New[int, int]()
+	for i := range 1000 {
+		big.Put(i, i)
+	}
+	if got, want := big.StringN(1000), big.String(); got != want {
+		t.Error("StringN(Len()) differs from String()")
+	}
+	if got := big.StringN(5); !strings.HasSuffix(got, "4: '4'\n... (995 more)\n") {
+		t.Errorf("StringN(5) = %q", got)
 	}
-	close(ops)
+} // Test_TPartitionMap_StringN()
 
-	// Create a function to run operations concurrently
-	runOperations := func(id int) {
-		defer wg.Done()
-		for op := range ops {
-			key := fmt.Sprintf("key-%d-%d", id, op)
-			value := fmt.Sprintf("value-%d-%d", id, op)
+func Test_TPartitionMap_Take(t *testing.T) {
+	const numKeys = 500
 
-			// Mix of operations to stress test all capabilities
-			switch op % 8 {
-			case 0: // Put
-				pm.Put(key, value)
-			case 1: // Get
-				pm.Get(key)
-			case 2: // Delete
-				pm.Delete(key)
-			case 3: // ForEach (limited scope)
-				pm.ForEach(func(k string, v string) {
-					// Just access the values
-					_ = k + v
-				})
-			case 4: // Keys
-				keys := pm.Keys()
-				if 100 < len(keys) {
-					// Limit memory usage in test
-					keys = keys[:100]
-					_ = keys
-				}
-			case 5: // Len
-				_ = pm.Len()
-			case 6: // String
-				s := pm.String()
-				if 0 < len(s) {
-					// Limit memory usage in test
-					s = ""
-					_ = s
-				}
-			case 7: // Values
-				values := pm.Values()
-				if 100 < len(values) {
-					// Limit memory usage in test
-					values = values[:100]
-					_ = values
-				}
-			} // switch op % 8
+	newMap := func() *TPartitionMap[int, int] {
+		pm := New[int, int]()
+		for i := range numKeys {
+			pm.Put(i, i*10)
+		}
+		return pm
+	}
 
-			// Occasionally perform more expensive operations
-			if 0 == op%1024 {
-				// Get length
-				_ = pm.Len()
+	for _, n := range []int{-1, 0, 1, 10, numKeys - 1, numKeys, numKeys * 2} {
+		pm := newMap()
+		want := max(min(n, numKeys), 0)
+
+		got := pm.Take(n)
+		if want != len(got) {
+			t.Errorf("Take(%d) returned %d pairs, want %d", n, len(got), want)
+		}
+		for k, v := range got {
+			if mv, ok := pm.Get(k); !ok || mv != v {
+				t.Errorf("Take(%d) returned (%d, %d), not a member", n, k, v)
 			}
+		}
+		if numKeys != pm.Len() {
+			t.Errorf("Take(%d) changed Len() to %d", n, pm.Len())
+		}
 
-			// Very occasionally clear the map
-			if (0 == op%512) && (1 == id) {
-				pm.Clear()
+		var deleted int
+		pm.OnDelete(func(int) { deleted++ })
+		taken := pm.TakeAndDelete(n)
+		if want != len(taken) {
+			t.Errorf("TakeAndDelete(%d) returned %d pairs, want %d", n, len(taken), want)
+		}
+		for k, v := range taken {
+			if k*10 != v {
+				t.Errorf("TakeAndDelete(%d) returned (%d, %d), not a member", n, k, v)
 			}
+			if pm.Has(k) {
+				t.Errorf("TakeAndDelete(%d) left key %d in the map", n, k)
+			}
+		}
+		if got := pm.Len(); numKeys-want != got {
+			t.Errorf("Len() after TakeAndDelete(%d) = %d, want %d", n, got, numKeys-want)
+		}
+		if want != deleted {
+			t.Errorf("OnDelete() called %d times, want %d", deleted, want)
 		}
 	}
 
-	// Start goroutines
-	start := time.Now()
-	for i := range numGoroutines {
-		go runOperations(i)
+	var npm *TPartitionMap[int, int]
+	if nil != npm.Take(1) || nil != npm.TakeAndDelete(1) {
+		t.Error("Take()/TakeAndDelete() on nil map should return nil")
 	}
+} // Test_TPartitionMap_Take()
 
-	// Wait for all operations to complete
-	wg.Wait()
-	duration := time.Since(start)
+func Test_TPartitionMap_TakeAndDelete_Concurrent(t *testing.T) {
+	const (
+		numKeys       = 10_000
+		numGoroutines = 8
+	)
 
-	// Report results
-	t.Logf("Stress test completed: %d operations across %d goroutines in %v",
-		numOperations, numGoroutines, duration)
-	t.Logf("Operations per second: %.2f", float64(numOperations)/duration.Seconds())
-	t.Logf("Final map size: %d entries", pm.Len())
+	pm := New[int, int]()
+	for i := range numKeys {
+		pm.Put(i, i)
+	}
 
-	// Verify the map is still functional after stress
-	testKey := "final-test-key"
-	testValue := "final-test-value"
-	pm.Put(testKey, testValue)
-	val, exists := pm.Get(testKey)
-	if !exists || (val != testValue) {
-		t.Errorf("Map integrity check failed after stress test")
+	var (
+		mtx  sync.Mutex
+		seen = make(map[int]int, numKeys)
+		wg   sync.WaitGroup
+	)
+	wg.Add(numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer wg.Done()
+			for batch := pm.TakeAndDelete(37); 0 < len(batch); batch = pm.TakeAndDelete(37) {
+				mtx.Lock()
+				for k := range batch {
+					seen[k]++
+				}
+				mtx.Unlock()
+			}
+		}()
 	}
+	wg.Wait()
 
-	metrics := pm.PartitionStats()
-	t.Logf("StressTest_StringKeys(): partitions = %d, keys = %d, average = %d, metrics.PartKeys = %v",
-		metrics.Parts, metrics.Keys, metrics.Avg, metrics.PartKeys)
-} // Test_TPartitionMap_StressTest_StringKeys()
+	if numKeys != len(seen) {
+		t.Errorf("TakeAndDelete() returned %d keys, want %d", len(seen), numKeys)
+	}
+	for k, n := range seen {
+		if 1 != n {
+			t.Fatalf("key %d returned %d times, want once", k, n)
+		}
+	}
+} // Test_TPartitionMap_TakeAndDelete_Concurrent()
 
-func Test_TPartitionMap_String(t *testing.T) {
+func Test_TPartitionMap_ToMap(t *testing.T) {
 	tests := []struct {
 		name string
 		pm   *TPartitionMap[string, int]
-		want string
+		want map[string]int
 	}{
 		{
 			name: "Empty partition map",
 			pm:   New[string, int](),
-			want: "",
+			want: map[string]int{},
 		},
 		{
 			name: "Partition map with values",
@@ -865,43 +5066,215 @@ func Test_TPartitionMap_String(t *testing.T) {
 				Put("key1", 100).
 				Put("key2", 200).
 				Put("key3", 300),
-			want: "key1: `100`\nkey2: '200'\nkey3: '300'\n", // Assuming this format from tPartition.String()
+			want: map[string]int{"key1": 100, "key2": 200, "key3": 300},
 		},
 		{
 			name: "Nil partition map",
 			pm:   nil,
-			want: "",
+			want: nil,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := tc.pm.String()
-			// Since we can't guarantee the order of keys in the output,
-			// we'll check that all expected key-value pairs are present
-			if (nil == tc.pm) || (0 == tc.pm.Len()) {
-				if got != tc.want {
-					t.Errorf("String() = %q, want %q",
-						got, tc.want)
-				}
-			} else {
-				for _, key := range []string{"key1", "key2", "key3"} {
-					if !strings.Contains(got, key) {
-						t.Errorf("String() = %q, should contain key %q",
-							got, key)
-					}
-				}
-				for _, val := range []int{100, 200, 300} {
-					valStr := fmt.Sprintf("%d", val)
-					if !strings.Contains(got, valStr) {
-						t.Errorf("String() = %q, should contain value %q",
-							got, valStr)
-					}
+			got := tc.pm.ToMap()
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ToMap() = %v, want %v", got, tc.want)
+			}
+			if nil == tc.pm {
+				return
+			}
+
+			// The result must be independent of the source.
+			tc.pm.Put("key4", 400).Delete("key1")
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ToMap() result changed with source: %v, want %v",
+					got, tc.want)
+			}
+			got["key5"] = 500
+			if _, ok := tc.pm.Get("key5"); ok {
+				t.Errorf("ToMap() result modification affected the source")
+			}
+		})
+	}
+} // Test_TPartitionMap_ToMap()
+
+func Test_TPartitionMap_Update(t *testing.T) {
+	type tAccount struct {
+		Owner   string
+		Balance int
+	}
+	errFunds := errors.New("insufficient funds")
+	withdraw := func(aAmount int) func(tAccount) (tAccount, error) {
+		return func(aOld tAccount) (tAccount, error) {
+			if aOld.Balance < aAmount {
+				return tAccount{}, errFunds
+			}
+			aOld.Balance -= aAmount
+			return aOld, nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		amount  int
+		wantErr error
+		want    tAccount
+	}{
+		{
+			name:    "Success",
+			key:     "alice",
+			amount:  30,
+			wantErr: nil,
+			want:    tAccount{"Alice", 70},
+		},
+		{
+			name:    "Error aborts",
+			key:     "alice",
+			amount:  1000,
+			wantErr: errFunds,
+			want:    tAccount{"Alice", 100},
+		},
+		{
+			name:    "Missing key",
+			key:     "bob",
+			amount:  10,
+			wantErr: ErrKeyNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pm := New[string, tAccount]().Put("alice", tAccount{"Alice", 100})
+			puts := 0
+			pm.OnPut(func(string, tAccount) { puts++ })
+
+			if err := pm.Update(tc.key, withdraw(tc.amount)); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Update() error = %v, want %v", err, tc.wantErr)
+			}
+			got, ok := pm.Get(tc.key)
+			if ok != (ErrKeyNotFound != tc.wantErr) {
+				t.Errorf("Get() found = %v after Update()", ok)
+			}
+			if got != tc.want {
+				t.Errorf("Get() = %v, want %v", got, tc.want)
+			}
+			wantPuts := 0
+			if nil == tc.wantErr {
+				wantPuts = 1
+			}
+			if wantPuts != puts {
+				t.Errorf("OnPut() called %d times, want %d", puts, wantPuts)
+			}
+		})
+	}
+
+	var npm *TPartitionMap[string, tAccount]
+	if err := npm.Update("alice", withdraw(1)); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Update() on nil map error = %v, want %v", err, ErrKeyNotFound)
+	}
+} // Test_TPartitionMap_Update()
+
+func Test_TPartitionMap_Upsert(t *testing.T) {
+	sum := func(aExisting, aIncoming int) int {
+		return aExisting + aIncoming
+	}
+	batch := func(aFrom, aTo, aValue int) map[string]int {
+		result := make(map[string]int, aTo-aFrom)
+		for i := aFrom; i < aTo; i++ {
+			result[fmt.Sprint("key", i)] = aValue
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		first  map[string]int
+		second map[string]int
+		merge  func(int, int) int
+		want   map[string]int
+	}{
+		{
+			name:   "Disjoint batches",
+			first:  batch(0, 100, 1),
+			second: batch(100, 200, 2),
+			merge:  sum,
+			want:   mergedMaps(batch(0, 100, 1), batch(100, 200, 2)),
+		},
+		{
+			name:   "Overlapping batches summed",
+			first:  batch(0, 100, 1),
+			second: batch(0, 100, 2),
+			merge:  sum,
+			want:   batch(0, 100, 3),
+		},
+		{
+			name:   "Partly overlapping batches summed",
+			first:  batch(0, 100, 1),
+			second: batch(50, 150, 2),
+			merge:  sum,
+			want:   mergedMaps(batch(0, 50, 1), batch(50, 100, 3), batch(100, 150, 2)),
+		},
+		{
+			name:   "Nil merge overwrites",
+			first:  batch(0, 100, 1),
+			second: batch(50, 150, 2),
+			merge:  nil,
+			want:   mergedMaps(batch(0, 50, 1), batch(50, 150, 2)),
+		},
+		{
+			name:   "Empty source",
+			first:  batch(0, 10, 1),
+			second: nil,
+			merge:  sum,
+			want:   batch(0, 10, 1),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pm := New[string, int]()
+			pm.Upsert(tc.first, tc.merge)
+
+			puts := 0
+			pm.OnPut(func(string, int) { puts++ })
+			if got := pm.Upsert(tc.second, tc.merge); pm != got {
+				t.Error("Upsert() didn't return the map itself")
+			}
+
+			if len(tc.want) != pm.Len() {
+				t.Errorf("Len() = %d, want %d", pm.Len(), len(tc.want))
+			}
+			for k, want := range tc.want {
+				if got, _ := pm.Get(k); want != got {
+					t.Errorf("Get(%q) = %d, want %d", k, got, want)
 				}
 			}
+			if len(tc.second) != puts {
+				t.Errorf("OnPut() called %d times, want %d", puts, len(tc.second))
+			}
+			recount(t, pm)
 		})
 	}
-} // Test_TPartitionMap_String()
+
+	var npm *TPartitionMap[string, int]
+	if nil != npm.Upsert(batch(0, 10, 1), sum) {
+		t.Error("Upsert() on nil map should return nil")
+	}
+} // Test_TPartitionMap_Upsert()
+
+// `mergedMaps()` returns a new map holding the pairs of all given maps.
+func mergedMaps(aMaps ...map[string]int) map[string]int {
+	result := make(map[string]int)
+	for _, m := range aMaps {
+		for k, v := range m {
+			result[k] = v
+		}
+	}
+
+	return result
+} // mergedMaps()
 
 func Test_TPartitionMap_Values(t *testing.T) {
 	tests := []struct {
@@ -973,6 +5346,125 @@ func Test_TPartitionMap_Values(t *testing.T) {
 	}
 } // Test_TPartitionMap_Values()
 
+func Test_TPartitionMap_ValuesSortedFunc(t *testing.T) {
+	type tUser struct {
+		name string
+		age  int
+	}
+	byAge := func(a, b tUser) bool {
+		return a.age < b.age
+	}
+	users := New[string, tUser]().
+		Put("u1", tUser{"carol", 41}).
+		Put("u2", tUser{"alice", 23}).
+		Put("u3", tUser{"bob", 35})
+
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, tUser]
+		less func(a, b tUser) bool
+		want []tUser
+	}{
+		{
+			name: "By age",
+			pm:   users,
+			less: byAge,
+			want: []tUser{{"alice", 23}, {"bob", 35}, {"carol", 41}},
+		},
+		{
+			name: "By name descending",
+			pm:   users,
+			less: func(a, b tUser) bool {
+				return a.name > b.name
+			},
+			want: []tUser{{"carol", 41}, {"bob", 35}, {"alice", 23}},
+		},
+		{
+			name: "Empty map",
+			pm:   New[string, tUser](),
+			less: byAge,
+			want: []tUser{},
+		},
+		{
+			name: "Nil map",
+			pm:   nil,
+			less: byAge,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.ValuesSortedFunc(tc.less)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ValuesSortedFunc() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if got := users.ValuesSortedFunc(nil); 3 != len(got) {
+		t.Errorf("ValuesSortedFunc(nil) returned %d values, want 3", len(got))
+	}
+} // Test_TPartitionMap_ValuesSortedFunc()
+
+func Test_TPartitionMap_ValuesUnsorted(t *testing.T) {
+	tests := []struct {
+		name string
+		pm   *TPartitionMap[string, int]
+		want []int
+	}{
+		{
+			name: "Empty partition map",
+			pm:   New[string, int](),
+			want: []int{},
+		},
+		{
+			name: "Partition map with values",
+			pm: New[string, int]().
+				Put("key3", 300).
+				Put("key1", 100).
+				Put("key2", 200),
+			want: []int{100, 200, 300},
+		},
+		{
+			name: "Nil partition map",
+			pm:   nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.pm.ValuesUnsorted()
+			if nil != got {
+				slices.Sort(got)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ValuesUnsorted() = %v, want %v",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TPartitionMap_ValuesUnsorted()
+
+func Benchmark_TPartitionMap_ValuesUnsorted(b *testing.B) {
+	pm := New[string, int]()
+	for i := range 1 << 18 {
+		pm.Put(fmt.Sprintf("key-%d", i), i)
+	}
+
+	b.Run("Values", func(b *testing.B) {
+		for range b.N {
+			_ = pm.Values()
+		}
+	})
+	b.Run("ValuesUnsorted", func(b *testing.B) {
+		for range b.N {
+			_ = pm.ValuesUnsorted()
+		}
+	})
+} // Benchmark_TPartitionMap_ValuesUnsorted()
+
 func Test_TPartitionMap_PartitionStats(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -1047,6 +5539,21 @@ func Test_TPartitionMap_PartitionStats(t *testing.T) {
 					len(metrics.PartKeys), metrics.Parts)
 			}
 
+			// No key was ever deleted, so no partition in use is empty
+			if 0 != metrics.EmptyParts {
+				t.Errorf("PartitionStats() metrics.EmptyParts = %v, want 0",
+					metrics.EmptyParts)
+			}
+			if metrics.MinKeys > metrics.MaxKeys {
+				t.Errorf("PartitionStats() metrics.MinKeys = %v > metrics.MaxKeys = %v",
+					metrics.MinKeys, metrics.MaxKeys)
+			}
+			if (0 < metrics.Parts) &&
+				(float64(metrics.Keys)/float64(metrics.Parts) != metrics.AvgF) {
+				t.Errorf("PartitionStats() metrics.AvgF = %v, want %v",
+					metrics.AvgF, float64(metrics.Keys)/float64(metrics.Parts))
+			}
+
 			// Verify that the reported partition counts match actual counts
 			if tc.pm != nil {
 				for idx, count := range metrics.PartKeys {
@@ -1055,7 +5562,7 @@ func Test_TPartitionMap_PartitionStats(t *testing.T) {
 					}
 
 					// Verify the partition exists and has the reported number of keys
-					partition := tc.pm.tPartitionList[idx]
+					partition := tc.pm.slots()[idx].Load()
 					if partition == nil {
 						t.Errorf("PartitionStats() reported non-nil partition at index %d, but it's nil", idx)
 					} else if partition.len() != count {
@@ -1068,4 +5575,47 @@ func Test_TPartitionMap_PartitionStats(t *testing.T) {
 	}
 } // Test_TPartitionMap_PartitionStats()
 
+func Test_TPartitionMap_PartitionStats_Distribution(t *testing.T) {
+	// A modulo hasher distributes the integer keys so that with
+	// four partitions the keys 0 to 9 yield the sizes 3, 3, 2, 2.
+	// Removing `2` and `6` leaves the third partition empty.
+	modulo := func(aKey int) uint64 {
+		return uint64(aKey) //#nosec G115
+	}
+	pm := NewWithPartitions[int, int](4).SetHasher(modulo)
+	for i := range 10 {
+		pm.Put(i, i)
+	}
+	pm.Delete(2).Delete(6)
+
+	got := pm.PartitionStats()
+	want := &TMetrics{
+		Parts:      4,
+		Keys:       8,
+		Avg:        2,
+		AvgF:       2.0,
+		MinKeys:    0,
+		MaxKeys:    3,
+		EmptyParts: 1,
+		StdDev:     math.Sqrt(1.5), // deviations 1, 1, -2, 0
+		PartKeys:   map[int]int{0: 3, 1: 3, 2: 0, 3: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PartitionStats() = %+v, want %+v", got, want)
+	}
+
+	// A skewed distribution: all keys in a single partition.
+	pm = NewWithPartitions[int, int](4).SetHasher(modulo)
+	for i := range 7 {
+		pm.Put(i*4, i)
+	}
+	pm.Put(1, 1).Delete(1)
+
+	got = pm.PartitionStats()
+	if (2 != got.Parts) || (0 != got.MinKeys) || (7 != got.MaxKeys) ||
+		(1 != got.EmptyParts) || (3.5 != got.AvgF) || (3.5 != got.StdDev) {
+		t.Errorf("PartitionStats() = %+v", got)
+	}
+} // Test_TPartitionMap_PartitionStats_Distribution()
+
 /* _EoF_ */
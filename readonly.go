@@ -0,0 +1,138 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides a read-only view of a partitioned map which can
+// be handed to code that must not modify the map.
+
+type (
+	// `TReadOnlyMap` is the read-only view of a partitioned map as
+	// returned by `TPartitionMap.ReadOnly()`.
+	TReadOnlyMap[K cmp.Ordered, V any] interface {
+		ForEach(aFunc func(aKey K, aValue V)) TReadOnlyMap[K, V]
+		Get(aKey K) (V, bool)
+		Has(aKey K) bool
+		Keys() []K
+		Len() int
+		String() string
+		Values() []V
+	}
+
+	// `tReadOnly` implements `TReadOnlyMap` by forwarding to the
+	// underlying partitioned map.
+	tReadOnly[K cmp.Ordered, V any] struct {
+		pm *TPartitionMap[K, V]
+	}
+)
+
+// `ForEach()` executes the provided function for each key/value pair
+// in the underlying map (see `TPartitionMap.ForEach()`).
+//
+// Parameters:
+//   - `aFunc`: The function to execute for each key/value pair.
+//
+// Returns:
+//   - `TReadOnlyMap[K, V]`: The view itself, allowing method chaining.
+func (ro tReadOnly[K, V]) ForEach(aFunc func(aKey K, aValue V)) TReadOnlyMap[K, V] {
+	ro.pm.ForEach(aFunc)
+
+	return ro
+} // ForEach()
+
+// `Get()` retrieves a value from the underlying map (see
+// `TPartitionMap.Get()`).
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to be retrieved.
+//
+// Returns:
+//   - `V`: The value associated with the key (if found).
+//   - `bool`: Indicating whether the key was found.
+func (ro tReadOnly[K, V]) Get(aKey K) (V, bool) {
+	return ro.pm.Get(aKey)
+} // Get()
+
+// `Has()` reports whether the underlying map contains the given key.
+//
+// Parameters:
+//   - `aKey`: The key to look for.
+//
+// Returns:
+//   - `bool`: `true` if the key is present.
+func (ro tReadOnly[K, V]) Has(aKey K) bool {
+	return ro.pm.Has(aKey)
+} // Has()
+
+// `Keys()` returns the sorted keys of the underlying map.
+//
+// Returns:
+//   - `[]K`: A slice of all keys.
+func (ro tReadOnly[K, V]) Keys() []K {
+	return ro.pm.Keys()
+} // Keys()
+
+// `Len()` returns the number of key/value pairs in the underlying map.
+//
+// Returns:
+//   - `int`: The number of key/value pairs.
+func (ro tReadOnly[K, V]) Len() int {
+	return ro.pm.Len()
+} // Len()
+
+// `String()` returns a string representation of the underlying map.
+//
+// Returns:
+//   - `string`: The map's string representation.
+func (ro tReadOnly[K, V]) String() string {
+	return ro.pm.String()
+} // String()
+
+// `Values()` returns the values of the underlying map.
+//
+// Returns:
+//   - `[]V`: A slice of all values.
+func (ro tReadOnly[K, V]) Values() []V {
+	return ro.pm.Values()
+} // Values()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `ReadOnly()` returns a read-only view of the partitioned map.
+//
+// The view offers only methods which don't modify the map, so code
+// receiving it can't (accidentally) change the map's contents; the
+// compiler rejects any attempt to do so. Since the view shares the
+// map's partitions instead of copying them, it's cheap to create and
+// always reflects the map's current state.
+//
+// NOTE: The view doesn't freeze the map: the map itself can still be
+// modified by anybody holding a reference to it. Use `Snapshot()` or
+// `Clone()` (and hand out a view of the copy) if the readers need
+// data that doesn't change.
+//
+// Example usage:
+//
+//	func startReporter(aStats partitionmap.TReadOnlyMap[string, int]) {
+//		// aStats.Put("x", 1) wouldn't compile
+//	}
+//
+//	startReporter(pm.ReadOnly())
+//
+// Returns:
+//   - `TReadOnlyMap[K, V]`: A read-only view of the partitioned map.
+func (pm *TPartitionMap[K, V]) ReadOnly() TReadOnlyMap[K, V] {
+	return tReadOnly[K, V]{pm: pm}
+} // ReadOnly()
+
+/* _EoF_ */
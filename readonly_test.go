@@ -0,0 +1,78 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TPartitionMap_ReadOnly(t *testing.T) {
+	pm := New[string, int]().Put("one", 1).Put("two", 2)
+	ro := pm.ReadOnly()
+
+	// Neither the interface nor the value behind it offers any
+	// modifying method.
+	for _, typ := range []reflect.Type{
+		reflect.TypeFor[TReadOnlyMap[string, int]](),
+		reflect.TypeOf(ro),
+	} {
+		for _, name := range []string{"Put", "Delete", "Clear", "Compute", "Pop"} {
+			if _, ok := typ.MethodByName(name); ok {
+				t.Errorf("%v has method %s", typ, name)
+			}
+		}
+	}
+	if _, ok := ro.(interface{ Clear() *TPartitionMap[string, int] }); ok {
+		t.Error("ReadOnly() view can be asserted to a modifiable map")
+	}
+
+	if v, ok := ro.Get("one"); !ok || 1 != v {
+		t.Errorf("Get(one) = (%d, %v), want (1, true)", v, ok)
+	}
+	if !ro.Has("two") || ro.Has("three") {
+		t.Error("Has() doesn't match the map's contents")
+	}
+
+	// Modifications of the map are visible through the view.
+	pm.Put("three", 3).Delete("one")
+	if got := ro.Len(); 2 != got {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+	if got, want := ro.Keys(), []string{"three", "two"}; !slices.Equal(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	values := ro.Values()
+	slices.Sort(values)
+	if want := []int{2, 3}; !slices.Equal(values, want) {
+		t.Errorf("Values() = %v, want %v", values, want)
+	}
+	if got, want := ro.String(), pm.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	sum := 0
+	if got := ro.ForEach(func(aKey string, aValue int) {
+		sum += aValue
+	}); got != ro {
+		t.Error("ForEach() returned different instance")
+	}
+	if 5 != sum {
+		t.Errorf("ForEach() sum = %d, want 5", sum)
+	}
+
+	var npm *TPartitionMap[string, int]
+	nro := npm.ReadOnly()
+	if 0 != nro.Len() || nro.Has("one") || nil != nro.Keys() {
+		t.Error("ReadOnly() view of nil map isn't empty")
+	}
+} // Test_TPartitionMap_ReadOnly()
+
+/* _EoF_ */
@@ -0,0 +1,123 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides an adapter offering the method set of the
+// standard library's `sync.Map`, so code written against the latter
+// can use a partitioned map with minimal changes.
+
+type (
+	// `TSyncMapAdapter` wraps a partitioned map, offering the methods
+	// of `sync.Map` as returned by `TPartitionMap.AsSyncMap()`.
+	//
+	// Other than `sync.Map` the methods use the map's key and value
+	// types instead of `any`.
+	TSyncMapAdapter[K cmp.Ordered, V any] struct {
+		pm *TPartitionMap[K, V]
+	}
+)
+
+// `Delete()` deletes the value for the given key.
+//
+// Parameters:
+//   - `aKey`: The key to delete.
+func (sm *TSyncMapAdapter[K, V]) Delete(aKey K) {
+	sm.pm.Delete(aKey)
+} // Delete()
+
+// `Load()` returns the value stored in the map for the given key.
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//
+// Returns:
+//   - `V`: The value stored (or the zero value if not present).
+//   - `bool`: Indicating whether a value was found.
+func (sm *TSyncMapAdapter[K, V]) Load(aKey K) (rValue V, rOk bool) {
+	return sm.pm.Get(aKey)
+} // Load()
+
+// `LoadAndDelete()` deletes the value for the given key, returning
+// the previous value if any.
+//
+// Parameters:
+//   - `aKey`: The key to delete.
+//
+// Returns:
+//   - `V`: The deleted value (or the zero value if not present).
+//   - `bool`: Indicating whether the key was present.
+func (sm *TSyncMapAdapter[K, V]) LoadAndDelete(aKey K) (rValue V, rLoaded bool) {
+	return sm.pm.GetAndDelete(aKey)
+} // LoadAndDelete()
+
+// `LoadOrStore()` returns the existing value for the given key if
+// present. Otherwise, it stores and returns the given value.
+//
+// Parameters:
+//   - `aKey`: The key to look up.
+//   - `aValue`: The value to store if the key is not present.
+//
+// Returns:
+//   - `V`: The existing value if loaded, otherwise `aValue`.
+//   - `bool`: `true` if the value was loaded, `false` if stored.
+func (sm *TSyncMapAdapter[K, V]) LoadOrStore(aKey K, aValue V) (rActual V, rLoaded bool) {
+	return sm.pm.LoadOrStore(aKey, aValue)
+} // LoadOrStore()
+
+// `Range()` calls the given function for each key and value present
+// in the map. If the function returns `false`, the iteration stops.
+//
+// Like `sync.Map.Range()` this doesn't correspond to a consistent
+// snapshot of the whole map: each partition is snapshotted on its
+// own (see `TPartitionMap.ForEachWhile()`). Since no lock is held
+// while the function is called, it may modify the map.
+//
+// Parameters:
+//   - `aFunc`: The function to call for each key/value pair.
+func (sm *TSyncMapAdapter[K, V]) Range(aFunc func(aKey K, aValue V) bool) {
+	sm.pm.ForEachWhile(aFunc)
+} // Range()
+
+// `Store()` sets the value for the given key.
+//
+// Parameters:
+//   - `aKey`: The key to set.
+//   - `aValue`: The value to store.
+func (sm *TSyncMapAdapter[K, V]) Store(aKey K, aValue V) {
+	sm.pm.Put(aKey, aValue)
+} // Store()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `AsSyncMap()` returns an adapter offering the method set of
+// `sync.Map` for the partitioned map.
+//
+// This allows for using the partitioned map in code written against
+// `sync.Map` (e.g. to compare both implementations with a given
+// workload) by changing little more than the map's declaration.
+// The adapter shares the partitioned map instead of copying it.
+//
+// Example usage:
+//
+//	// var cache sync.Map
+//	cache := partitionmap.New[string, *TItem]().AsSyncMap()
+//	cache.Store("key", item)
+//
+// Returns:
+//   - `*TSyncMapAdapter[K, V]`: An adapter for the partitioned map.
+func (pm *TPartitionMap[K, V]) AsSyncMap() *TSyncMapAdapter[K, V] {
+	return &TSyncMapAdapter[K, V]{pm: pm}
+} // AsSyncMap()
+
+/* _EoF_ */
@@ -0,0 +1,143 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TSyncMapAdapter(t *testing.T) {
+	var std sync.Map
+	pm := New[string, int]()
+	sm := pm.AsSyncMap()
+
+	// Each step is applied to both maps, expecting the same results.
+	if v, ok := sm.Load("a"); ok || 0 != v {
+		t.Errorf("Load(a) = (%d, %v), want (0, false)", v, ok)
+	}
+
+	std.Store("a", 1)
+	sm.Store("a", 1)
+	if v, ok := sm.Load("a"); !ok || 1 != v {
+		t.Errorf("Load(a) = (%d, %v), want (1, true)", v, ok)
+	}
+
+	stdV, stdLoaded := std.LoadOrStore("a", 11)
+	v, loaded := sm.LoadOrStore("a", 11)
+	if stdLoaded != loaded || stdV.(int) != v {
+		t.Errorf("LoadOrStore(a) = (%d, %v), want (%v, %v)", v, loaded, stdV, stdLoaded)
+	}
+	stdV, stdLoaded = std.LoadOrStore("b", 2)
+	v, loaded = sm.LoadOrStore("b", 2)
+	if stdLoaded != loaded || stdV.(int) != v {
+		t.Errorf("LoadOrStore(b) = (%d, %v), want (%v, %v)", v, loaded, stdV, stdLoaded)
+	}
+
+	stdV, stdLoaded = std.LoadAndDelete("a")
+	v, loaded = sm.LoadAndDelete("a")
+	if stdLoaded != loaded || stdV.(int) != v {
+		t.Errorf("LoadAndDelete(a) = (%d, %v), want (%v, %v)", v, loaded, stdV, stdLoaded)
+	}
+	_, stdLoaded = std.LoadAndDelete("a")
+	if v, loaded = sm.LoadAndDelete("a"); stdLoaded != loaded || 0 != v {
+		t.Errorf("LoadAndDelete(a) again = (%d, %v), want (0, %v)", v, loaded, stdLoaded)
+	}
+
+	std.Delete("b")
+	sm.Delete("b")
+	sm.Delete("missing") // must be a no-op
+	if 0 != pm.Len() {
+		t.Errorf("Len() after Delete() = %d, want 0", pm.Len())
+	}
+
+	// The adapter shares the partitioned map.
+	pm.Put("c", 3)
+	if v, ok := sm.Load("c"); !ok || 3 != v {
+		t.Errorf("Load(c) = (%d, %v), want (3, true)", v, ok)
+	}
+} // Test_TSyncMapAdapter()
+
+func Test_TSyncMapAdapter_Range(t *testing.T) {
+	sm := New[int, int]().AsSyncMap()
+	for i := range 100 {
+		sm.Store(i, i*i)
+	}
+
+	seen := make(map[int]int)
+	sm.Range(func(aKey, aValue int) bool {
+		seen[aKey] = aValue
+		return true
+	})
+	if 100 != len(seen) {
+		t.Errorf("Range() visited %d pairs, want 100", len(seen))
+	}
+	for k, v := range seen {
+		if k*k != v {
+			t.Errorf("Range() visited (%d, %d), want (%d, %d)", k, v, k, k*k)
+		}
+	}
+
+	// Returning `false` stops the iteration.
+	calls := 0
+	sm.Range(func(int, int) bool {
+		calls++
+		return 10 > calls
+	})
+	if 10 != calls {
+		t.Errorf("Range() called function %d times, want 10", calls)
+	}
+
+	// The function may modify the map.
+	sm.Range(func(aKey, _ int) bool {
+		sm.Delete(aKey)
+		return true
+	})
+	if _, ok := sm.Load(1); ok {
+		t.Error("Range() with Delete() left pairs behind")
+	}
+} // Test_TSyncMapAdapter_Range()
+
+func Benchmark_TSyncMapAdapter(b *testing.B) {
+	keys := make([]string, 1<<10)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+
+	b.Run("sync.Map", func(b *testing.B) {
+		var m sync.Map
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				key := keys[i%len(keys)]
+				if 0 == i%4 {
+					m.Store(key, i)
+				} else {
+					m.Load(key)
+				}
+			}
+		})
+	})
+
+	b.Run("TSyncMapAdapter", func(b *testing.B) {
+		m := New[string, int]().AsSyncMap()
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				key := keys[i%len(keys)]
+				if 0 == i%4 {
+					m.Store(key, i)
+				} else {
+					m.Load(key)
+				}
+			}
+		})
+	})
+} // Benchmark_TSyncMapAdapter()
+
+/* _EoF_ */
@@ -0,0 +1,354 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides the time-to-live (TTL) handling of a partitioned
+// map. Each entry stored with a TTL gets an expiry time which is kept
+// in its partition next to the key/value store and protected by the
+// same lock.
+//
+// `Get()` and `GetMany()` treat expired entries as absent (`Get()`
+// removes them as well). All other methods (e.g. `Len()`, `Keys()`,
+// or `ForEach()`) see an expired entry until it's removed by
+// `DeleteExpired()`, either called directly or periodically by the
+// background sweeper (see `StartSweeper()`).
+//
+// The expiry times are not inherited by copies like those made by
+// `Clone()`, `Snapshot()`, or `Filter()`; the entries of a copy never
+// expire.
+
+type (
+	// `tExpiry` holds the TTL configuration shared by all partitions
+	// of a partitioned map.
+	tExpiry struct {
+		ttl time.Duration    // the default lifetime of an entry
+		now func() time.Time // the clock, replaceable for testing
+	}
+)
+
+// `nanos()` returns the current time in nanoseconds.
+//
+// A `nil` configuration (i.e. a map without default TTL) uses the
+// system clock.
+//
+// Returns:
+//   - `int64`: The current Unix time in nanoseconds.
+func (e *tExpiry) nanos() int64 {
+	if (nil == e) || (nil == e.now) {
+		return time.Now().UnixNano()
+	}
+
+	return e.now().UnixNano()
+} // nanos()
+
+// ---------------------------------------------------------------------------
+// `tPartition` methods:
+
+// `expire()` removes the given key from the partition if its entry
+// is expired.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to check.
+//
+// Returns:
+//   - `bool`: Indicating whether the key was removed.
+func (p *tPartition[K, V]) expire(aKey K) (rOk bool) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return p.successor(aKey).expire(aKey)
+	}
+	// Another goroutine might have updated the entry meanwhile.
+	if rOk = p.expired(aKey, p.expiry.nanos()); rOk {
+		delete(p.kv, aKey)
+		p.forget(aKey)
+	}
+	p.Unlock()
+
+	return
+} // expire()
+
+// `expired()` reports whether the entry of the given key has expired
+// at the given time.
+//
+// The caller must hold (at least) the partition's read lock.
+//
+// Parameters:
+//   - `aKey`: The key of the key/value pair to check.
+//   - `aNow`: The current time in nanoseconds.
+//
+// Returns:
+//   - `bool`: `true` if the entry has an expiry time not after `aNow`.
+func (p *tPartition[K, V]) expired(aKey K, aNow int64) bool {
+	deadline, ok := p.deadlines[aKey]
+
+	return ok && (deadline <= aNow)
+} // expired()
+
+// `putTTL()` stores a key/value pair with the given expiry time in
+// the partition.
+//
+// Parameters:
+//   - `aKey`: The key to be stored in the partition.
+//   - `aVal`: The value associated with the key.
+//   - `aDeadline`: The expiry time in nanoseconds (`0` for none).
+//
+// Returns:
+//   - `*tPartition[K, V]`: The partition itself, allowing method chaining.
+func (p *tPartition[K, V]) putTTL(aKey K, aVal V, aDeadline int64) *tPartition[K, V] {
+	if nil == p {
+		return nil
+	}
+
+	if !p.lock() {
+		return p.successor(aKey).putTTL(aKey, aVal, aDeadline)
+	}
+	p.kv[aKey] = aVal
+	p.touch(aKey)
+	if 0 == aDeadline {
+		delete(p.deadlines, aKey)
+	} else {
+		if nil == p.deadlines {
+			p.deadlines = make(map[K]int64)
+		}
+		p.deadlines[aKey] = aDeadline
+	}
+	p.Unlock()
+
+	return p
+} // putTTL()
+
+// `sweep()` removes all expired key/value pairs from the partition.
+//
+// Parameters:
+//   - `aNow`: The current time in nanoseconds.
+//
+// Returns:
+//   - `[]K`: The keys of the removed key/value pairs.
+func (p *tPartition[K, V]) sweep(aNow int64) (rKeys []K) {
+	if nil == p {
+		return
+	}
+
+	if !p.lock() {
+		return
+	}
+	for k, deadline := range p.deadlines {
+		if deadline <= aNow {
+			delete(p.kv, k)
+			p.forget(k)
+			rKeys = append(rKeys, k)
+		}
+	}
+	p.Unlock()
+
+	return
+} // sweep()
+
+// `renew()` sets the expiry time of the given key after its value
+// was stored.
+//
+// In a map with a default TTL the entry expires after that period,
+// otherwise a previous expiry time (see `PutWithTTL()`) is removed.
+// The caller must hold the partition's write lock.
+//
+// Parameters:
+//   - `aKey`: The key of the stored key/value pair.
+func (p *tPartition[K, V]) renew(aKey K) {
+	if (nil == p.expiry) || (0 >= p.expiry.ttl) {
+		delete(p.deadlines, aKey)
+		return
+	}
+
+	if nil == p.deadlines {
+		p.deadlines = make(map[K]int64)
+	}
+	p.deadlines[aKey] = p.expiry.nanos() + int64(p.expiry.ttl)
+} // renew()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` constructor:
+
+// `NewWithTTL()` creates and initialises a new partitioned map
+// instance whose entries expire after the given period.
+//
+// Every entry stored by e.g. `Put()`, `PutAll()`, or `Compute()`
+// expires the given period after it was last stored; use
+// `PutWithTTL()` for a different period per entry.
+// Expired entries are removed lazily by `Get()` and in bulk by
+// `DeleteExpired()` or the background sweeper (see `StartSweeper()`).
+// If `aTTL` isn't positive the map behaves like one created by `New()`.
+//
+// Example usage:
+//
+//	sessions := NewWithTTL[string, *TSession](30 * time.Minute)
+//	sessions.StartSweeper(time.Minute)
+//	defer sessions.StopSweeper()
+//
+// Parameters:
+//   - `aTTL`: The default lifetime of the map's entries.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: A pointer to a newly created partitioned map.
+func NewWithTTL[K cmp.Ordered, V any](aTTL time.Duration) *TPartitionMap[K, V] {
+	result := New[K, V]()
+	result.expiry = &tExpiry{
+		ttl: max(aTTL, 0),
+		now: time.Now,
+	}
+
+	return result
+} // NewWithTTL()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `sweep()` periodically removes the expired entries until the given
+// channel is closed.
+//
+// Parameters:
+//   - `aInterval`: The time between two sweeps.
+//   - `aStop`: The channel signalling the sweeper to stop.
+func (pm *TPartitionMap[K, V]) sweep(aInterval time.Duration, aStop <-chan struct{}) {
+	ticker := time.NewTicker(aInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-aStop:
+			return
+		case <-ticker.C:
+			pm.DeleteExpired()
+		}
+	}
+} // sweep()
+
+// `DeleteExpired()` removes all expired key/value pairs from the
+// partitioned map.
+//
+// The partitions are processed one after the other, each under its
+// write lock. The delete hook (see `OnDelete()`) is called for each
+// removed key.
+//
+// Returns:
+//   - `int`: The number of removed key/value pairs.
+func (pm *TPartitionMap[K, V]) DeleteExpired() (rCount int) {
+	if nil == pm {
+		return
+	}
+
+	now := pm.expiry.nanos()
+	pm.eachPartition(func(aPartition *tPartition[K, V]) bool {
+		removed := aPartition.sweep(now)
+		rCount += len(removed)
+		for _, key := range removed {
+			pm.notifyDelete(key)
+		}
+		return true
+	})
+
+	return
+} // DeleteExpired()
+
+// `PutWithTTL()` stores a key/value pair which expires after the
+// given period.
+//
+// This overrides the map's default TTL (if any) for the given entry.
+// A later `Put()` of the same key resets the entry's expiry to the
+// map's default. If `aTTL` isn't positive the entry never expires.
+//
+// Parameters:
+//   - `aKey`: The key to store the value with.
+//   - `aValue`: The value to store.
+//   - `aTTL`: The lifetime of the entry.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) PutWithTTL(aKey K, aValue V, aTTL time.Duration) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	var deadline int64
+	if 0 < aTTL {
+		deadline = pm.expiry.nanos() + int64(aTTL)
+	}
+
+	aKey = pm.normKey(aKey)
+	if p, ok := pm.partition(aKey, true); ok {
+		pm.evict(p.putTTL(aKey, aValue, deadline))
+		pm.notifyPut(aKey, aValue)
+	}
+
+	return pm
+} // PutWithTTL()
+
+// `StartSweeper()` starts a background goroutine removing the
+// expired entries (see `DeleteExpired()`) at the given interval.
+//
+// A sweeper already running is stopped and replaced by the new one.
+// If `aInterval` isn't positive no sweeper is started.
+// Call `StopSweeper()` once the map isn't needed anymore, otherwise
+// the goroutine keeps the map alive.
+//
+// Parameters:
+//   - `aInterval`: The time between two sweeps.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) StartSweeper(aInterval time.Duration) *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	pm.Lock()
+	defer pm.Unlock()
+
+	if nil != pm.sweeper {
+		close(pm.sweeper)
+		pm.sweeper = nil
+	}
+	if 0 < aInterval {
+		pm.sweeper = make(chan struct{})
+		go pm.sweep(aInterval, pm.sweeper)
+	}
+
+	return pm
+} // StartSweeper()
+
+// `StopSweeper()` stops the background goroutine started by
+// `StartSweeper()`.
+//
+// Calling it without a running sweeper is a no-op.
+//
+// Returns:
+//   - `*TPartitionMap[K, V]`: The partitioned map itself, allowing method chaining.
+func (pm *TPartitionMap[K, V]) StopSweeper() *TPartitionMap[K, V] {
+	if nil == pm {
+		return nil
+	}
+
+	pm.Lock()
+	if nil != pm.sweeper {
+		close(pm.sweeper)
+		pm.sweeper = nil
+	}
+	pm.Unlock()
+
+	return pm
+} // StopSweeper()
+
+/* _EoF_ */
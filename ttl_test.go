@@ -0,0 +1,166 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `tFakeClock` is a manually advanced clock for the TTL tests.
+type tFakeClock struct {
+	nanos atomic.Int64
+}
+
+func (c *tFakeClock) Advance(aDelta time.Duration) {
+	c.nanos.Add(int64(aDelta))
+} // Advance()
+
+func (c *tFakeClock) Now() time.Time {
+	return time.Unix(0, c.nanos.Load())
+} // Now()
+
+// `newFakeTTL()` returns a TTL map using a fake clock.
+func newFakeTTL(aTTL time.Duration) (*TPartitionMap[string, int], *tFakeClock) {
+	clock := &tFakeClock{}
+	clock.nanos.Store(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+	pm := NewWithTTL[string, int](aTTL)
+	pm.expiry.now = clock.Now
+
+	return pm, clock
+} // newFakeTTL()
+
+func Test_TPartitionMap_NewWithTTL(t *testing.T) {
+	pm, clock := newFakeTTL(time.Minute)
+	pm.Put("one", 1)
+
+	clock.Advance(59 * time.Second)
+	pm.Put("two", 2)
+	if v, ok := pm.Get("one"); !ok || 1 != v {
+		t.Errorf("Get(one) before expiry = (%d, %v), want (1, true)", v, ok)
+	}
+
+	clock.Advance(time.Second)
+	if v, ok := pm.Get("one"); ok {
+		t.Errorf("Get(one) after expiry = (%d, %v), want (0, false)", v, ok)
+	}
+	if got := pm.Len(); 1 != got {
+		t.Errorf("Len() after lazy removal = %d, want 1", got)
+	}
+	if got := pm.GetMany([]string{"one", "two"}); 1 != len(got) || 2 != got["two"] {
+		t.Errorf("GetMany() = %v, want map[two:2]", got)
+	}
+
+	// Updating an entry restarts its lifetime.
+	clock.Advance(30 * time.Second)
+	pm.Put("two", 22)
+	clock.Advance(45 * time.Second)
+	if v, ok := pm.Get("two"); !ok || 22 != v {
+		t.Errorf("Get(two) after update = (%d, %v), want (22, true)", v, ok)
+	}
+} // Test_TPartitionMap_NewWithTTL()
+
+func Test_TPartitionMap_PutWithTTL(t *testing.T) {
+	pm, clock := newFakeTTL(time.Minute)
+	pm.PutWithTTL("short", 1, time.Second).
+		PutWithTTL("forever", 2, 0).
+		Put("default", 3)
+
+	clock.Advance(time.Second)
+	if _, ok := pm.Get("short"); ok {
+		t.Error("Get(short) should report the entry as expired")
+	}
+
+	clock.Advance(time.Hour)
+	if v, ok := pm.Get("forever"); !ok || 2 != v {
+		t.Errorf("Get(forever) = (%d, %v), want (2, true)", v, ok)
+	}
+	if _, ok := pm.Get("default"); ok {
+		t.Error("Get(default) should report the entry as expired")
+	}
+
+	// A plain `Put()` in a map without default TTL removes the expiry.
+	plain := New[string, int]().PutWithTTL("key", 1, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := plain.Get("key"); ok {
+		t.Error("Get(key) should report the entry as expired")
+	}
+	plain.PutWithTTL("key", 2, time.Nanosecond).Put("key", 3)
+	time.Sleep(time.Millisecond)
+	if v, ok := plain.Get("key"); !ok || 3 != v {
+		t.Errorf("Get(key) after Put() = (%d, %v), want (3, true)", v, ok)
+	}
+} // Test_TPartitionMap_PutWithTTL()
+
+func Test_TPartitionMap_DeleteExpired(t *testing.T) {
+	pm, clock := newFakeTTL(time.Minute)
+	for _, key := range []string{"a", "b", "c"} {
+		pm.Put(key, 1)
+	}
+	clock.Advance(30 * time.Second)
+	pm.Put("d", 1).Put("e", 1)
+
+	var deleted []string
+	pm.OnDelete(func(aKey string) {
+		deleted = append(deleted, aKey)
+	})
+
+	clock.Advance(30 * time.Second)
+	if got := pm.DeleteExpired(); 3 != got {
+		t.Errorf("DeleteExpired() = %d, want 3", got)
+	}
+	slices.Sort(deleted)
+	if want := []string{"a", "b", "c"}; !slices.Equal(deleted, want) {
+		t.Errorf("OnDelete() events = %v, want %v", deleted, want)
+	}
+	if want := []string{"d", "e"}; !slices.Equal(pm.Keys(), want) {
+		t.Errorf("Keys() = %v, want %v", pm.Keys(), want)
+	}
+
+	// Deleted entries must not leave an expiry time behind.
+	pm.Delete("d")
+	clock.Advance(time.Hour)
+	if got := pm.DeleteExpired(); 1 != got {
+		t.Errorf("DeleteExpired() = %d, want 1", got)
+	}
+
+	var npm *TPartitionMap[string, int]
+	if got := npm.DeleteExpired(); 0 != got {
+		t.Errorf("DeleteExpired() on nil map = %d, want 0", got)
+	}
+} // Test_TPartitionMap_DeleteExpired()
+
+func Test_TPartitionMap_StartSweeper(t *testing.T) {
+	pm, clock := newFakeTTL(time.Minute)
+	pm.PutAll(map[string]int{"a": 1, "b": 2, "c": 3})
+	pm.StartSweeper(time.Millisecond)
+	defer pm.StopSweeper()
+
+	clock.Advance(time.Minute)
+	deadline := time.Now().Add(5 * time.Second)
+	for (0 < pm.Len()) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := pm.Len(); 0 != got {
+		t.Errorf("Len() after sweeping = %d, want 0", got)
+	}
+
+	pm.StopSweeper()
+	pm.Put("x", 1)
+	clock.Advance(time.Minute)
+	time.Sleep(10 * time.Millisecond)
+	if got := pm.Len(); 1 != got {
+		t.Errorf("Len() after StopSweeper() = %d, want 1", got)
+	}
+	pm.StopSweeper() // must be a no-op
+} // Test_TPartitionMap_StartSweeper()
+
+/* _EoF_ */
@@ -0,0 +1,232 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// This file provides the watchers of a partitioned map, i.e. channels
+// receiving an event for each modification of the map.
+//
+// The events are sent at the same points where the change hooks
+// (see `OnPut()` and `OnDelete()`) are called, i.e. after the
+// modification took place and outside of any partition lock.
+// Methods resetting the whole map (`Clear()`, `Drain()`, `Reset()`,
+// and `SetAll()`) send a single `EventClear` instead of an event
+// for each removed pair; `SetAll()` then sends an `EventPut` for
+// each stored pair.
+//
+// Each watcher owns a buffered channel. Sending never blocks the
+// goroutine modifying the map: if a watcher's buffer is full, its
+// oldest pending event is dropped to make room for the new one.
+// A watcher that doesn't keep up with the modifications thus loses
+// events but always receives the most recent ones.
+
+const (
+	// The number of events buffered for each watcher.
+	watchBufferSize = 256
+)
+
+type (
+	// `TEventKind` tells which kind of modification a `TEvent`
+	// reports.
+	TEventKind int
+
+	// `TEvent` reports a modification of a partitioned map as
+	// received from the channel returned by `TPartitionMap.Watch()`.
+	//
+	// `Kind` is the kind of modification.
+	// `Key` is the key stored or removed (unused for `EventClear`).
+	// `Value` is the value stored (only used for `EventPut`).
+	TEvent[K cmp.Ordered, V any] struct {
+		Kind  TEventKind
+		Key   K
+		Value V
+	}
+
+	// `tWatcher` is a single subscriber of a map's events.
+	tWatcher[K cmp.Ordered, V any] struct {
+		sync.Mutex                   // serialise sending and closing
+		events     chan TEvent[K, V] // the subscriber's channel
+		closed     bool              // whether the channel is closed
+	}
+)
+
+const (
+	// `EventPut` reports a key/value pair stored in the map.
+	EventPut TEventKind = iota
+
+	// `EventDelete` reports a key/value pair removed from the map.
+	EventDelete
+
+	// `EventClear` reports all key/value pairs removed from the map.
+	EventClear
+)
+
+// `String()` returns the name of the event kind.
+//
+// Returns:
+//   - `string`: The event kind's name.
+func (ek TEventKind) String() string {
+	switch ek {
+	case EventPut:
+		return "Put"
+	case EventDelete:
+		return "Delete"
+	case EventClear:
+		return "Clear"
+	default:
+		return "Unknown"
+	}
+} // String()
+
+// ---------------------------------------------------------------------------
+// `tWatcher` methods:
+
+// `close()` closes the watcher's channel.
+//
+// Calling it more than once is harmless.
+func (w *tWatcher[K, V]) close() {
+	w.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.events)
+	}
+	w.Unlock()
+} // close()
+
+// `send()` delivers the given event to the watcher.
+//
+// If the watcher's buffer is full, its oldest pending event is
+// dropped to make room for the new one.
+//
+// Parameters:
+//   - `aEvent`: The event to deliver.
+func (w *tWatcher[K, V]) send(aEvent TEvent[K, V]) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return
+	}
+	for {
+		select {
+		case w.events <- aEvent:
+			return
+		default:
+		}
+		// The buffer is full: drop the oldest event (unless the
+		// receiver took it meanwhile) and try again.
+		select {
+		case <-w.events:
+		default:
+		}
+	}
+} // send()
+
+// ---------------------------------------------------------------------------
+// `TPartitionMap` methods:
+
+// `broadcast()` delivers the given event to all watchers of the map.
+//
+// Parameters:
+//   - `aEvent`: The event to deliver.
+func (pm *TPartitionMap[K, V]) broadcast(aEvent TEvent[K, V]) {
+	if list := pm.watchers.Load(); nil != list {
+		for _, w := range *list {
+			w.send(aEvent)
+		}
+	}
+} // broadcast()
+
+// `watching()` reports whether the map has any watchers.
+//
+// Returns:
+//   - `bool`: `true` if at least one watcher is registered.
+func (pm *TPartitionMap[K, V]) watching() bool {
+	list := pm.watchers.Load()
+
+	return (nil != list) && (0 < len(*list))
+} // watching()
+
+// `Watch()` subscribes to the modifications of the partitioned map.
+//
+// The returned channel receives an event for each key/value pair
+// stored in or removed from the map, and for each reset of the whole
+// map (see the notes at the top of this file). Any number of watchers
+// may be registered; each of them receives all events. Events caused
+// by a single goroutine arrive in the order of its modifications,
+// while those of concurrent modifications are interleaved.
+//
+// The channel buffers up to 256 events. If the receiver falls
+// behind, the oldest pending events are dropped, so modifying the
+// map never blocks on a slow watcher.
+//
+// The returned function unsubscribes the watcher and closes its
+// channel; calling it more than once is harmless. Watchers belong to
+// the map instance, i.e. they are not inherited by copies like those
+// made by `Clone()`.
+//
+// Example usage:
+//
+//	events, cancel := cache.Watch()
+//	defer cancel()
+//	for ev := range events {
+//		log.Println(ev.Kind, ev.Key)
+//	}
+//
+// Returns:
+//   - `<-chan TEvent[K, V]`: The channel receiving the events.
+//   - `func()`: The function to unsubscribe the watcher.
+func (pm *TPartitionMap[K, V]) Watch() (<-chan TEvent[K, V], func()) {
+	w := &tWatcher[K, V]{
+		events: make(chan TEvent[K, V], watchBufferSize),
+	}
+	if nil == pm {
+		w.close()
+		return w.events, func() {}
+	}
+
+	// The list of watchers is replaced (copy-on-write) so that
+	// `broadcast()` can iterate it without any locking.
+	for {
+		old := pm.watchers.Load()
+		var list []*tWatcher[K, V]
+		if nil != old {
+			list = slices.Clone(*old)
+		}
+		list = append(list, w)
+		if pm.watchers.CompareAndSwap(old, &list) {
+			break
+		}
+	}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			for {
+				old := pm.watchers.Load()
+				list := slices.DeleteFunc(slices.Clone(*old), func(aW *tWatcher[K, V]) bool {
+					return aW == w
+				})
+				if pm.watchers.CompareAndSwap(old, &list) {
+					break
+				}
+			}
+			w.close()
+		})
+	}
+
+	return w.events, cancel
+} // Watch()
+
+/* _EoF_ */
@@ -0,0 +1,139 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package partitionmap
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `receive()` reads the given number of events from the channel.
+func receive(t *testing.T, aEvents <-chan TEvent[string, int], aCount int) []TEvent[string, int] {
+	t.Helper()
+
+	result := make([]TEvent[string, int], 0, aCount)
+	for range aCount {
+		select {
+		case ev, ok := <-aEvents:
+			if !ok {
+				t.Fatalf("channel closed after %d events", len(result))
+			}
+			result = append(result, ev)
+		default:
+			t.Fatalf("got %d events, want %d", len(result), aCount)
+		}
+	}
+
+	return result
+} // receive()
+
+func Test_TPartitionMap_Watch(t *testing.T) {
+	pm := New[string, int]()
+	events, cancel := pm.Watch()
+	other, cancelOther := pm.Watch()
+	defer cancelOther()
+
+	pm.Put("a", 1).Put("b", 2)
+	pm.Delete("a")
+	pm.Delete("nokey") // not reported
+	Increment(pm, "b", 10)
+	pm.Clear()
+	pm.PutAll(map[string]int{"c": 3})
+	pm.SetAll(map[string]int{"d": 4})
+
+	want := []TEvent[string, int]{
+		{Kind: EventPut, Key: "a", Value: 1},
+		{Kind: EventPut, Key: "b", Value: 2},
+		{Kind: EventDelete, Key: "a"},
+		{Kind: EventPut, Key: "b", Value: 12},
+		{Kind: EventClear},
+		{Kind: EventPut, Key: "c", Value: 3},
+		{Kind: EventClear},
+		{Kind: EventPut, Key: "d", Value: 4},
+	}
+	if got := receive(t, events, len(want)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch() events = %v, want %v", got, want)
+	}
+	if got := receive(t, other, len(want)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch() second watcher events = %v, want %v", got, want)
+	}
+
+	cancel()
+	cancel() // harmless
+	if _, ok := <-events; ok {
+		t.Error("Watch() channel still open after cancel")
+	}
+
+	// The remaining watcher keeps receiving events.
+	pm.Put("e", 5)
+	if got := receive(t, other, 1); EventPut != got[0].Kind || "e" != got[0].Key {
+		t.Errorf("Watch() event after cancel = %v", got[0])
+	}
+	if 1 != len(*pm.watchers.Load()) {
+		t.Errorf("Watch() %d watchers registered, want 1", len(*pm.watchers.Load()))
+	}
+
+	var npm *TPartitionMap[string, int]
+	nilEvents, nilCancel := npm.Watch()
+	nilCancel()
+	if _, ok := <-nilEvents; ok {
+		t.Error("Watch() on nil map returned an open channel")
+	}
+} // Test_TPartitionMap_Watch()
+
+func Test_TPartitionMap_Watch_Overflow(t *testing.T) {
+	pm := New[int, int]()
+	events, cancel := pm.Watch()
+	defer cancel()
+
+	const numEvents = watchBufferSize + 100
+	for i := range numEvents {
+		pm.Put(i, i) // must not block
+	}
+
+	// The oldest events were dropped.
+	got := make([]int, 0, watchBufferSize)
+	for len(events) > 0 {
+		got = append(got, (<-events).Key)
+	}
+	if watchBufferSize != len(got) {
+		t.Fatalf("Watch() buffered %d events, want %d", len(got), watchBufferSize)
+	}
+	for idx, key := range got {
+		if want := numEvents - watchBufferSize + idx; want != key {
+			t.Fatalf("Watch() event %d has key %d, want %d", idx, key, want)
+		}
+	}
+} // Test_TPartitionMap_Watch_Overflow()
+
+func Test_TPartitionMap_Watch_Concurrent(t *testing.T) {
+	pm := New[int, int]()
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				events, cancel := pm.Watch()
+				pm.Put(g*1000+i, i)
+				<-events
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if pm.watching() {
+		t.Errorf("Watch() %d watchers left", len(*pm.watchers.Load()))
+	}
+} // Test_TPartitionMap_Watch_Concurrent()
+
+/* _EoF_ */